@@ -0,0 +1,243 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// _manifest is a name of an archive member listing files to load.
+const _manifest = "_manifest.yaml"
+
+// Archive adds YAML files from tar or zip archives to read configuration
+// from, so a deployment can ship configuration as a single bundle. Archives
+// are recognized by the ".zip", ".tar" and ".tgz" extensions, compressed tar
+// archives like "bundle.tar.gz" are decompressed like other files.
+//
+// If an archive contains a _manifest.yaml file at the top level, it lists
+// members to load in order, e.g.
+//
+// 	- base.yaml
+// 	- production/service.yaml
+//
+// Otherwise all the ".yaml" and ".yml" members are loaded in lexical order
+// of their paths.
+func Archive(archives ...string) YAMLOption {
+	return func(o *yamlOptions) {
+		for _, archive := range archives {
+			o.sources = append(o.sources, yamlSource{archive: archive})
+		}
+	}
+}
+
+// readArchive reads members of an archive to load in order. Only the
+// manifest and the members to load are read, each of them up to the limit
+// of bytes, unless it is zero.
+func readArchive(name string, limit int64) ([]io.Reader, error) {
+	isManifest := func(member string) bool { return member == _manifest }
+	members, manifest, err := archiveMembers(name, limit, isManifest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read archive %q", name)
+	}
+
+	order, err := archiveOrder(members, manifest[_manifest])
+	if err != nil {
+		return nil, errors.Wrapf(err, "in archive %q", name)
+	}
+
+	selected := make(map[string]bool, len(order))
+	for _, member := range order {
+		selected[member] = true
+	}
+
+	_, contents, err := archiveMembers(name, limit, func(member string) bool { return selected[member] })
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read archive %q", name)
+	}
+
+	readers := make([]io.Reader, len(order))
+	for i, member := range order {
+		readers[i] = namedReader{
+			Reader: newByteSource(contents[member]),
+			name:   name + ":" + member,
+		}
+	}
+
+	return readers, nil
+}
+
+// archiveMembers returns cleaned paths of regular files in an archive and
+// contents of the files selected by the function.
+func archiveMembers(name string, limit int64, read func(string) bool) ([]string, map[string][]byte, error) {
+	if filepath.Ext(name) == ".zip" {
+		return zipMembers(name, limit, read)
+	}
+
+	base := name
+	if decompressorFor(name) != nil {
+		base = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	switch {
+	case filepath.Ext(base) == ".tar":
+		f, err := openFile(name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		defer f.Close()
+		return tarMembers(f, limit, read)
+	case filepath.Ext(name) == ".tgz":
+		f, err := openFile(name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return tarMembers(gz, limit, read)
+	}
+
+	return nil, nil, fmt.Errorf("unsupported archive format")
+}
+
+func zipMembers(name string, limit int64, read func(string) bool) ([]string, map[string][]byte, error) {
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer r.Close()
+
+	var members []string
+	contents := make(map[string][]byte)
+	for _, f := range r.File {
+		if !f.FileInfo().Mode().IsRegular() {
+			continue
+		}
+
+		member := path.Clean(f.Name)
+		members = append(members, member)
+		if !read(member) {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		b, err := readLimited(rc, limit)
+		if cerr := rc.Close(); err == nil {
+			err = cerr
+		}
+
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "in member %q", member)
+		}
+
+		contents[member] = b
+	}
+
+	return members, contents, nil
+}
+
+func tarMembers(r io.Reader, limit int64, read func(string) bool) ([]string, map[string][]byte, error) {
+	var members []string
+	contents := make(map[string][]byte)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return members, contents, nil
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+
+		member := path.Clean(hdr.Name)
+		members = append(members, member)
+		if !read(member) {
+			continue
+		}
+
+		b, err := readLimited(tr, limit)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "in member %q", member)
+		}
+
+		contents[member] = b
+	}
+}
+
+// archiveOrder returns names of members to load in order.
+func archiveOrder(members []string, manifest []byte) ([]string, error) {
+	if manifest != nil {
+		present := make(map[string]bool, len(members))
+		for _, member := range members {
+			present[member] = true
+		}
+
+		var order []string
+		if err := yaml.Unmarshal(manifest, &order); err != nil {
+			return nil, errors.Wrapf(err, "can't parse %s", _manifest)
+		}
+
+		for i, member := range order {
+			order[i] = path.Clean(member)
+			if !present[order[i]] {
+				return nil, fmt.Errorf("%q listed in %s is missing", member, _manifest)
+			}
+		}
+
+		return order, nil
+	}
+
+	var order []string
+	for _, member := range members {
+		if ext := path.Ext(member); ext == ".yaml" || ext == ".yml" {
+			order = append(order, member)
+		}
+	}
+
+	sort.Strings(order)
+	return order, nil
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type archiveFile struct {
+	name    string
+	content string
+}
+
+func writeZip(t *testing.T, name string, files ...archiveFile) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, f := range files {
+		fw, err := w.Create(f.name)
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, w.Close())
+	require.NoError(t, ioutil.WriteFile(name, buf.Bytes(), os.ModePerm))
+}
+
+func writeTarGz(t *testing.T, name string, files ...archiveFile) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := tar.NewWriter(gz)
+	for _, f := range files {
+		require.NoError(t, w.WriteHeader(&tar.Header{
+			Name: f.name,
+			Mode: 0600,
+			Size: int64(len(f.content)),
+		}))
+
+		_, err := io.WriteString(w, f.content)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, w.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, ioutil.WriteFile(name, buf.Bytes(), os.ModePerm))
+}
+
+func TestArchive(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestArchive")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	files := []archiveFile{
+		{"b.yaml", "a: b\nb: b"},
+		{"a.yml", "a: a\nc: a"},
+		{"README", "not: loaded"},
+	}
+
+	for _, name := range []string{"bundle.zip", "bundle.tar.gz", "bundle.tgz"} {
+		name := filepath.Join(dir, name)
+		if filepath.Ext(name) == ".zip" {
+			writeZip(t, name, files...)
+		} else {
+			writeTarGz(t, name, files...)
+		}
+
+		p, err := NewYAML(Archive(name), Source(bytes.NewBufferString("c: override")))
+		require.NoError(t, err, "Can't load %q", name)
+
+		assert.Equal(t, "b", p.Get("a").Value(), "Members should load in lexical order")
+		assert.Equal(t, "b", p.Get("b").Value())
+		assert.Equal(t, "override", p.Get("c").Value())
+		assert.False(t, p.Get("not").HasValue())
+	}
+}
+
+func TestArchiveManifest(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestArchiveManifest")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	name := filepath.Join(dir, "bundle.zip")
+	writeZip(t, name,
+		archiveFile{"_manifest.yaml", "- ./prod/service.yaml\n- base.yaml"},
+		archiveFile{"base.yaml", "a: base"},
+		archiveFile{"prod/service.yaml", "a: service\nb: service"},
+		archiveFile{"unlisted.yaml", "b: unlisted"},
+	)
+
+	p, err := NewYAML(Archive(name))
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "base", p.Get("a").Value())
+	assert.Equal(t, "service", p.Get("b").Value())
+
+	missing := filepath.Join(dir, "missing.zip")
+	writeZip(t, missing, archiveFile{"_manifest.yaml", "[missing.yaml]"})
+	_, err = NewYAML(Archive(missing))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"missing.yaml" listed in _manifest.yaml is missing`)
+}
+
+func TestArchiveErrors(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestArchiveErrors")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	broken := filepath.Join(dir, "broken.zip")
+	writeZip(t, broken, archiveFile{"a.yaml", "a: ["})
+	_, err = NewYAML(Archive(broken))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken.zip:a.yaml")
+
+	manifest := filepath.Join(dir, "manifest.tgz")
+	writeTarGz(t, manifest, archiveFile{"_manifest.yaml", "a: b"})
+	_, err = NewYAML(Archive(manifest))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't parse _manifest.yaml")
+
+	_, err = NewYAML(Archive(filepath.Join(dir, "bundle.rar")))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported archive format")
+
+	_, err = NewYAML(Archive(filepath.Join(dir, "missing.zip")))
+	assert.Error(t, err)
+}
+
+func TestArchiveWithLimits(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestArchiveWithLimits")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	large := strings.Repeat("a", 1024)
+	for _, name := range []string{"bundle.zip", "bundle.tgz"} {
+		archive := filepath.Join(dir, name)
+		write := writeZip
+		if name == "bundle.tgz" {
+			write = writeTarGz
+		}
+
+		write(t, archive, archiveFile{"a.yaml", "a: b"}, archiveFile{"README", large})
+		p, err := NewYAML(Archive(archive), WithLimits(Limits{MaxFileSize: 16}))
+		require.NoError(t, err, "Members, that aren't loaded, must not count for %s", name)
+		assert.Equal(t, "b", p.Get("a").Value())
+
+		write(t, archive, archiveFile{"a.yaml", "a: " + large})
+		_, err = NewYAML(Archive(archive), WithLimits(Limits{MaxFileSize: 16}))
+		require.Error(t, err, "Expected an error for %s", name)
+		assert.Contains(t, err.Error(), `in member "a.yaml": size of the source exceeds the limit of 16 bytes`)
+	}
+}
+
+// Not parallel, so allocations of other tests don't count.
+func TestArchiveBomb(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestArchiveBomb")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	// Zeros compress well, so the archive is small, but the member is large.
+	bomb := filepath.Join(dir, "bomb.tgz")
+	writeTarGz(t, bomb, archiveFile{"a.yaml", "a: " + strings.Repeat("0", 64<<20)})
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err = NewYAML(Archive(bomb), WithLimits(Limits{MaxFileSize: 1024}))
+	runtime.ReadMemStats(&after)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds the limit of 1024 bytes")
+	assert.True(t, after.TotalAlloc-before.TotalAlloc < 8<<20,
+		"Members must not be read past the limit, allocated %d bytes", after.TotalAlloc-before.TotalAlloc)
+}
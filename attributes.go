@@ -0,0 +1,103 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// An Attribute is a named configuration value, e.g. a resource attribute of
+// traces or a field of logs.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attributes maps configuration keys of a provider to attributes named by
+// the values of the map, so telemetry can carry deployment-relevant
+// configuration without this package depending on a telemetry library, e.g.
+//
+// 	attrs := config.Attributes(p, map[string]string{
+// 		"region":  "cloud.region",
+// 		"cluster": "k8s.cluster.name",
+// 		"flags":   "app.flags",
+// 	})
+//
+// 	kvs := make([]attribute.KeyValue, 0, len(attrs))
+// 	for _, a := range attrs {
+// 		kvs = append(kvs, attribute.String(a.Key, fmt.Sprint(a.Value)))
+// 	}
+//
+// 	res := resource.NewWithAttributes(semconv.SchemaURL, kvs...)
+//
+// Scalars are kept as they are. Mappings and sequences are flattened, with
+// keys and indexes of their elements appended to the attribute names with
+// dots, e.g. "app.flags.beta". Missing keys, nulls and empty collections are
+// skipped. Attributes are sorted by name.
+func Attributes(p Provider, keys map[string]string) []Attribute {
+	var attrs []Attribute
+	var walk func(name string, value interface{})
+	walk = func(name string, value interface{}) {
+		switch v := value.(type) {
+		case map[interface{}]interface{}:
+			for key, child := range v {
+				walk(name+"."+fmt.Sprint(key), child)
+			}
+		case []interface{}:
+			for i, child := range v {
+				walk(name+"."+strconv.Itoa(i), child)
+			}
+		case nil:
+		default:
+			attrs = append(attrs, Attribute{Key: name, Value: v})
+		}
+	}
+
+	for key, name := range keys {
+		if v := p.Get(key); v.HasValue() {
+			walk(name, v.Value())
+		}
+	}
+
+	sort.Sort(attributesByKey(attrs))
+	return attrs
+}
+
+// Fields returns alternating names and values of the attributes, e.g. for
+// loggers like *zap.SugaredLogger and *slog.Logger:
+//
+// 	logger = logger.With(config.Fields(attrs)...)
+func Fields(attrs []Attribute) []interface{} {
+	res := make([]interface{}, 0, 2*len(attrs))
+	for _, a := range attrs {
+		res = append(res, a.Key, a.Value)
+	}
+
+	return res
+}
+
+type attributesByKey []Attribute
+
+func (a attributesByKey) Len() int           { return len(a) }
+func (a attributesByKey) Less(i, j int) bool { return a[i].Key < a[j].Key }
+func (a attributesByKey) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
@@ -0,0 +1,69 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttributes(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAML(Source(strings.NewReader(`
+region: us-east-1
+cluster:
+  name: blue
+  size: 3
+flags:
+  beta: true
+  ratio: 0.5
+zones: [a, b]
+empty: {}
+none: ~
+`)))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	attrs := Attributes(p, map[string]string{
+		"region":       "cloud.region",
+		"cluster.name": "k8s.cluster.name",
+		"flags":        "app.flags",
+		"zones":        "cloud.zones",
+		"empty":        "app.empty",
+		"none":         "app.none",
+		"missing":      "app.missing",
+	})
+
+	assert.Equal(t, []Attribute{
+		{Key: "app.flags.beta", Value: true},
+		{Key: "app.flags.ratio", Value: 0.5},
+		{Key: "cloud.region", Value: "us-east-1"},
+		{Key: "cloud.zones.0", Value: "a"},
+		{Key: "cloud.zones.1", Value: "b"},
+		{Key: "k8s.cluster.name", Value: "blue"},
+	}, attrs)
+
+	assert.Equal(t, []interface{}{"app.flags.beta", true, "app.flags.ratio", 0.5}, Fields(attrs[:2]))
+	assert.Empty(t, Fields(nil))
+}
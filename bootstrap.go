@@ -0,0 +1,165 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// _sourcesKey is a key of the source list in bootstrap configuration.
+const _sourcesKey = "sources"
+
+// Factory creates a provider from its configuration in a bootstrap source
+// list.
+type Factory func(cfg Value) (Provider, error)
+
+var _factories = struct {
+	sync.RWMutex
+	byName map[string]Factory
+}{byName: map[string]Factory{"yaml": yamlFactory}}
+
+// RegisterProvider registers a factory of providers, so they can be listed
+// in bootstrap configuration by the name. The factory receives the whole
+// source entry, including the type key. The "yaml" provider is registered
+// by default. Registration is usually done in init functions.
+func RegisterProvider(name string, factory Factory) error {
+	if name == "" {
+		return errors.New("empty provider name")
+	}
+
+	if factory == nil {
+		return errors.New("received a nil factory")
+	}
+
+	_factories.Lock()
+	defer _factories.Unlock()
+
+	if _, ok := _factories.byName[name]; ok {
+		return fmt.Errorf("provider %q is already registered", name)
+	}
+
+	_factories.byName[name] = factory
+	return nil
+}
+
+// NewProviderFromBootstrap creates a configuration provider from sources
+// listed in bootstrap configuration, so the set of backends is configured
+// instead of coded. Sources are merged in order like providers in a group
+// and are either URLs for NewProviderFromURLs, or entries with a type of
+// a registered provider, e.g.
+//
+// 	sources:
+// 	  - type: yaml
+// 	    files: [base.yaml, production.yaml]
+// 	    strict: true
+// 	  - consul://localhost:8500/service
+// 	  - type: vault
+// 	    path: secret/service
+func NewProviderFromBootstrap(bootstrap Provider) (Provider, error) {
+	if bootstrap == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	sources := bootstrap.Get(_sourcesKey)
+	if !sources.HasValue() {
+		return nil, fmt.Errorf("bootstrap configuration has no %q key", _sourcesKey)
+	}
+
+	list, ok := sources.Value().([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%q must be a list of sources, found %v", _sourcesKey, sources.Value())
+	}
+
+	providers := make([]Provider, 0, len(list))
+	for i := range list {
+		p, err := bootstrapSource(sources.Get(strconv.Itoa(i)))
+		if err != nil {
+			return nil, fmt.Errorf("source %d: %v", i, err)
+		}
+
+		providers = append(providers, p)
+	}
+
+	return NewProviderGroup("bootstrap", providers...)
+}
+
+func bootstrapSource(source Value) (Provider, error) {
+	if u, ok := source.Value().(string); ok {
+		return NewProviderFromURLs(u)
+	}
+
+	name, ok := source.Get(_typeKey).Value().(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a URL or an entry with a %q key, found %v", _typeKey, source.Value())
+	}
+
+	_factories.RLock()
+	factory, ok := _factories.byName[name]
+	names := make([]string, 0, len(_factories.byName))
+	for n := range _factories.byName {
+		names = append(names, n)
+	}
+	_factories.RUnlock()
+
+	if !ok {
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown provider %q, registered providers: %s", name, strings.Join(names, ", "))
+	}
+
+	return factory(source)
+}
+
+// yamlFactory creates a YAML provider from files listed in a source entry.
+func yamlFactory(cfg Value) (Provider, error) {
+	var s struct {
+		Files     []string
+		Separator string
+		Strict    bool
+	}
+
+	if err := cfg.Populate(&s); err != nil {
+		return nil, err
+	}
+
+	if len(s.Files) == 0 {
+		return nil, errors.New("yaml provider needs files")
+	}
+
+	for i, file := range s.Files {
+		s.Files[i] = ExpandPath(file)
+	}
+
+	options := []YAMLOption{File(s.Files...)}
+	if s.Separator != "" {
+		options = append(options, WithSeparator(s.Separator))
+	}
+
+	if s.Strict {
+		options = append(options, WithStrict())
+	}
+
+	return NewYAML(options...)
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProviderFromBootstrap(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, RegisterProvider("TestNewProviderFromBootstrap", func(cfg Value) (Provider, error) {
+		var s struct{ Value string }
+		if err := cfg.Populate(&s); err != nil {
+			return nil, err
+		}
+
+		if s.Value == "" {
+			return nil, errors.New("missing value")
+		}
+
+		return NewStaticProvider(map[string]string{"c": s.Value})
+	}))
+
+	b, err := NewYAMLProviderFromBytes([]byte(`
+sources:
+  - type: yaml
+    files: [testdata/fs/base.yaml]
+  - testdata/fs/dev.yaml
+  - type: TestNewProviderFromBootstrap
+    value: custom
+`))
+	require.NoError(t, err, "Can't create a bootstrap provider")
+
+	p, err := NewProviderFromBootstrap(b)
+	require.NoError(t, err, "Can't create a provider")
+	assert.Equal(t, "base", p.Get("a").Value())
+	assert.Equal(t, "dev", p.Get("b").Value())
+	assert.Equal(t, "custom", p.Get("c").Value())
+
+	assert.Error(t, RegisterProvider("TestNewProviderFromBootstrap", yamlFactory), "Duplicate registration")
+	assert.Error(t, RegisterProvider("", yamlFactory))
+	assert.Error(t, RegisterProvider("nil", nil))
+}
+
+func TestNewProviderFromBootstrapErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewProviderFromBootstrap(nil)
+	assert.EqualError(t, err, "received a nil provider")
+
+	tests := []struct {
+		yaml string
+		err  string
+	}{
+		{"a: b", `bootstrap configuration has no "sources" key`},
+		{"sources: a", `"sources" must be a list of sources`},
+		{"sources: [{files: [a.yaml]}]", `source 0: expected a URL or an entry with a "type" key`},
+		{"sources: [{type: unknown}]", `source 0: unknown provider "unknown", registered providers:`},
+		{"sources: [{type: yaml}]", "source 0: yaml provider needs files"},
+		{"sources: [{type: yaml, files: a.yaml}]", "source 0:"},
+		{"sources: [testdata/fs/base.yaml, testdata/fs/missing.yaml]", "source 1:"},
+	}
+
+	for _, tt := range tests {
+		b, err := NewYAMLProviderFromBytes([]byte(tt.yaml))
+		require.NoError(t, err, "Can't create a bootstrap provider")
+
+		_, err = NewProviderFromBootstrap(b)
+		require.Error(t, err, "Expected an error for %q", tt.yaml)
+		assert.Contains(t, err.Error(), tt.err)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"io"
+)
+
+// A Builder composes a provider from YAML sources and other providers in
+// a chain of calls, e.g.
+//
+// 	p, err := config.NewBuilder().
+// 		File("base.yaml", "production.yaml").
+// 		Provider(remote).
+// 		OptionalFile("local.yaml").
+// 		Expand(os.LookupEnv).
+// 		Strict().
+// 		Build()
+//
+// Setters are shortcuts for the most common options, With takes the others,
+// e.g. WithLimits, the same way NewYAML does. YAML sources are merged in
+// the order they are added, other options apply to all of them regardless
+// of the order of calls. Errors are reported by Build.
+type Builder struct {
+	sources []builderSource
+	options yamlOptions
+	name    string
+}
+
+// A builderSource is either a YAML source or a provider.
+type builderSource struct {
+	yaml     yamlSource
+	provider Provider
+}
+
+// NewBuilder returns an empty builder.
+func NewBuilder() *Builder {
+	return &Builder{name: "builder"}
+}
+
+// With adds YAML sources and options, see NewYAML.
+func (b *Builder) With(options ...YAMLOption) *Builder {
+	for _, option := range options {
+		option(&b.options)
+		for _, s := range b.options.sources {
+			b.sources = append(b.sources, builderSource{yaml: s})
+		}
+
+		b.options.sources = nil
+	}
+
+	return b
+}
+
+// File adds YAML files, see config.File.
+func (b *Builder) File(files ...string) *Builder {
+	return b.With(File(files...))
+}
+
+// OptionalFile adds YAML files, that are skipped if they don't exist, see
+// config.OptionalFile.
+func (b *Builder) OptionalFile(files ...string) *Builder {
+	return b.With(OptionalFile(files...))
+}
+
+// Reader adds YAML readers, see config.Source.
+func (b *Builder) Reader(readers ...io.Reader) *Builder {
+	return b.With(Source(readers...))
+}
+
+// Bytes adds YAML blobs, see config.SourceBytes.
+func (b *Builder) Bytes(yamls ...[]byte) *Builder {
+	return b.With(SourceBytes(yamls...))
+}
+
+// Expand replaces ${var} and $var sequences in YAML sources based on
+// the mapping function, see config.WithExpand.
+func (b *Builder) Expand(mapping func(string) (string, bool)) *Builder {
+	return b.With(WithExpand(mapping))
+}
+
+// Separator sets a custom key separator, see config.WithSeparator.
+func (b *Builder) Separator(separator string) *Builder {
+	return b.With(WithSeparator(separator))
+}
+
+// Strict makes Build fail if YAML sources have duplicate keys, see
+// config.WithStrict.
+func (b *Builder) Strict() *Builder {
+	return b.With(WithStrict())
+}
+
+// Logger sets a logger for loaded sources and skipped optional files, see
+// config.WithLogger.
+func (b *Builder) Logger(l Logger) *Builder {
+	return b.With(WithLogger(l))
+}
+
+// Provider adds a provider, e.g. a remote one, overriding values of
+// the sources added before it.
+func (b *Builder) Provider(p Provider) *Builder {
+	if p == nil {
+		b.fail(errors.New("received a nil provider"))
+		return b
+	}
+
+	b.sources = append(b.sources, builderSource{provider: p})
+	return b
+}
+
+// Name sets a name of the provider group created when providers are added
+// along with YAML sources, "builder" is used by default.
+func (b *Builder) Name(name string) *Builder {
+	b.name = name
+	return b
+}
+
+func (b *Builder) fail(err error) {
+	if b.options.err == nil {
+		b.options.err = err
+	}
+}
+
+// Build creates the provider. Consecutive YAML sources are merged into one
+// YAML provider, that is grouped with added providers if there are any.
+func (b *Builder) Build() (Provider, error) {
+	if err := b.options.check(); err != nil {
+		return nil, err
+	}
+
+	var providers []Provider
+	var yamls []yamlSource
+	flush := func() error {
+		if len(yamls) == 0 {
+			return nil
+		}
+
+		o := b.options
+		o.sources = yamls
+		p, err := o.provider()
+		if err != nil {
+			return err
+		}
+
+		providers = append(providers, p)
+		yamls = nil
+		return nil
+	}
+
+	for _, s := range b.sources {
+		if s.provider == nil {
+			yamls = append(yamls, s.yaml)
+			continue
+		}
+
+		if err := flush(); err != nil {
+			return nil, err
+		}
+
+		providers = append(providers, s.provider)
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	switch len(providers) {
+	case 0:
+		return b.options.provider()
+	case 1:
+		return providers[0], nil
+	}
+
+	return NewProviderGroup(b.name, providers...)
+}
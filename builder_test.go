@@ -0,0 +1,163 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+
+	static, err := NewStaticProvider(map[string]string{"owner": "static"})
+	require.NoError(t, err, "Can't create a static provider")
+
+	lookup := func(key string) (string, bool) {
+		if key == "ENV" {
+			return "prod", true
+		}
+
+		return "", false
+	}
+
+	p, err := NewBuilder().
+		File("./testdata/base.yaml").
+		Bytes([]byte("env: ${ENV}\nowner: bytes")).
+		Provider(static).
+		Reader(bytes.NewBufferString("modules:\n  rpc:\n    bind: ${BIND:localhost}")).
+		Expand(lookup).
+		Name("service").
+		Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, "service", p.Name())
+	assert.Equal(t, "base_only", p.Get("value").Value())
+	assert.Equal(t, "prod", p.Get("env").Value())
+	assert.Equal(t, "static", p.Get("owner").Value())
+	assert.Equal(t, "localhost", p.Get("modules.rpc.bind").Value())
+}
+
+func TestBuilderSingleProvider(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewBuilder().Bytes([]byte("a:\n  b.c: d")).Separator("/").Build()
+	require.NoError(t, err)
+	assert.Equal(t, "d", p.Get("a/b.c").Value())
+
+	p, err = NewBuilder().Build()
+	require.NoError(t, err)
+	assert.False(t, p.Get("a").HasValue())
+}
+
+func TestBuilderOptionalFile(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	p, err := NewBuilder().
+		File("./testdata/base.yaml").
+		OptionalFile("./testdata/missing.yaml").
+		Logger(StdLogger(log.New(&buf, "", 0))).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, "base_only", p.Get("value").Value())
+	assert.Contains(t, buf.String(), "missing.yaml")
+}
+
+func TestBuilderStrict(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"a: 1\na: 2":                          `duplicate key "a"`,
+		"a:\n  b: 1\n  b: 2":                  `duplicate key "b"`,
+		"a:\n  - b: 1\n    b: 2":              `duplicate key "b"`,
+		"a:\n  - [{b: 1, b: 2}]":              `duplicate key "b"`,
+		"a: &a\n  b: 1\nc:\n  <<: *a\n  b: 2": "",
+		"a: [1, 1]\nb: 1":                     "",
+	}
+
+	for yaml, msg := range tests {
+		_, err := NewBuilder().With(SourceBytes([]byte(yaml))).Build()
+		require.NoError(t, err, "Unexpected error for %q", yaml)
+
+		p, err := NewBuilder().Bytes([]byte(yaml)).Strict().Build()
+		if msg == "" {
+			require.NoError(t, err, "Unexpected error for %q", yaml)
+			continue
+		}
+
+		require.Error(t, err, "Expected an error for %q", yaml)
+		assert.Contains(t, err.Error(), msg, "Unexpected error for %q", yaml)
+		assert.Nil(t, p)
+	}
+
+	p, err := NewBuilder().With(SourceBytes([]byte("a: &a\n  b: 1\nc:\n  <<: *a\n  b: 2")), WithStrict()).Build()
+	require.NoError(t, err)
+	assert.Equal(t, 2, p.Get("c.b").Value())
+}
+
+func TestBuilderErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewBuilder().Provider(nil).Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "received a nil provider")
+
+	_, err = NewBuilder().Separator("").Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty key separator")
+
+	_, err = NewBuilder().With(File("./testdata/base.yaml", "./testdata/missing.yaml")).Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing.yaml")
+
+	_, err = NewBuilder().With(File("./testdata/base.yaml"), SourceBytes([]byte("a: ["))).Build()
+	require.Error(t, err)
+}
+
+func TestBuilderWithOptions(t *testing.T) {
+	t.Parallel()
+
+	static, err := NewStaticProvider(map[string]string{"b": "static"})
+	require.NoError(t, err, "Can't create a static provider")
+
+	var report LoadReport
+	p, err := NewBuilder().
+		With(Source(bytes.NewBufferString("a: 1\nb: yaml")), WithLoadReport(&report)).
+		Provider(static).
+		With(WithLazyLoad(), WithKeyOrder()).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.Get("a").Value())
+	assert.Equal(t, "static", p.Get("b").Value())
+	assert.Len(t, report.Sources, 1, "Options should apply to YAML sources of the builder")
+
+	_, err = NewBuilder().With(WithParallelLoad(0)).Provider(static).Build()
+	assert.EqualError(t, err, "number of sources to load in parallel must be positive")
+
+	_, err = NewBuilder().With(WithStrict(), WithUnmarshaler(json.Unmarshal)).Build()
+	assert.EqualError(t, err, "WithStrict can't be combined with WithUnmarshaler")
+}
@@ -51,6 +51,14 @@ func (p *cachedProvider) Name() string {
 	return fmt.Sprintf("cached %q", p.Provider.Name())
 }
 
+func (p *cachedProvider) separator() string {
+	return separatorOf(p.Provider)
+}
+
+func (p *cachedProvider) keyOrder() *keyOrder {
+	return keyOrderOf(p.Provider)
+}
+
 // Get retrieves a Value and caches it internally.
 // The value is cached only if it is found.
 func (p *cachedProvider) Get(key string) Value {
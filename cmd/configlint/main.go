@@ -0,0 +1,99 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Command configlint checks YAML configuration files with the rules of
+// go.uber.org/config/lint and fails if there are errors.
+//
+// Usage:
+//
+// 	configlint [-banned keys] [-durations suffixes] [-naming regexp] file...
+//
+// The files are merged in order before they are checked.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"go.uber.org/config"
+	"go.uber.org/config/lint"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("configlint", flag.ContinueOnError)
+	banned := fs.String("banned", "", "comma separated keys that must not be set")
+	durations := fs.String("durations", "timeout,interval,period,ttl", "comma separated suffixes of duration keys")
+	naming := fs.String("naming", "", "regular expression map keys must match")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		return errors.New("expected configuration files")
+	}
+
+	var rules []lint.Rule
+	if *banned != "" {
+		rules = append(rules, lint.BannedKeys(strings.Split(*banned, ",")...))
+	}
+
+	if *durations != "" {
+		rules = append(rules, lint.DurationUnits(strings.Split(*durations, ",")...))
+	}
+
+	if *naming != "" {
+		re, err := regexp.Compile(*naming)
+		if err != nil {
+			return err
+		}
+
+		rules = append(rules, lint.NamingConvention(re))
+	}
+
+	p, err := config.NewYAMLProviderFromFiles(fs.Args()...)
+	if err != nil {
+		return err
+	}
+
+	errs := 0
+	for _, issue := range lint.Lint(p, rules...) {
+		fmt.Println(issue)
+		if issue.Severity == lint.Error {
+			errs++
+		}
+	}
+
+	if errs > 0 {
+		return fmt.Errorf("found %d errors", errs)
+	}
+
+	return nil
+}
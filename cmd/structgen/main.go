@@ -0,0 +1,82 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Command structgen prints Go struct definitions inferred from a sample YAML
+// configuration file, see go.uber.org/config/structgen for details.
+//
+// Usage:
+//
+// 	structgen [-package name] [-type name] [-o file] [sample.yaml]
+//
+// The sample is read from the standard input if no file is provided.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"go.uber.org/config/structgen"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("structgen", flag.ContinueOnError)
+	pkg := fs.String("package", "config", "package name of the generated code")
+	name := fs.String("type", "Config", "name of the root struct")
+	out := fs.String("o", "", "output file, standard output by default")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var sample []byte
+	var err error
+	switch fs.NArg() {
+	case 0:
+		sample, err = ioutil.ReadAll(os.Stdin)
+	case 1:
+		sample, err = ioutil.ReadFile(fs.Arg(0))
+	default:
+		return fmt.Errorf("expected at most one sample file, got %d", fs.NArg())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	src, err := structgen.Generate(sample, structgen.Options{Package: *pkg, Name: *name})
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+
+	return ioutil.WriteFile(*out, src, 0644)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Decompressor wraps a reader of compressed data with a reader of
+// decompressed data.
+type Decompressor func(io.Reader) (io.ReadCloser, error)
+
+var _decompressors = struct {
+	sync.RWMutex
+	byExt map[string]Decompressor
+}{byExt: map[string]Decompressor{
+	".gz": func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+}}
+
+// RegisterDecompressor registers a decompressor for files with the
+// extension, so file loaders decompress them transparently, e.g. to load
+// "config.yaml.zst" files:
+//
+// 	config.RegisterDecompressor(".zst", func(r io.Reader) (io.ReadCloser, error) {
+// 		d, err := zstd.NewReader(r)
+// 		if err != nil {
+// 			return nil, err
+// 		}
+//
+// 		return d.IOReadCloser(), nil
+// 	})
+//
+// Files with the ".gz" extension are decompressed with gzip by default.
+// Registration is usually done in init functions.
+func RegisterDecompressor(ext string, d Decompressor) error {
+	if !strings.HasPrefix(ext, ".") || len(ext) < 2 {
+		return fmt.Errorf("extension must start with a dot, got %q", ext)
+	}
+
+	if d == nil {
+		return errors.New("received a nil decompressor")
+	}
+
+	_decompressors.Lock()
+	defer _decompressors.Unlock()
+
+	if _, ok := _decompressors.byExt[ext]; ok {
+		return fmt.Errorf("decompressor for %q is already registered", ext)
+	}
+
+	_decompressors.byExt[ext] = d
+	return nil
+}
+
+func decompressorFor(file string) Decompressor {
+	_decompressors.RLock()
+	defer _decompressors.RUnlock()
+
+	return _decompressors.byExt[filepath.Ext(file)]
+}
+
+// decompressedFile closes both the decompressor and the file.
+type decompressedFile struct {
+	io.ReadCloser
+	file *os.File
+}
+
+func (f decompressedFile) Close() error {
+	err := f.ReadCloser.Close()
+	if ferr := f.file.Close(); err == nil {
+		err = ferr
+	}
+
+	return err
+}
+
+// openFile opens a file and decompresses it, if there is a decompressor
+// registered for its extension.
+func openFile(name string) (io.ReadCloser, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	d := decompressorFor(name)
+	if d == nil {
+		return file, nil
+	}
+
+	r, err := d(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("can't decompress %q: %v", name, err)
+	}
+
+	return decompressedFile{ReadCloser: r, file: file}, nil
+}
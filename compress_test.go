@@ -0,0 +1,106 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeGzip(t *testing.T, name string, content string) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, ioutil.WriteFile(name, buf.Bytes(), os.ModePerm))
+}
+
+func TestGzipFiles(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestGzipFiles")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	gz := filepath.Join(dir, "base.yaml.gz")
+	writeGzip(t, gz, "a: 1\nb: 2")
+
+	plain := filepath.Join(dir, "dev.yaml")
+	require.NoError(t, ioutil.WriteFile(plain, []byte("b: 3"), os.ModePerm))
+
+	p, err := NewYAMLProviderFromFiles(gz, plain)
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, 1, p.Get("a").Value())
+	assert.Equal(t, 3, p.Get("b").Value())
+
+	broken := filepath.Join(dir, "broken.yaml.gz")
+	writeGzip(t, broken, "a: [")
+	_, err = NewYAMLProviderFromFiles(broken)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken.yaml.gz")
+
+	notGzip := filepath.Join(dir, "plain.yaml.gz")
+	require.NoError(t, ioutil.WriteFile(notGzip, []byte("a: 1"), os.ModePerm))
+	_, err = NewYAMLProviderFromFiles(notGzip)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't decompress")
+}
+
+func TestRegisterDecompressor(t *testing.T) {
+	t.Parallel()
+
+	upper := func(r io.Reader) (io.ReadCloser, error) {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return ioutil.NopCloser(strings.NewReader(strings.ToLower(string(b)))), nil
+	}
+
+	require.NoError(t, RegisterDecompressor(".upper", upper))
+	assert.Error(t, RegisterDecompressor(".upper", upper), "Duplicate registration")
+	assert.Error(t, RegisterDecompressor(".gz", upper), "Gzip is registered by default")
+	assert.Error(t, RegisterDecompressor("upper", upper), "Extension without a dot")
+	assert.Error(t, RegisterDecompressor(".", upper), "Empty extension")
+	assert.Error(t, RegisterDecompressor(".nil", nil))
+
+	dir, err := ioutil.TempDir("", "TestRegisterDecompressor")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	name := filepath.Join(dir, "config.yaml.upper")
+	require.NoError(t, ioutil.WriteFile(name, []byte("A: B"), os.ModePerm))
+
+	p, err := NewYAMLProviderFromFilesWithIncludes(name)
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "b", p.Get("a").Value())
+}
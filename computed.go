@@ -0,0 +1,114 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// A ComputeFunc derives a value from other values of a provider.
+type ComputeFunc func(Provider) (interface{}, error)
+
+// NewProviderWithComputed creates a provider, where values of the keys are
+// computed from values of the provider, e.g.
+//
+// 	p, err := config.NewProviderWithComputed(base, map[string]config.ComputeFunc{
+// 		"db.dsn": func(p config.Provider) (interface{}, error) {
+// 			return fmt.Sprintf("%s:%s", p.Get("db.host"), p.Get("db.port")), nil
+// 		},
+// 	})
+//
+// makes "db.dsn" available to consumers as any other key. Functions are
+// called once, when the provider is created, and computed values override
+// values of the provider.
+func NewProviderWithComputed(p Provider, computed map[string]ComputeFunc) (Provider, error) {
+	if p == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	sep := separatorOf(p)
+	keys := make([]string, 0, len(computed))
+	for key := range computed {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	root := make(map[interface{}]interface{})
+	for _, key := range keys {
+		if computed[key] == nil {
+			return nil, fmt.Errorf("received a nil function for key %q", key)
+		}
+
+		v, err := computed[key](p)
+		if err != nil {
+			return nil, errorWithKey(err, key)
+		}
+
+		if err := setValue(root, splitKey(key, sep), v, sep); err != nil {
+			return nil, errorWithKey(err, key)
+		}
+	}
+
+	reader, err := toReader(root)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := NewYAMLProviderFromReaderWithSeparator(sep, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewProviderGroup(fmt.Sprintf("%s with computed keys", p.Name()), p, values)
+}
+
+// setValue sets a value in a tree of maps by the key segments.
+func setValue(root map[interface{}]interface{}, segments []string, value interface{}, sep string) error {
+	node := root
+	for i, segment := range segments {
+		segment = unescapeSeparators(segment, sep)
+		if i == len(segments)-1 {
+			if _, ok := node[segment]; ok {
+				return errors.New("key conflicts with another key")
+			}
+
+			node[segment] = value
+			return nil
+		}
+
+		child, ok := node[segment]
+		if !ok {
+			child = make(map[interface{}]interface{})
+			node[segment] = child
+		}
+
+		m, ok := child.(map[interface{}]interface{})
+		if !ok {
+			return errors.New("key conflicts with another key")
+		}
+
+		node = m
+	}
+
+	return nil
+}
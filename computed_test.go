@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProviderWithComputed(t *testing.T) {
+	t.Parallel()
+
+	base, err := NewYAMLProviderFromBytes([]byte(`
+db:
+  host: localhost
+  port: 5432
+  dsn: unused
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	p, err := NewProviderWithComputed(base, map[string]ComputeFunc{
+		"db.dsn": func(p Provider) (interface{}, error) {
+			return fmt.Sprintf("postgres://%s:%s", p.Get("db.host"), p.Get("db.port")), nil
+		},
+		"db.replicas": func(p Provider) (interface{}, error) {
+			return []string{"a", "b"}, nil
+		},
+		`hosts.my\.host`: func(p Provider) (interface{}, error) {
+			return 80, nil
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `cached "yaml" with computed keys`, p.Name())
+	assert.Equal(t, "postgres://localhost:5432", p.Get("db.dsn").Value())
+	assert.Equal(t, "b", p.Get("db.replicas.1").Value())
+	assert.Equal(t, 80, p.Get(`hosts.my\.host`).Value())
+
+	var db struct {
+		Host string
+		DSN  string `yaml:"dsn"`
+	}
+
+	require.NoError(t, p.Get("db").Populate(&db))
+	assert.Equal(t, "localhost", db.Host)
+	assert.Equal(t, "postgres://localhost:5432", db.DSN)
+}
+
+func TestNewProviderWithComputedErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewProviderWithComputed(nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "received a nil provider")
+
+	_, err = NewProviderWithComputed(NopProvider{}, map[string]ComputeFunc{"a": nil})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `received a nil function for key "a"`)
+
+	_, err = NewProviderWithComputed(NopProvider{}, map[string]ComputeFunc{
+		"a": func(Provider) (interface{}, error) { return nil, errors.New("boom") },
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `for key "a": boom`)
+
+	one := func(Provider) (interface{}, error) { return 1, nil }
+	_, err = NewProviderWithComputed(NopProvider{}, map[string]ComputeFunc{"a": one, "a.b": one})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `for key "a.b": key conflicts with another key`)
+}
@@ -0,0 +1,299 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// _whenKey holds a condition of the enclosing block.
+const _whenKey = "_when"
+
+// NewProviderWithConditions creates a provider, where blocks with a _when key
+// are kept only if their conditions hold for the variables, e.g. with
+// the "env" variable set to "prod"
+//
+// 	replicas: 1
+// 	production:
+// 	  _when: env == "prod" && region != "eu"
+// 	  replicas: 10
+//
+// has the production block unless the "region" variable is "eu". Blocks
+// in sequences are removed from the sequences. Conditions compare variables
+// and quoted strings with == and !=, and combine comparisons with &&, ||, !
+// and parentheses. A variable on its own holds if it is "true". Unknown
+// variables are reported as errors, so typos don't silently drop blocks.
+// The _when keys are case insensitive like other keys and are not visible in
+// the new provider.
+func NewProviderWithConditions(p Provider, vars map[string]string) (Provider, error) {
+	if p == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	separator := separatorOf(p)
+	root, _, err := applyConditions(nil, p.Get(Root).Value(), vars, separator)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDerivedProvider(p, root)
+}
+
+// applyConditions returns a copy of the node without blocks with false
+// conditions and reports whether the node itself should be kept.
+func applyConditions(path []string, node interface{}, vars map[string]string, separator string) (interface{}, bool, error) {
+	switch n := node.(type) {
+	case map[interface{}]interface{}:
+		// Keys are case insensitive, so all the conditions of blocks with
+		// several ones, e.g. _when and _When, must hold.
+		for k, cond := range n {
+			if !isWhenKey(k) {
+				continue
+			}
+
+			holds, err := evalCondition(cond, vars)
+			if err != nil {
+				return nil, false, fmt.Errorf("can't evaluate %s at %q: %v",
+					k, strings.Join(path, separator), err)
+			}
+
+			if !holds {
+				return nil, false, nil
+			}
+		}
+
+		m := make(map[interface{}]interface{}, len(n))
+		for k, v := range n {
+			if isWhenKey(k) {
+				continue
+			}
+
+			child, keep, err := applyConditions(childPath(path, fmt.Sprint(k)), v, vars, separator)
+			if err != nil {
+				return nil, false, err
+			}
+
+			if keep {
+				m[k] = child
+			}
+		}
+
+		return m, true, nil
+	case []interface{}:
+		s := make([]interface{}, 0, len(n))
+		for i, v := range n {
+			child, keep, err := applyConditions(childPath(path, strconv.Itoa(i)), v, vars, separator)
+			if err != nil {
+				return nil, false, err
+			}
+
+			if keep {
+				s = append(s, child)
+			}
+		}
+
+		return s, true, nil
+	}
+
+	return node, true, nil
+}
+
+func isWhenKey(key interface{}) bool {
+	s, ok := key.(string)
+	return ok && strings.EqualFold(s, _whenKey)
+}
+
+func evalCondition(cond interface{}, vars map[string]string) (bool, error) {
+	switch c := cond.(type) {
+	case bool:
+		return c, nil
+	case string:
+		tokens, err := tokenizeCondition(c)
+		if err != nil {
+			return false, err
+		}
+
+		e := conditionEvaluator{tokens: tokens, vars: vars}
+		res, err := e.or()
+		if err == nil && e.pos < len(e.tokens) {
+			err = fmt.Errorf("unexpected %q", e.tokens[e.pos].text)
+		}
+
+		return res, err
+	}
+
+	return false, fmt.Errorf("condition must be a string or a boolean, found %v", cond)
+}
+
+type conditionToken struct {
+	text string
+
+	// Quoted strings are literals, other operands are variables.
+	literal bool
+}
+
+func tokenizeCondition(s string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, conditionToken{text: s[i : i+1]})
+			i++
+		case c == '!' && !strings.HasPrefix(s[i:], "!="):
+			tokens = append(tokens, conditionToken{text: "!"})
+			i++
+		case strings.HasPrefix(s[i:], "==") || strings.HasPrefix(s[i:], "!=") ||
+			strings.HasPrefix(s[i:], "&&") || strings.HasPrefix(s[i:], "||"):
+			tokens = append(tokens, conditionToken{text: s[i : i+2]})
+			i += 2
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return nil, errors.New("unterminated string")
+			}
+
+			tokens = append(tokens, conditionToken{text: s[i+1 : i+1+end], literal: true})
+			i += end + 2
+		case isVariableChar(rune(c)):
+			start := i
+			for i < len(s) && isVariableChar(rune(s[i])) {
+				i++
+			}
+
+			tokens = append(tokens, conditionToken{text: s[start:i]})
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+
+	return tokens, nil
+}
+
+func isVariableChar(r rune) bool {
+	return r == '_' || r == '.' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// conditionEvaluator evaluates tokens with recursive descent.
+type conditionEvaluator struct {
+	tokens []conditionToken
+	pos    int
+	vars   map[string]string
+}
+
+func (e *conditionEvaluator) peek(text string) bool {
+	return e.pos < len(e.tokens) && !e.tokens[e.pos].literal && e.tokens[e.pos].text == text
+}
+
+func (e *conditionEvaluator) or() (bool, error) {
+	res, err := e.and()
+	for err == nil && e.peek("||") {
+		e.pos++
+		var next bool
+		next, err = e.and()
+		res = res || next
+	}
+
+	return res, err
+}
+
+func (e *conditionEvaluator) and() (bool, error) {
+	res, err := e.unary()
+	for err == nil && e.peek("&&") {
+		e.pos++
+		var next bool
+		next, err = e.unary()
+		res = res && next
+	}
+
+	return res, err
+}
+
+func (e *conditionEvaluator) unary() (bool, error) {
+	if e.peek("!") {
+		e.pos++
+		res, err := e.unary()
+		return !res, err
+	}
+
+	if e.peek("(") {
+		e.pos++
+		res, err := e.or()
+		if err != nil {
+			return false, err
+		}
+
+		if !e.peek(")") {
+			return false, errors.New("missing closing parenthesis")
+		}
+
+		e.pos++
+		return res, nil
+	}
+
+	left, err := e.operand()
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case e.peek("=="), e.peek("!="):
+		equal := e.peek("==")
+		e.pos++
+		right, err := e.operand()
+		if err != nil {
+			return false, err
+		}
+
+		return (left == right) == equal, nil
+	}
+
+	return left == "true", nil
+}
+
+func (e *conditionEvaluator) operand() (string, error) {
+	if e.pos >= len(e.tokens) {
+		return "", errors.New("unexpected end of condition")
+	}
+
+	t := e.tokens[e.pos]
+	if t.literal {
+		e.pos++
+		return t.text, nil
+	}
+
+	if !isVariableChar([]rune(t.text)[0]) {
+		return "", fmt.Errorf("unexpected %q", t.text)
+	}
+
+	v, ok := e.vars[t.text]
+	if !ok {
+		return "", fmt.Errorf("unknown variable %q", t.text)
+	}
+
+	e.pos++
+	return v, nil
+}
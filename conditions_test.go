@@ -0,0 +1,131 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProviderWithConditions(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+replicas: 1
+production:
+  _when: env == "prod" && region != 'eu'
+  replicas: 10
+staging:
+  _when: env == "staging" || (debug && !(region == "us"))
+  replicas: 2
+always:
+  _when: true
+  value: 1
+servers:
+  - host: a
+  - _when: env != "prod"
+    host: b
+  - host: c
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	c, err := NewProviderWithConditions(p, map[string]string{"env": "prod", "region": "us", "debug": "true"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 10, c.Get("production.replicas").Value())
+	assert.False(t, c.Get("production._when").HasValue())
+	assert.False(t, c.Get("staging").HasValue())
+	assert.Equal(t, 1, c.Get("always.value").Value())
+	assert.Equal(t, []interface{}{
+		map[interface{}]interface{}{"host": "a"},
+		map[interface{}]interface{}{"host": "c"},
+	}, c.Get("servers").Value())
+
+	c, err = NewProviderWithConditions(p, map[string]string{"env": "dev", "region": "eu", "debug": "true"})
+	require.NoError(t, err)
+
+	assert.False(t, c.Get("production").HasValue())
+	assert.Equal(t, 2, c.Get("staging.replicas").Value())
+	assert.Equal(t, "b", c.Get("servers.1.host").Value())
+	assert.Len(t, p.Get("servers").Value(), 3, "The original provider shouldn't change")
+	assert.True(t, p.Get("production._when").HasValue(), "The original provider shouldn't change")
+}
+
+func TestNewProviderWithConditionsCaseInsensitive(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+production:
+  _When: env == "prod"
+  replicas: 10
+both:
+  _when: env == "prod"
+  _WHEN: region == "eu"
+  replicas: 2
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	c, err := NewProviderWithConditions(p, map[string]string{"env": "prod", "region": "us"})
+	require.NoError(t, err)
+	assert.Equal(t, map[interface{}]interface{}{"replicas": 10}, c.Get("production").Value())
+	assert.False(t, c.Get("both").HasValue(), "Expected all the conditions to hold")
+
+	c, err = NewProviderWithConditions(p, map[string]string{"env": "dev"})
+	require.NoError(t, err)
+	assert.False(t, c.Get("production").HasValue())
+}
+
+func TestNewProviderWithConditionsErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewProviderWithConditions(nil, nil)
+	require.Error(t, err)
+
+	tests := map[string]string{
+		`env = "prod"`:        `unexpected character '='`,
+		`env == "prod`:        "unterminated string",
+		`(env == "prod"`:      "missing closing parenthesis",
+		`env ==`:              "unexpected end of condition",
+		`env == "prod" "dev"`: `unexpected "dev"`,
+		`missing == "prod"`:   `unknown variable "missing"`,
+		`env == && env`:       `unexpected "&&"`,
+		`1`:                   `unknown variable "1"`,
+	}
+
+	for cond, msg := range tests {
+		p, err := NewStaticProvider(map[string]interface{}{"a": map[string]string{"_when": cond}})
+		require.NoError(t, err, "Can't create a static provider")
+
+		_, err = NewProviderWithConditions(p, map[string]string{"env": "prod"})
+		require.Error(t, err, "Expected an error for %q", cond)
+		assert.Contains(t, err.Error(), `can't evaluate _when at "a"`)
+		assert.Contains(t, err.Error(), msg, "Unexpected error for %q", cond)
+	}
+
+	p, err := NewStaticProvider(map[string]interface{}{"_when": 1})
+	require.NoError(t, err, "Can't create a static provider")
+
+	_, err = NewProviderWithConditions(p, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "condition must be a string or a boolean")
+}
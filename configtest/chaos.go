@@ -0,0 +1,203 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package configtest provides helpers for tests of code using configuration,
+// e.g. a provider injecting faults.
+package configtest // import "go.uber.org/config/configtest"
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.uber.org/config"
+)
+
+// ErrChaos is returned for faults a ChaosProvider injects.
+var ErrChaos = errors.New("config: injected fault")
+
+// Chaos configures faults a ChaosProvider injects. Rates are fractions of
+// calls from 0 to 1.
+type Chaos struct {
+	// Latency is added to every Get and Update.
+	Latency time.Duration
+
+	// ErrorRate of Gets return missing values, of Healthy calls report
+	// ErrChaos and of Updates fail with it.
+	ErrorRate float64
+
+	// StaleRate of Gets return values of the provider before the last
+	// Update.
+	StaleRate float64
+
+	// Seed makes faults reproducible, the current time is used if it is
+	// zero.
+	Seed int64
+}
+
+// A ChaosProvider injects latency, errors and stale values into lookups of
+// a provider, so tests can check how services behave when a configuration
+// backend misbehaves, e.g.
+//
+// 	p, err := configtest.NewChaosProvider(base, configtest.Chaos{
+// 		Latency:   10 * time.Millisecond,
+// 		ErrorRate: 0.1,
+// 		StaleRate: 0.2,
+// 	})
+// 	...
+// 	// Simulate a reload, which can fail with configtest.ErrChaos.
+// 	err = p.Update(reloaded)
+//
+// Lookups of children of values go through the faults too and split keys
+// with the separator of the provider the ChaosProvider was created with.
+type ChaosProvider struct {
+	chaos    Chaos
+	injected config.Provider
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	current  config.Provider
+	previous config.Provider
+}
+
+// NewChaosProvider wraps a provider with faults.
+func NewChaosProvider(p config.Provider, c Chaos) (*ChaosProvider, error) {
+	if p == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	if c.ErrorRate < 0 || c.ErrorRate > 1 || c.StaleRate < 0 || c.StaleRate > 1 {
+		return nil, errors.New("rates must be between 0 and 1")
+	}
+
+	seed := c.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	cp := &ChaosProvider{
+		chaos:    c,
+		rand:     rand.New(rand.NewSource(seed)),
+		current:  p,
+		previous: p,
+	}
+
+	// Interceptors route lookups of children and fields through the faults.
+	injected, err := config.NewProviderWithInterceptors(p, func(config.Lookup) config.Lookup {
+		return cp.lookup
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cp.injected = injected
+	return cp, nil
+}
+
+// Name returns the name of the underlying provider with chaos.
+func (c *ChaosProvider) Name() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return fmt.Sprintf("%s with chaos", c.current.Name())
+}
+
+// Get returns a value of the underlying provider, a missing value or a
+// value of the provider before the last Update, after the latency.
+func (c *ChaosProvider) Get(key string) config.Value {
+	return c.injected.Get(key)
+}
+
+func (c *ChaosProvider) lookup(key string) config.Value {
+	time.Sleep(c.chaos.Latency)
+
+	c.mu.Lock()
+	p := c.current
+	switch f := c.rand.Float64(); {
+	case f < c.chaos.ErrorRate:
+		p = nil
+	case f < c.chaos.ErrorRate+c.chaos.StaleRate:
+		p = c.previous
+	}
+	c.mu.Unlock()
+
+	if p == nil {
+		return config.NewValue(c, key, nil, false)
+	}
+
+	return p.Get(key)
+}
+
+// Update replaces the underlying provider, like a reload does, keeping the
+// old one for stale values. It fails with ErrChaos at the error rate and
+// keeps the current provider then.
+func (c *ChaosProvider) Update(p config.Provider) error {
+	if p == nil {
+		return errors.New("received a nil provider")
+	}
+
+	time.Sleep(c.chaos.Latency)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rand.Float64() < c.chaos.ErrorRate {
+		return ErrChaos
+	}
+
+	c.previous, c.current = c.current, p
+	return nil
+}
+
+// Healthy reports ErrChaos at the error rate, or health of the underlying
+// provider.
+func (c *ChaosProvider) Healthy() error {
+	c.mu.Lock()
+	p := c.current
+	failed := c.rand.Float64() < c.chaos.ErrorRate
+	c.mu.Unlock()
+
+	if failed {
+		return ErrChaos
+	}
+
+	return config.Healthy(p)
+}
+
+// Metadata returns metadata of values of the underlying provider.
+func (c *ChaosProvider) Metadata(key string) config.Metadata {
+	c.mu.Lock()
+	p := c.current
+	c.mu.Unlock()
+	return p.Get(key).Metadata()
+}
+
+// Describe returns the description of the underlying provider under the
+// name of the chaos provider.
+func (c *ChaosProvider) Describe() config.Description {
+	c.mu.Lock()
+	p := c.current
+	c.mu.Unlock()
+
+	d := config.Describe(p)
+	d.Name = fmt.Sprintf("%s with chaos", p.Name())
+	return d
+}
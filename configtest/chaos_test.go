@@ -0,0 +1,110 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package configtest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaosProvider(t *testing.T) {
+	t.Parallel()
+
+	base, err := config.NewYAMLProviderFromBytes([]byte("a: {b: 1}"))
+	require.NoError(t, err, "Can't create a YAML provider")
+	reloaded, err := config.NewYAMLProviderFromBytes([]byte("a: {b: 2}"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	p, err := NewChaosProvider(base, Chaos{ErrorRate: 0.2, StaleRate: 0.3, Seed: 1})
+	require.NoError(t, err)
+	assert.Equal(t, base.Name()+" with chaos", p.Name())
+
+	for p.Update(reloaded) != nil {
+	}
+
+	counts := make(map[interface{}]int)
+	for i := 0; i < 1000; i++ {
+		// Lookups of children go through the faults too.
+		counts[p.Get("a").Get("b").Value()]++
+	}
+
+	assert.InDelta(t, 200, counts[nil], 50, "Missing values are expected at the error rate")
+	assert.InDelta(t, 300, counts[1], 50, "Stale values are expected at the stale rate")
+	assert.InDelta(t, 500, counts[2], 50, "Current values are expected")
+
+	var failed int
+	for i := 0; i < 1000; i++ {
+		if p.Healthy() == ErrChaos {
+			failed++
+		}
+	}
+
+	assert.InDelta(t, 200, failed, 50, "Health checks should fail at the error rate")
+}
+
+func TestChaosProviderSeparator(t *testing.T) {
+	t.Parallel()
+
+	base, err := config.NewYAML(config.Source(strings.NewReader("a: {b.c: 1}")), config.WithSeparator("/"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	p, err := NewChaosProvider(base, Chaos{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.Get("a").Get("b.c").Value())
+	assert.Equal(t, config.Description{Name: base.Name() + " with chaos"}, config.Describe(p))
+}
+
+func TestChaosProviderLatency(t *testing.T) {
+	t.Parallel()
+
+	base, err := config.NewYAMLProviderFromBytes([]byte("a: 1"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	p, err := NewChaosProvider(base, Chaos{Latency: 10 * time.Millisecond})
+	require.NoError(t, err)
+
+	start := time.Now()
+	assert.Equal(t, 1, p.Get("a").Value())
+	assert.NoError(t, p.Update(base))
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+	assert.NoError(t, p.Healthy())
+}
+
+func TestChaosProviderErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewChaosProvider(nil, Chaos{})
+	assert.EqualError(t, err, "received a nil provider")
+
+	_, err = NewChaosProvider(config.NopProvider{}, Chaos{ErrorRate: 1.5})
+	assert.EqualError(t, err, "rates must be between 0 and 1")
+
+	p, err := NewChaosProvider(config.NopProvider{}, Chaos{ErrorRate: 1})
+	require.NoError(t, err)
+	assert.Equal(t, ErrChaos, p.Update(config.NopProvider{}))
+	assert.EqualError(t, p.Update(nil), "received a nil provider")
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"context"
+	"io"
+)
+
+// NewYAMLProviderFromFilesContext creates a configuration provider from a set
+// of YAML file names, like NewYAMLProviderFromFiles does. If ctx is done
+// before all the files are read, the context error is returned, so a hung
+// file system can't block the caller indefinitely.
+func NewYAMLProviderFromFilesContext(ctx context.Context, files ...string) (Provider, error) {
+	return withContext(ctx, func() (Provider, error) {
+		return NewYAMLProviderFromFiles(files...)
+	})
+}
+
+// NewYAMLProviderFromReaderContext creates a configuration provider from a
+// list of io.Readers, like NewYAMLProviderFromReader does. If ctx is done
+// before all the readers are consumed, the context error is returned.
+func NewYAMLProviderFromReaderContext(ctx context.Context, readers ...io.Reader) (Provider, error) {
+	return withContext(ctx, func() (Provider, error) {
+		return NewYAMLProviderFromReader(readers...)
+	})
+}
+
+// withContext runs the constructor in a separate goroutine and waits either
+// for its result or for the context to be done, whichever comes first.
+// The constructor keeps running in the background after the context is done,
+// because there is no way to interrupt a blocked read.
+func withContext(ctx context.Context, constructor func() (Provider, error)) (Provider, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		p   Provider
+		err error
+	}
+
+	ch := make(chan result, 1)
+	go func() {
+		p, err := constructor()
+		ch <- result{p: p, err: err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.p, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewYAMLProviderFromFilesContext(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromFilesContext(context.Background(), "./testdata/base.yaml")
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "base_only", p.Get("value").String())
+}
+
+func TestNewYAMLProviderFromFilesContextMissingFile(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewYAMLProviderFromFilesContext(context.Background(), "./testdata/missing.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing.yaml")
+}
+
+func TestNewYAMLProviderFromReaderContext(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromReaderContext(context.Background(), bytes.NewBufferString("a: b"))
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "b", p.Get("a").String())
+}
+
+func TestNewYAMLProviderFromReaderContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewYAMLProviderFromReaderContext(ctx, bytes.NewBufferString("a: b"))
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestNewYAMLProviderFromReaderContextTimeout(t *testing.T) {
+	t.Parallel()
+
+	r, w := io.Pipe()
+	defer w.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// Nobody writes to the pipe, so the read hangs until the timeout.
+	_, err := NewYAMLProviderFromReaderContext(ctx, r)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
@@ -26,17 +26,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"net/http"
+	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"gopkg.in/validator.v2"
 	"gopkg.in/yaml.v2"
 )
 
+var (
+	_typeOfURLValues  = reflect.TypeOf(url.Values{})
+	_typeOfHTTPHeader = reflect.TypeOf(http.Header{})
+)
+
+// _base64Encoding is a value of the encoding tag for base64 encoded fields.
+const _base64Encoding = "base64"
+
 type fieldInfo struct {
 	FieldName    string
 	DefaultValue string
+	Encoding     string
+	OneOf        []string
+	Min          string
+	Max          string
+	Fallback     []string
 	Required     bool
 }
 
@@ -44,7 +60,21 @@ func getFieldInfo(field reflect.StructField) fieldInfo {
 	return fieldInfo{
 		FieldName:    field.Tag.Get("yaml"),
 		DefaultValue: field.Tag.Get("default"),
+		Encoding:     field.Tag.Get("encoding"),
+		OneOf:        splitTag(field.Tag.Get("oneof")),
+		Min:          field.Tag.Get("min"),
+		Max:          field.Tag.Get("max"),
+		Fallback:     splitTag(field.Tag.Get("fallback")),
+	}
+}
+
+// splitTag splits a comma separated list of tag values.
+func splitTag(tag string) []string {
+	if tag == "" {
+		return nil
 	}
+
+	return strings.Split(tag, ",")
 }
 
 func derefType(t reflect.Type) reflect.Type {
@@ -223,9 +253,9 @@ func convert(childKey string, value *reflect.Value, val interface{}) error {
 	return nil
 }
 
-func addSeparator(key string) string {
+func (d *decoder) addSeparator(key string) string {
 	if key != "" {
-		key += _separator
+		key += separatorOf(d.getGlobalProvider())
 	}
 	return key
 }
@@ -236,7 +266,26 @@ func errorWithKey(err error, key string) error {
 
 type decoder struct {
 	*Value
-	m map[interface{}]struct{}
+	m       map[interface{}]struct{}
+	options populateOptions
+
+	// Out of range values are reported together after populating everything.
+	outOfRange []string
+
+	// Errors of fields populated with the BestEffort option.
+	failed []error
+
+	// The last key unmarshal was called for, to report panics.
+	key string
+}
+
+// rangeError returns an error listing all the values out of range, if any.
+func (d *decoder) rangeError() error {
+	if len(d.outOfRange) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("values out of range: %s", strings.Join(d.outOfRange, "; "))
 }
 
 func (d *decoder) getGlobalProvider() Provider {
@@ -255,6 +304,12 @@ func (d *decoder) scalar(childKey string, value reflect.Value, def string) error
 	// For primitive values, just get the value and set it into the field
 	if v2 := global.Get(childKey); v2.HasValue() {
 		val = v2.Value()
+		if val != nil {
+			var err error
+			if val, err = d.options.parseScalar(val, value.Type()); err != nil {
+				return errorWithKey(err, childKey)
+			}
+		}
 	} else if def != "" {
 		val = def
 	}
@@ -296,6 +351,13 @@ func (d *decoder) sequence(childKey string, value reflect.Value) error {
 	slice := global.Get(childKey)
 
 	s := slice.Value()
+
+	// YAML decodes !!binary values to strings, use their bytes for byte slices.
+	if str, ok := s.(string); ok && value.Type().Elem().Kind() == reflect.Uint8 {
+		value.SetBytes([]byte(str))
+		return nil
+	}
+
 	sv := reflect.ValueOf(s)
 	if err := checkCollections(sv.Kind(), value.Kind()); err != nil && slice.Value() != nil {
 		return err
@@ -306,7 +368,7 @@ func (d *decoder) sequence(childKey string, value reflect.Value) error {
 
 	// start looking for child values.
 	elementType := derefType(valueType).Elem()
-	childKey = addSeparator(childKey)
+	childKey = d.addSeparator(childKey)
 
 	for ai := 0; ; ai++ {
 		arrayKey := childKey + strconv.Itoa(ai)
@@ -363,7 +425,7 @@ func (d *decoder) array(childKey string, value reflect.Value) error {
 
 	// start looking for child values.
 	elementType := derefType(valueType).Elem()
-	childKey = addSeparator(childKey)
+	childKey = d.addSeparator(childKey)
 
 	for ai := 0; ai < value.Len(); ai++ {
 		arrayKey := childKey + strconv.Itoa(ai)
@@ -407,9 +469,13 @@ func (d *decoder) mapping(childKey string, value reflect.Value, def string) erro
 		return fmt.Errorf("expected map for key %q. actual type: %q", childKey, reflect.TypeOf(val))
 	}
 
+	if valueType == _typeOfURLValues || valueType == _typeOfHTTPHeader {
+		return d.stringLists(childKey, value, val)
+	}
+
 	destMap := reflect.ValueOf(reflect.MakeMap(valueType).Interface())
 
-	childKey = addSeparator(childKey)
+	childKey = d.addSeparator(childKey)
 
 	rVal := reflect.ValueOf(val)
 	for _, key := range rVal.MapKeys() {
@@ -439,6 +505,46 @@ func (d *decoder) mapping(childKey string, value reflect.Value, def string) erro
 	return nil
 }
 
+// stringLists sets url.Values and http.Header, which take lists of scalars or
+// single scalars for keys, e.g. {accept: application/json}. Keys of headers
+// are canonicalized.
+func (d *decoder) stringLists(childKey string, value reflect.Value, val interface{}) error {
+	global := d.getGlobalProvider()
+	dest := reflect.MakeMap(value.Type())
+	childKey = d.addSeparator(childKey)
+	for _, key := range reflect.ValueOf(val).MapKeys() {
+		name := fmt.Sprint(key.Interface())
+		itemKey := childKey + name
+		var list []string
+		switch item := global.Get(itemKey).Value().(type) {
+		case nil:
+			continue
+		case []interface{}:
+			for i, v := range item {
+				switch v.(type) {
+				case nil, map[interface{}]interface{}, []interface{}:
+					return errorWithKey(errors.New("expected a scalar"), d.addSeparator(itemKey)+strconv.Itoa(i))
+				}
+
+				list = append(list, fmt.Sprint(v))
+			}
+		case map[interface{}]interface{}:
+			return errorWithKey(errors.New("expected a scalar or a sequence of scalars"), itemKey)
+		default:
+			list = []string{fmt.Sprint(item)}
+		}
+
+		if value.Type() == _typeOfHTTPHeader {
+			name = http.CanonicalHeaderKey(name)
+		}
+
+		dest.SetMapIndex(reflect.ValueOf(name), reflect.ValueOf(list))
+	}
+
+	value.Set(dest)
+	return nil
+}
+
 // Sets value to an interface type.
 func (d *decoder) iface(key string, value reflect.Value, def string) error {
 	v := d.getGlobalProvider().Get(key)
@@ -447,6 +553,10 @@ func (d *decoder) iface(key string, value reflect.Value, def string) error {
 		return nil
 	}
 
+	if ok, err := d.registered(key, value, v.Value()); ok {
+		return err
+	}
+
 	src := reflect.ValueOf(v.Value())
 	if src.Type().Implements(value.Type()) {
 		value.Set(src)
@@ -477,27 +587,228 @@ func (d *decoder) valueStruct(key string, target interface{}) error {
 			continue
 		}
 
-		fieldName := field.Name
-		fieldInfo := getFieldInfo(field)
-		if fieldInfo.FieldName != "" {
-			fieldName = fieldInfo.FieldName
+		if err := d.field(key, field, tarGet.Field(i)); err != nil {
+			if !d.options.bestEffort {
+				return err
+			}
+
+			d.failed = append(d.failed, err)
 		}
+	}
+
+	err := validator.Validate(target)
+	if !d.options.bestEffort {
+		return errorWithKey(err, key)
+	}
 
-		if key != "" {
-			fieldName = key + _separator + fieldName
+	// Errors of nested structs are already reported, when they are populated.
+	if errs, ok := err.(validator.ErrorMap); ok {
+		for field := range errs {
+			if strings.ContainsAny(field, ".[") {
+				delete(errs, field)
+			}
 		}
 
-		fieldValue := tarGet.Field(i)
-		if fieldValue.Kind() == reflect.Ptr && fieldValue.IsNil() {
-			fieldValue.Set(reflect.New(fieldValue.Type()).Elem())
+		if len(errs) == 0 {
+			err = nil
 		}
+	}
+
+	if err != nil {
+		d.failed = append(d.failed, errorWithKey(err, key))
+	}
+
+	return nil
+}
+
+// field populates a field of a struct with the key.
+func (d *decoder) field(key string, field reflect.StructField, fieldValue reflect.Value) error {
+	fieldName := field.Name
+	fieldInfo := getFieldInfo(field)
+	if fieldInfo.FieldName != "" {
+		fieldName = fieldInfo.FieldName
+	}
 
-		if err := d.unmarshal(fieldName, fieldValue, getFieldInfo(field).DefaultValue); err != nil {
+	fieldName = d.addSeparator(key) + fieldName
+
+	// Fields are populated from the first of fallback keys with a value,
+	// if the field key has none.
+	if len(fieldInfo.Fallback) > 0 {
+		fieldName = GetFirst(d.getGlobalProvider(), append([]string{fieldName}, fieldInfo.Fallback...)...).key
+	}
+
+	if fieldValue.Kind() == reflect.Ptr && fieldValue.IsNil() {
+		fieldValue.Set(reflect.New(fieldValue.Type()).Elem())
+	}
+
+	if fieldInfo.Encoding != "" {
+		return d.encoded(fieldName, fieldValue, fieldInfo)
+	}
+
+	if err := d.unmarshal(fieldName, fieldValue, fieldInfo.DefaultValue); err != nil {
+		return err
+	}
+
+	if len(fieldInfo.OneOf) > 0 {
+		if err := checkEnum(fieldName, fieldValue, fieldInfo.OneOf); err != nil {
 			return err
 		}
 	}
 
-	return errorWithKey(validator.Validate(target), key)
+	if fieldInfo.Min != "" || fieldInfo.Max != "" {
+		return d.checkRange(fieldName, fieldValue, fieldInfo)
+	}
+
+	return nil
+}
+
+// Sets value of a byte slice field with an encoding tag, e.g.
+//
+// 	Key []byte `encoding:"base64"`
+//
+// to the decoded string value.
+func (d *decoder) encoded(key string, value reflect.Value, info fieldInfo) error {
+	if info.Encoding != _base64Encoding {
+		return errorWithKey(fmt.Errorf("unsupported encoding %q", info.Encoding), key)
+	}
+
+	if value.Kind() != reflect.Slice || value.Type().Elem().Kind() != reflect.Uint8 {
+		return errorWithKey(fmt.Errorf("can't decode %s into %q", info.Encoding, value.Type()), key)
+	}
+
+	v := d.getGlobalProvider().Get(key)
+	if shouldSkip(&v, info.DefaultValue) {
+		return nil
+	}
+
+	if !v.HasValue() {
+		v = NewValue(v.provider, key, info.DefaultValue, true)
+	}
+
+	b, err := v.AsBytesBase64()
+	if err != nil {
+		return err
+	}
+
+	value.SetBytes(b)
+	return nil
+}
+
+// Checks that a populated string field with a oneof tag, e.g.
+//
+// 	Level string `oneof:"debug,info,warn"`
+//
+// has one of the allowed values. Empty values are not checked.
+func checkEnum(key string, value reflect.Value, allowed []string) error {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.String {
+		return errorWithKey(fmt.Errorf("oneof tag is not supported for %q", value.Type()), key)
+	}
+
+	if value.String() == "" {
+		return nil
+	}
+
+	return errorWithKey(checkOneOf(value.String(), allowed), key)
+}
+
+// Checks that a populated numeric field with min or max tags, e.g.
+//
+// 	Port int `min:"1" max:"65535"`
+//
+// is in the range. Values out of range are collected to be reported together,
+// errors are returned only for invalid tags.
+func (d *decoder) checkRange(key string, value reflect.Value, info fieldInfo) error {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+	default:
+		return errorWithKey(fmt.Errorf("min and max tags are not supported for %q", value.Type()), key)
+	}
+
+	if info.Min != "" {
+		c, err := compareBound(value, info.Min)
+		if err != nil {
+			return errorWithKey(errors.Wrap(err, "invalid min tag"), key)
+		}
+
+		if c < 0 {
+			d.outOfRange = append(d.outOfRange, fmt.Sprintf("%q is %v, expected at least %s", key, value.Interface(), info.Min))
+		}
+	}
+
+	if info.Max != "" {
+		c, err := compareBound(value, info.Max)
+		if err != nil {
+			return errorWithKey(errors.Wrap(err, "invalid max tag"), key)
+		}
+
+		if c > 0 {
+			d.outOfRange = append(d.outOfRange, fmt.Sprintf("%q is %v, expected at most %s", key, value.Interface(), info.Max))
+		}
+	}
+
+	return nil
+}
+
+// compareBound returns -1, 0 or 1 if a number is less than, equal to or
+// greater than a bound of a tag. Integers are compared exactly with integer
+// bounds, floats are used for other bounds, e.g. 0.5.
+func compareBound(value reflect.Value, bound string) (int, error) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if b, err := strconv.ParseInt(bound, 10, 64); err == nil {
+			return compareNumbers(value.Int() < b, value.Int() > b), nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if b, err := strconv.ParseUint(bound, 10, 64); err == nil {
+			return compareNumbers(value.Uint() < b, value.Uint() > b), nil
+		}
+	}
+
+	b, err := strconv.ParseFloat(bound, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	var v float64
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v = float64(value.Uint())
+	default:
+		v = value.Float()
+	}
+
+	return compareNumbers(v < b, v > b), nil
+}
+
+func compareNumbers(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+
+	return 0
 }
 
 // If there is no value with name - leave it nil, otherwise allocate memory and set the value.
@@ -628,6 +939,7 @@ func (d *decoder) checkCycles(value reflect.Value) error {
 
 // Dispatch un-marshalling functions based on the value type.
 func (d *decoder) unmarshal(name string, value reflect.Value, def string) error {
+	d.key = name
 	if err := d.checkCycles(value); err != nil {
 		return errorWithKey(err, name)
 	}
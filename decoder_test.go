@@ -23,7 +23,10 @@ package config
 import (
 	"fmt"
 	"math"
+	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/google/gofuzz"
 	"github.com/stretchr/testify/assert"
@@ -551,3 +554,250 @@ func TestAddKeyToErrorReturnsNilForNilErrors(t *testing.T) {
 
 	assert.NoError(t, errorWithKey(nil, "key"))
 }
+
+func TestBinaryDecoding(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+cert: !!binary aGVsbG8=
+key: plain
+bytes: [1, 2]
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	type cert []byte
+	var c struct {
+		Cert  cert
+		Key   []byte
+		Bytes []byte
+		Empty []byte
+	}
+
+	require.NoError(t, p.Get(Root).Populate(&c))
+	assert.Equal(t, cert("hello"), c.Cert)
+	assert.Equal(t, []byte("plain"), c.Key)
+	assert.Equal(t, []byte{1, 2}, c.Bytes)
+	assert.Nil(t, c.Empty)
+}
+
+func TestBase64EncodedFields(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+key: aGVsbG8=
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var c struct {
+		Key     []byte `encoding:"base64"`
+		Default []byte `encoding:"base64" default:"d29ybGQ="`
+		Missing []byte `encoding:"base64"`
+	}
+
+	require.NoError(t, p.Get(Root).Populate(&c))
+	assert.Equal(t, []byte("hello"), c.Key)
+	assert.Equal(t, []byte("world"), c.Default)
+	assert.Nil(t, c.Missing)
+
+	var unsupported struct {
+		Key []byte `encoding:"hex"`
+	}
+
+	err = p.Get(Root).Populate(&unsupported)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported encoding "hex"`)
+
+	var notBytes struct {
+		Key string `encoding:"base64"`
+	}
+
+	err = p.Get(Root).Populate(&notBytes)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `can't decode base64 into "string"`)
+}
+
+func TestOneOfFields(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+level: info
+mode: fast
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	type mode string
+	var c struct {
+		Level   string  `oneof:"debug,info"`
+		Mode    *mode   `oneof:"fast,slow"`
+		Default string  `oneof:"a,b" default:"b"`
+		Missing string  `oneof:"a,b"`
+		Nil     *string `oneof:"a,b"`
+	}
+
+	require.NoError(t, p.Get(Root).Populate(&c))
+	assert.Equal(t, "info", c.Level)
+	assert.Equal(t, mode("fast"), *c.Mode)
+	assert.Equal(t, "b", c.Default)
+	assert.Empty(t, c.Missing)
+	assert.Nil(t, c.Nil)
+
+	var invalid struct {
+		Level string `oneof:"warn,error"`
+	}
+
+	err = p.Get(Root).Populate(&invalid)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `for key "Level": "info" is not one of: warn, error`)
+
+	var notString struct {
+		Count int `oneof:"1,2"`
+	}
+
+	err = p.Get(Root).Populate(&notString)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `oneof tag is not supported for "int"`)
+}
+
+func TestRangeFields(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+port: 8080
+percentage: 50.5
+servers:
+  - port: 0
+  - port: 70000
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var valid struct {
+		Port       int      `min:"1" max:"65535"`
+		Percentage *float64 `min:"0" max:"100"`
+		Nil        *int     `min:"1"`
+	}
+
+	require.NoError(t, p.Get(Root).Populate(&valid))
+	assert.Equal(t, 8080, valid.Port)
+	assert.Equal(t, 50.5, *valid.Percentage)
+
+	var invalid struct {
+		Port       int     `max:"1024"`
+		Percentage float64 `min:"60"`
+		Servers    []struct {
+			Port int `min:"1" max:"65535"`
+		}
+	}
+
+	err = p.Get(Root).Populate(&invalid)
+	require.Error(t, err)
+	assert.Equal(t, `values out of range: "Port" is 8080, expected at most 1024; `+
+		`"Percentage" is 50.5, expected at least 60; `+
+		`"Servers.0.Port" is 0, expected at least 1; `+
+		`"Servers.1.Port" is 70000, expected at most 65535`, err.Error())
+	assert.Equal(t, 70000, invalid.Servers[1].Port, "Values should be populated anyway")
+}
+
+func TestRangeFieldsLargeIntegers(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+signed: 9007199254740993
+unsigned: 18446744073709551615
+fraction: 1
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var c struct {
+		Signed   int64  `max:"9007199254740992"`
+		Unsigned uint64 `max:"18446744073709551614"`
+		Fraction int    `min:"0.5" max:"1.5"`
+	}
+
+	err = p.Get(Root).Populate(&c)
+	require.Error(t, err)
+	assert.Equal(t, `values out of range: "Signed" is 9007199254740993, expected at most 9007199254740992; `+
+		`"Unsigned" is 18446744073709551615, expected at most 18446744073709551614`, err.Error())
+}
+
+func TestRangeTagErrors(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`port: 1`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var badTag struct {
+		Port int `min:"one"`
+	}
+
+	err = p.Get(Root).Populate(&badTag)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid min tag")
+
+	var notNumber struct {
+		Port string `max:"1"`
+	}
+
+	err = p.Get(Root).Populate(&notNumber)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `min and max tags are not supported for "string"`)
+}
+
+func TestHTTPMaps(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+client:
+  query: {tags: [a, b], limit: 10, empty: ~}
+  headers: {content-type: application/json, x-request-id: [1, 2]}
+  timeouts: {read: 1s, write: 500ms}
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var client struct {
+		Query    url.Values
+		Headers  http.Header
+		Timeouts map[string]time.Duration
+	}
+
+	require.NoError(t, p.Get("client").Populate(&client))
+	assert.Equal(t, url.Values{"tags": {"a", "b"}, "limit": {"10"}}, client.Query)
+	assert.Equal(t, http.Header{"Content-Type": {"application/json"}, "X-Request-Id": {"1", "2"}}, client.Headers)
+	assert.Equal(t, map[string]time.Duration{"read": time.Second, "write": 500 * time.Millisecond}, client.Timeouts)
+
+	for src, msg := range map[string]string{
+		"query: {a: {b: c}}": `for key "query.a": expected a scalar or a sequence of scalars`,
+		"query: {a: [[b]]}":  `for key "query.a.0": expected a scalar`,
+	} {
+		p, err := NewYAMLProviderFromBytes([]byte(src))
+		require.NoError(t, err, "Can't create a YAML provider")
+
+		var q url.Values
+		err = p.Get("query").Populate(&q)
+		require.Error(t, err, "Expected an error for %q", src)
+		assert.Contains(t, err.Error(), msg)
+	}
+}
+
+func TestFallbackFields(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+legacy:
+  db_host: old
+  db_port: 5432
+storage:
+  host: new
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var storage struct {
+		Host string `yaml:"host" fallback:"legacy.db_host"`
+		Port int    `yaml:"port" fallback:"legacy.port,legacy.db_port"`
+		User string `yaml:"user" fallback:"legacy.user" default:"admin"`
+	}
+
+	require.NoError(t, p.Get("storage").Populate(&storage))
+	assert.Equal(t, "new", storage.Host)
+	assert.Equal(t, 5432, storage.Port)
+	assert.Equal(t, "admin", storage.User)
+}
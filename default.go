@@ -0,0 +1,96 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"sync"
+)
+
+// The default provider used by package level functions, for small programs
+// that don't want to pass a provider around.
+var _default struct {
+	sync.RWMutex
+	provider Provider
+}
+
+// Load replaces the default provider with a YAML provider created from
+// the files, like NewYAMLProviderFromFiles does. The default provider is not
+// changed on errors.
+func Load(files ...string) error {
+	p, err := NewYAMLProviderFromFiles(files...)
+	if err != nil {
+		return err
+	}
+
+	return SetDefault(p)
+}
+
+// MustLoad is like Load, but panics on errors. It simplifies loading
+// configuration in main functions.
+func MustLoad(files ...string) {
+	if err := Load(files...); err != nil {
+		panic(err)
+	}
+}
+
+// SetDefault replaces the default provider, e.g. with a provider group.
+func SetDefault(p Provider) error {
+	if p == nil {
+		return errors.New("received a nil provider")
+	}
+
+	_default.Lock()
+	_default.provider = p
+	_default.Unlock()
+	return nil
+}
+
+// Default returns the default provider. It is safe to call concurrently with
+// Load and SetDefault.
+func Default() Provider {
+	_default.RLock()
+	defer _default.RUnlock()
+
+	if _default.provider == nil {
+		return defaultNopProvider{}
+	}
+
+	return _default.provider
+}
+
+// Get returns a value from the default provider. Values are missing until
+// the default provider is loaded.
+func Get(key string) Value {
+	return Default().Get(key)
+}
+
+// defaultNopProvider is used until the default provider is set. Unlike
+// NopProvider it doesn't have values.
+type defaultNopProvider struct{}
+
+func (defaultNopProvider) Name() string {
+	return "default"
+}
+
+func (p defaultNopProvider) Get(key string) Value {
+	return NewValue(p, key, nil, false)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests of the default provider are not parallel, because they share it.
+func resetDefault() {
+	_default.Lock()
+	_default.provider = nil
+	_default.Unlock()
+}
+
+func TestDefaultProvider(t *testing.T) {
+	defer resetDefault()
+	resetDefault()
+
+	assert.Equal(t, "default", Default().Name())
+	assert.False(t, Get("value").HasValue())
+
+	require.NoError(t, Load("./testdata/base.yaml"))
+	assert.Equal(t, "base_only", Get("value").Value())
+
+	err := Load("./testdata/missing.yaml")
+	require.Error(t, err)
+	assert.Equal(t, "base_only", Get("value").Value(), "Default provider shouldn't change on errors")
+
+	assert.Panics(t, func() { MustLoad("./testdata/missing.yaml") })
+	MustLoad("./testdata/base.yaml", "./testdata/dev.yaml")
+	assert.Equal(t, "base_only", Get("value").Value())
+
+	s, err := NewStaticProvider(map[string]string{"value": "static"})
+	require.NoError(t, err, "Can't create a static provider")
+	require.NoError(t, SetDefault(s))
+	assert.Equal(t, "static", Get("value").Value())
+
+	err = SetDefault(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "received a nil provider")
+}
+
+func TestDefaultProviderConcurrentAccess(t *testing.T) {
+	defer resetDefault()
+	resetDefault()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, Load("./testdata/base.yaml"))
+		}()
+		go func() {
+			defer wg.Done()
+			Get("value")
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, "base_only", Get("value").Value())
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Description identifies a provider, e.g. one of several YAML providers in
+// a group.
+type Description struct {
+	// Name is a short name of the provider, e.g. "base" or "secrets".
+	Name string
+
+	// Description describes sources of the provider, e.g. files or a URL of
+	// a remote store.
+	Description string
+
+	// Priority is a position of the provider in a group, providers with
+	// higher priorities override the others. It is zero outside of groups.
+	Priority int
+}
+
+func (d Description) String() string {
+	if d.Description == "" {
+		return d.Name
+	}
+
+	return fmt.Sprintf("%s (%s)", d.Name, d.Description)
+}
+
+// Describer is implemented by providers that describe themselves beyond
+// their names.
+type Describer interface {
+	Describe() Description
+}
+
+// Describe returns a description of a provider. Providers that don't
+// implement the Describer interface are described by their names.
+func Describe(p Provider) Description {
+	if d, ok := p.(Describer); ok {
+		return d.Describe()
+	}
+
+	return Description{Name: p.Name()}
+}
+
+// NewDescribedProvider gives a provider a name and a description, which its
+// values report in their metadata and groups report in their layers, e.g.
+//
+// 	base, err := config.NewDescribedProvider(yaml, "base", "base.yaml, production.yaml")
+func NewDescribedProvider(p Provider, name, description string) (Provider, error) {
+	if p == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	if name == "" {
+		return nil, errors.New("received an empty name")
+	}
+
+	return describedProvider{Provider: p, name: name, description: description}, nil
+}
+
+type describedProvider struct {
+	Provider
+
+	name        string
+	description string
+}
+
+func (p describedProvider) Name() string {
+	return p.name
+}
+
+func (p describedProvider) Get(key string) Value {
+	v := p.Provider.Get(key)
+
+	// Report metadata of the described provider for children too.
+	v.provider = p
+	v.root = nil
+	return v
+}
+
+func (p describedProvider) Describe() Description {
+	return Description{Name: p.name, Description: p.description}
+}
+
+// Metadata reports the name and the description as the source of values.
+func (p describedProvider) Metadata(key string) Metadata {
+	m := metadataOf(p.Provider, key)
+	m.Source = p.name
+	m.Description = p.description
+	return m
+}
+
+func (p describedProvider) separator() string {
+	return separatorOf(p.Provider)
+}
+
+// Healthy returns health of the underlying provider.
+func (p describedProvider) Healthy() error {
+	return Healthy(p.Provider)
+}
+
+func (p describedProvider) keyOrder() *keyOrder {
+	return keyOrderOf(p.Provider)
+}
+
+// Layers returns descriptions of providers of a group from the lowest
+// priority to the highest one, or the description of a provider if it
+// isn't a group.
+func Layers(p Provider) []Description {
+	g, ok := p.(providerGroup)
+	if !ok {
+		return []Description{Describe(p)}
+	}
+
+	layers := make([]Description, len(g.providers))
+	for i, provider := range g.providers {
+		layers[i] = Describe(provider)
+		layers[i].Priority = i
+	}
+
+	return layers
+}
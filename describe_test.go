@@ -0,0 +1,70 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribedProvider(t *testing.T) {
+	t.Parallel()
+
+	base, err := NewYAML(File("testdata/fs/base.yaml"), WithSeparator("/"))
+	require.NoError(t, err, "Can't create a YAML provider")
+	secrets, err := NewYAMLProviderFromReaderWithSeparator("/", bytes.NewBufferString("b: secret"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	described, err := NewDescribedProvider(base, "base", "testdata/fs/base.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "base", described.Name())
+	assert.Equal(t, "/", separatorOf(described))
+	assert.Equal(t, Description{Name: "base", Description: "testdata/fs/base.yaml"}, Describe(described))
+
+	m := described.Get("b").Metadata()
+	assert.Equal(t, "base", m.Source)
+	assert.Equal(t, "testdata/fs/base.yaml", m.Description)
+	assert.NotEmpty(t, m.File)
+
+	pg, err := NewProviderGroup("group", described, secrets)
+	require.NoError(t, err)
+	assert.Equal(t, []Description{
+		{Name: "base", Description: "testdata/fs/base.yaml"},
+		{Name: secrets.Name(), Priority: 1},
+	}, Layers(pg))
+	assert.Equal(t, []Description{{Name: secrets.Name()}}, Layers(secrets))
+	assert.Equal(t, "base", pg.Get("a").Metadata().Source)
+
+	var buf bytes.Buffer
+	require.NoError(t, Dump(&buf, pg, WithLayers()))
+	assert.Contains(t, buf.String(), "# Layers, later ones override earlier ones:\n"+
+		"#   0: base (testdata/fs/base.yaml)\n"+
+		"#   1: "+secrets.Name()+"\n")
+	assert.Contains(t, buf.String(), "b: secret\n")
+
+	_, err = NewDescribedProvider(nil, "base", "")
+	assert.EqualError(t, err, "received a nil provider")
+	_, err = NewDescribedProvider(base, "", "")
+	assert.EqualError(t, err, "received an empty name")
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const _appConfigFile = "config.yaml"
+
+// AppConfigPaths returns files NewYAMLProviderForApp looks for, from the
+// lowest priority to the highest:
+//
+// 	/etc/<app>/config.yaml             - system wide configuration.
+// 	$XDG_CONFIG_HOME/<app>/config.yaml - user configuration, ~/.config is
+// 	                                     used if XDG_CONFIG_HOME is not set.
+// 	./<app>.yaml                       - project configuration in the
+// 	                                     working directory.
+//
+// On Windows system wide and user configuration is in
+// %ProgramData%\<app>\config.yaml and %APPDATA%\<app>\config.yaml.
+func AppConfigPaths(app string) []string {
+	return appConfigPaths(app, os.Getenv, runtime.GOOS)
+}
+
+func appConfigPaths(app string, getenv func(string) string, goos string) []string {
+	if goos == "windows" {
+		var paths []string
+		for _, v := range []string{"ProgramData", "APPDATA"} {
+			if dir := getenv(v); dir != "" {
+				paths = append(paths, filepath.Join(dir, app, _appConfigFile))
+			}
+		}
+
+		return append(paths, app+".yaml")
+	}
+
+	paths := []string{filepath.Join("/etc", app, _appConfigFile)}
+
+	if dir := getenv("XDG_CONFIG_HOME"); dir != "" {
+		paths = append(paths, filepath.Join(dir, app, _appConfigFile))
+	} else if home := getenv("HOME"); home != "" {
+		paths = append(paths, filepath.Join(home, ".config", app, _appConfigFile))
+	}
+
+	return append(paths, app+".yaml")
+}
+
+// NewYAMLProviderForApp creates a configuration provider for a command line
+// tool from the files it finds in conventional locations, see
+// AppConfigPaths. Files are merged in the order of their priority and
+// missing files are skipped.
+func NewYAMLProviderForApp(app string) (Provider, error) {
+	return NewYAML(App(app))
+}
+
+// App adds the YAML files of a command line tool in conventional locations
+// to read configuration from, see NewYAMLProviderForApp.
+func App(app string) YAMLOption {
+	return func(o *yamlOptions) {
+		if app == "" {
+			if o.err == nil {
+				o.err = errors.New("empty application name")
+			}
+
+			return
+		}
+
+		o.sources = append(o.sources, appSources(AppConfigPaths(app))...)
+	}
+}
+
+// appSources returns sources of the files, that are skipped if they are
+// missing.
+func appSources(paths []string) []yamlSource {
+	sources := make([]yamlSource, len(paths))
+	for i, file := range paths {
+		sources[i] = yamlSource{file: file, optional: true, quiet: true}
+	}
+
+	return sources
+}
+
+// ExpandPath replaces a leading ~ in a path with the home directory of the
+// user and, on Windows, %VAR% sequences with values of environment
+// variables, e.g. %APPDATA%\tool\certs. Unknown variables are kept as is,
+// like the Windows shell does.
+func ExpandPath(path string) string {
+	return expandPath(path, os.Getenv, runtime.GOOS)
+}
+
+func expandPath(path string, getenv func(string) string, goos string) string {
+	if goos == "windows" {
+		path = expandWindowsVars(path, getenv)
+	}
+
+	if path != "~" && !strings.HasPrefix(path, "~/") &&
+		!(goos == "windows" && strings.HasPrefix(path, `~\`)) {
+		return path
+	}
+
+	home := getenv("HOME")
+	if goos == "windows" {
+		home = getenv("USERPROFILE")
+	}
+
+	if home == "" {
+		return path
+	}
+
+	return home + path[1:]
+}
+
+// expandWindowsVars replaces %VAR% sequences with values of environment
+// variables.
+func expandWindowsVars(path string, getenv func(string) string) string {
+	var res []string
+	for {
+		start := strings.IndexByte(path, '%')
+		if start < 0 {
+			break
+		}
+
+		end := strings.IndexByte(path[start+1:], '%')
+		if end < 0 {
+			break
+		}
+
+		end += start + 1
+		name := path[start+1 : end]
+		if v := getenv(name); name != "" && v != "" {
+			res = append(res, path[:start], v)
+			path = path[end+1:]
+			continue
+		}
+
+		// Keep unknown variables and look for the next one after them.
+		res = append(res, path[:end])
+		path = path[end:]
+	}
+
+	return strings.Join(append(res, path), "")
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppConfigPaths(t *testing.T) {
+	t.Parallel()
+
+	env := func(m map[string]string) func(string) string {
+		return func(key string) string { return m[key] }
+	}
+
+	assert.Equal(t, []string{
+		filepath.Join("/etc", "tool", "config.yaml"),
+		filepath.Join("/xdg", "tool", "config.yaml"),
+		"tool.yaml",
+	}, appConfigPaths("tool", env(map[string]string{"XDG_CONFIG_HOME": "/xdg", "HOME": "/home/user"}), "linux"))
+
+	assert.Equal(t, []string{
+		filepath.Join("/etc", "tool", "config.yaml"),
+		filepath.Join("/home/user", ".config", "tool", "config.yaml"),
+		"tool.yaml",
+	}, appConfigPaths("tool", env(map[string]string{"HOME": "/home/user"}), "darwin"))
+
+	assert.Equal(t, []string{
+		filepath.Join("/etc", "tool", "config.yaml"),
+		"tool.yaml",
+	}, appConfigPaths("tool", env(nil), "linux"))
+
+	assert.Equal(t, []string{
+		filepath.Join(`C:\ProgramData`, "tool", "config.yaml"),
+		filepath.Join(`C:\Users\user\AppData\Roaming`, "tool", "config.yaml"),
+		"tool.yaml",
+	}, appConfigPaths("tool", env(map[string]string{
+		"ProgramData": `C:\ProgramData`,
+		"APPDATA":     `C:\Users\user\AppData\Roaming`,
+	}), "windows"))
+}
+
+func TestExpandPath(t *testing.T) {
+	t.Parallel()
+
+	getenv := func(key string) string {
+		return map[string]string{
+			"HOME":        "/home/user",
+			"USERPROFILE": `C:\Users\user`,
+			"APPDATA":     `C:\Users\user\AppData\Roaming`,
+		}[key]
+	}
+
+	tests := []struct {
+		path     string
+		goos     string
+		expected string
+	}{
+		{"~", "linux", "/home/user"},
+		{"~/certs/a.pem", "linux", "/home/user/certs/a.pem"},
+		{"~user/a", "linux", "~user/a"},
+		{"a/~/b", "linux", "a/~/b"},
+		{"%APPDATA%/a", "linux", "%APPDATA%/a"},
+		{`~\certs`, "linux", `~\certs`},
+		{`~\certs`, "windows", `C:\Users\user\certs`},
+		{`%APPDATA%\tool\a.pem`, "windows", `C:\Users\user\AppData\Roaming\tool\a.pem`},
+		{`%MISSING%\%APPDATA%`, "windows", `%MISSING%\C:\Users\user\AppData\Roaming`},
+		{`100%`, "windows", `100%`},
+		{`%%`, "windows", `%%`},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, expandPath(tt.path, getenv, tt.goos), "Unexpected expansion of %q on %s", tt.path, tt.goos)
+	}
+
+	assert.Equal(t, "~/a", expandPath("~/a", func(string) string { return "" }, "linux"))
+}
+
+func TestNewYAMLProviderFromPaths(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestNewYAMLProviderFromPaths")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	system := filepath.Join(dir, "system.yaml")
+	require.NoError(t, ioutil.WriteFile(system, []byte("a: system\nb: system"), os.ModePerm))
+
+	project := filepath.Join(dir, "project.yaml")
+	require.NoError(t, ioutil.WriteFile(project, []byte("b: project"), os.ModePerm))
+
+	p, err := loadYAMLSources(yamlSettings{}, appSources([]string{system, filepath.Join(dir, "missing.yaml"), project}))
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "system", p.Get("a").Value())
+	assert.Equal(t, "project", p.Get("b").Value())
+
+	_, err = NewYAMLProviderForApp("")
+	assert.Error(t, err)
+
+	p, err = NewYAML(App("TestNewYAMLProviderFromPaths"), Source(bytes.NewBufferString("a: source")))
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "source", p.Get("a").Value())
+
+	p, err = NewYAMLProviderForApp("TestNewYAMLProviderFromPaths")
+	require.NoError(t, err, "Missing files should be skipped")
+	assert.Nil(t, p.Get(Root).Value())
+}
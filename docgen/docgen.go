@@ -0,0 +1,262 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package docgen documents configuration keys of configuration structs,
+// so documentation doesn't drift from the code, e.g.
+//
+// 	type HTTP struct {
+// 		Port int `yaml:"port" default:"8080" min:"1" max:"65535" doc:"Port to listen on."`
+// 	}
+//
+// 	docgen.Markdown(os.Stdout, docgen.Section{Key: "http", Value: HTTP{}})
+//
+// writes a table row for the http.port key with its type, default value and
+// description. Descriptions come from doc tags, because Go doesn't keep
+// comments at runtime. Keys of sequence elements and map values contain
+// the * wildcard, e.g. servers.*.host.
+package docgen // import "go.uber.org/config/docgen"
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// A Section is a configuration struct populated from a key.
+type Section struct {
+	// Key the struct is populated from, config.Root for the whole
+	// configuration.
+	Key string
+
+	// Value is a struct or a pointer to a struct.
+	Value interface{}
+}
+
+// A Field describes a configuration key.
+type Field struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Fields returns descriptions of all the keys of the sections' structs with
+// scalar values, in order of the struct fields.
+func Fields(sections ...Section) []Field {
+	var fields []Field
+	for _, s := range sections {
+		t := reflect.TypeOf(s.Value)
+		if t == nil {
+			continue
+		}
+
+		fields = walk(fields, s.Key, t, map[reflect.Type]bool{})
+	}
+
+	return fields
+}
+
+// walk appends fields of a type under the key.
+func walk(fields []Field, key string, t reflect.Type, seen map[reflect.Type]bool) []Field {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if elem := deref(t.Elem()); elem.Kind() == reflect.Struct && !isScalar(elem) {
+			return walk(fields, join(key, "*"), elem, seen)
+		}
+	case reflect.Struct:
+		if isScalar(t) || seen[t] {
+			break
+		}
+
+		// Types are tracked on the current path only to stop on recursive types.
+		seen[t] = true
+		defer delete(seen, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+
+			// Decoder skips private and embedded fields.
+			if f.PkgPath != "" || f.Anonymous {
+				continue
+			}
+
+			name := f.Name
+			if tag := f.Tag.Get("yaml"); tag != "" {
+				name = tag
+			}
+
+			childKey := join(key, name)
+			if ft := deref(f.Type); ft.Kind() == reflect.Struct && !isScalar(ft) ||
+				isCollectionOfStructs(ft) {
+				fields = walk(fields, childKey, f.Type, seen)
+				continue
+			}
+
+			fields = append(fields, Field{
+				Key:         childKey,
+				Type:        f.Type.String(),
+				Default:     f.Tag.Get("default"),
+				Required:    isRequired(f.Tag.Get("validate")),
+				Description: describe(f.Tag),
+			})
+		}
+
+		return fields
+	}
+
+	return append(fields, Field{Key: key, Type: t.String()})
+}
+
+func deref(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+func isCollectionOfStructs(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		elem := deref(t.Elem())
+		return elem.Kind() == reflect.Struct && !isScalar(elem)
+	}
+
+	return false
+}
+
+// isScalar returns true for structs populated from scalars, e.g. time.Time.
+func isScalar(t reflect.Type) bool {
+	for _, m := range []string{"UnmarshalText", "UnmarshalJSON", "UnmarshalYAML"} {
+		if _, ok := reflect.PtrTo(t).MethodByName(m); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isRequired checks validator tags for the nonzero rule.
+func isRequired(tag string) bool {
+	for _, rule := range strings.Split(tag, ",") {
+		if rule == "nonzero" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// describe returns the doc tag with descriptions of constraints appended.
+func describe(tag reflect.StructTag) string {
+	parts := []string{}
+	if doc := tag.Get("doc"); doc != "" {
+		parts = append(parts, doc)
+	}
+
+	if oneof := tag.Get("oneof"); oneof != "" {
+		parts = append(parts, fmt.Sprintf("One of: %s.", strings.Replace(oneof, ",", ", ", -1)))
+	}
+
+	min, max := tag.Get("min"), tag.Get("max")
+	switch {
+	case min != "" && max != "":
+		parts = append(parts, fmt.Sprintf("From %s to %s.", min, max))
+	case min != "":
+		parts = append(parts, fmt.Sprintf("At least %s.", min))
+	case max != "":
+		parts = append(parts, fmt.Sprintf("At most %s.", max))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func join(key, child string) string {
+	if key == "" {
+		return child
+	}
+
+	return key + "." + child
+}
+
+// Keys returns keys of the fields of sections' structs, e.g. to complete
+// overrides in command line tools.
+func Keys(sections ...Section) []string {
+	fields := Fields(sections...)
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.Key
+	}
+
+	return keys
+}
+
+// JSON writes descriptions of all the keys of the sections as a JSON array
+// for editors and other tools.
+func JSON(w io.Writer, sections ...Section) error {
+	fields := Fields(sections...)
+	if fields == nil {
+		fields = []Field{}
+	}
+
+	return json.NewEncoder(w).Encode(fields)
+}
+
+// Markdown writes a table documenting all the keys of the sections.
+func Markdown(w io.Writer, sections ...Section) error {
+	lines := []string{
+		"| Key | Type | Default | Required | Description |",
+		"| --- | --- | --- | --- | --- |",
+	}
+
+	for _, f := range Fields(sections...) {
+		required := ""
+		if f.Required {
+			required = "yes"
+		}
+
+		lines = append(lines, fmt.Sprintf("| `%s` | `%s` | %s | %s | %s |",
+			f.Key, f.Type, code(f.Default), required, escape(f.Description)))
+	}
+
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// code formats a non empty value as inline code.
+func code(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	return "`" + escape(s) + "`"
+}
+
+// escape escapes pipes, that separate table cells.
+func escape(s string) string {
+	return strings.Replace(s, "|", `\|`, -1)
+}
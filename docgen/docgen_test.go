@@ -0,0 +1,111 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package docgen
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type server struct {
+	Host    string        `yaml:"host" validate:"nonzero" doc:"Host name."`
+	Timeout time.Duration `yaml:"timeout" default:"1s"`
+}
+
+type node struct {
+	Name     string
+	Children []node
+}
+
+type service struct {
+	Name    string `yaml:"name" validate:"min=1,nonzero"`
+	Level   string `yaml:"level" default:"info" oneof:"debug,info" doc:"Log level."`
+	Port    int    `yaml:"port" min:"1" max:"65535" doc:"Port | listener."`
+	Servers []server
+	Primary *server           `yaml:"primary"`
+	Labels  map[string]string `yaml:"labels"`
+	Start   time.Time         `yaml:"start" min:"0"`
+	Root    node              `yaml:"root"`
+
+	server
+	private int
+}
+
+func TestFields(t *testing.T) {
+	t.Parallel()
+
+	fields := Fields(Section{Key: "svc", Value: &service{}}, Section{Value: nil}, Section{Key: "n", Value: 1})
+	assert.Equal(t, []Field{
+		{Key: "svc.name", Type: "string", Required: true},
+		{Key: "svc.level", Type: "string", Default: "info", Description: "Log level. One of: debug, info."},
+		{Key: "svc.port", Type: "int", Description: "Port | listener. From 1 to 65535."},
+		{Key: "svc.Servers.*.host", Type: "string", Required: true, Description: "Host name."},
+		{Key: "svc.Servers.*.timeout", Type: "time.Duration", Default: "1s"},
+		{Key: "svc.primary.host", Type: "string", Required: true, Description: "Host name."},
+		{Key: "svc.primary.timeout", Type: "time.Duration", Default: "1s"},
+		{Key: "svc.labels", Type: "map[string]string"},
+		{Key: "svc.start", Type: "time.Time", Description: "At least 0."},
+		{Key: "svc.root.Name", Type: "string"},
+		{Key: "svc.root.Children.*", Type: "docgen.node"},
+		{Key: "n", Type: "int"},
+	}, fields)
+}
+
+func TestMarkdown(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, Markdown(&buf, Section{Value: server{}}))
+	assert.Equal(t, "| Key | Type | Default | Required | Description |\n"+
+		"| --- | --- | --- | --- | --- |\n"+
+		"| `host` | `string` |  | yes | Host name. |\n"+
+		"| `timeout` | `time.Duration` | `1s` |  |  |\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, Markdown(&buf, Section{Value: service{}}))
+	assert.Contains(t, buf.String(), `| Port \| listener. From 1 to 65535. |`)
+}
+
+func TestKeys(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"s.host", "s.timeout"}, Keys(Section{Key: "s", Value: server{}}))
+	assert.Empty(t, Keys())
+}
+
+func TestJSON(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, JSON(&buf, Section{Value: server{}}))
+	assert.JSONEq(t, `[
+		{"key": "host", "type": "string", "required": true, "description": "Host name."},
+		{"key": "timeout", "type": "time.Duration", "default": "1s"}
+	]`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, JSON(&buf))
+	assert.Equal(t, "[]\n", buf.String())
+}
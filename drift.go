@@ -0,0 +1,182 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Drift describes configuration of the sources, that differs from the
+// running configuration.
+type Drift struct {
+	// Running and Sources are checksums of the running configuration and of
+	// the configuration of the sources.
+	Running string
+	Sources string
+
+	// Keys are sorted keys of scalars, empty collections and nulls, that
+	// differ.
+	Keys []string
+}
+
+// A DriftDetector reports when configuration on disk was changed, but the
+// running configuration wasn't reloaded, e.g.
+//
+// 	d := config.DriftDetector{
+// 		Load: func() (config.Provider, error) {
+// 			return config.NewYAML(config.File("base.yaml"), config.File("production.yaml"))
+// 		},
+// 		Interval: time.Minute,
+// 		Logger:   logger,
+// 		OnDrift:  func(config.Drift) { driftCounter.Inc() },
+// 	}
+//
+// 	stop := d.Watch(p)
+// 	defer stop()
+type DriftDetector struct {
+	// Load re-reads the original sources of the running configuration.
+	Load func() (Provider, error)
+
+	// Interval between checks, a minute if it is zero.
+	Interval time.Duration
+
+	// Logger receives a warning when drift is detected, it is optional.
+	Logger Logger
+
+	// OnDrift is called when drift is detected, e.g. to emit a metric. It is
+	// optional.
+	OnDrift func(Drift)
+
+	// OnError is called with errors of loading the sources, it is optional.
+	OnError func(error)
+}
+
+// Check loads the sources and compares them with the running
+// configuration. It returns nil if they match.
+func (d DriftDetector) Check(running Provider) (*Drift, error) {
+	if running == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	if d.Load == nil {
+		return nil, errors.New("received a nil load function")
+	}
+
+	sources, err := d.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	runningSum, err := snapshotChecksum(running.Get(Root).Value())
+	if err != nil {
+		return nil, err
+	}
+
+	sourcesSum, err := snapshotChecksum(sources.Get(Root).Value())
+	if err != nil {
+		return nil, err
+	}
+
+	if runningSum == sourcesSum {
+		return nil, nil
+	}
+
+	sep := separatorOf(running)
+	before := make(map[string]interface{})
+	flatten(before, Root, running.Get(Root).Value(), sep)
+	after := make(map[string]interface{})
+	flatten(after, Root, sources.Get(Root).Value(), sep)
+
+	var keys []string
+	for key, v := range after {
+		if o, ok := before[key]; !ok || !reflect.DeepEqual(o, v) {
+			keys = append(keys, key)
+		}
+	}
+
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return &Drift{Running: runningSum, Sources: sourcesSum, Keys: keys}, nil
+}
+
+// Watch calls Check with the interval until the returned function is
+// called. Drift is reported once for every new configuration of the
+// sources. After the running configuration is reloaded, stop watching it
+// and watch the new one.
+func (d DriftDetector) Watch(running Provider) (stop func()) {
+	interval := d.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	logger := loggerOrNop(d.Logger)
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+
+		var reported string
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			drift, err := d.Check(running)
+			if err != nil {
+				if d.OnError != nil {
+					d.OnError(err)
+				}
+
+				continue
+			}
+
+			if drift == nil {
+				reported = ""
+				continue
+			}
+
+			if drift.Sources == reported {
+				continue
+			}
+
+			reported = drift.Sources
+			logger.Warnf("config: sources have drifted from the running configuration (%s, running %s), keys: %v",
+				drift.Sources, drift.Running, drift.Keys)
+			if d.OnDrift != nil {
+				d.OnDrift(*drift)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
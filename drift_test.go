@@ -0,0 +1,107 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriftDetectorCheck(t *testing.T) {
+	t.Parallel()
+
+	running, err := NewYAMLProviderFromBytes([]byte("a: 1\nb: {c: 2}"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var source []byte
+	d := DriftDetector{Load: func() (Provider, error) {
+		return NewYAMLProviderFromBytes(source)
+	}}
+
+	source = []byte("b: {c: 2}\na: 1")
+	drift, err := d.Check(running)
+	require.NoError(t, err)
+	assert.Nil(t, drift)
+
+	source = []byte("b: {c: 3, d: 4}")
+	drift, err = d.Check(running)
+	require.NoError(t, err)
+	require.NotNil(t, drift)
+	assert.Equal(t, []string{"a", "b.c", "b.d"}, drift.Keys)
+	assert.NotEqual(t, drift.Running, drift.Sources)
+
+	d.Load = func() (Provider, error) { return nil, errors.New("no sources") }
+	_, err = d.Check(running)
+	assert.EqualError(t, err, "no sources")
+
+	_, err = DriftDetector{}.Check(running)
+	assert.EqualError(t, err, "received a nil load function")
+}
+
+func TestDriftDetectorWatch(t *testing.T) {
+	t.Parallel()
+
+	running, err := NewYAMLProviderFromBytes([]byte("a: 1"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var buf lockedBuffer
+	drifts := make(chan Drift, 10)
+	d := DriftDetector{
+		Load: func() (Provider, error) {
+			return NewYAMLProviderFromBytes([]byte("a: 2"))
+		},
+		Interval: time.Millisecond,
+		Logger:   StdLogger(log.New(&buf, "", 0)),
+		OnDrift:  func(d Drift) { drifts <- d },
+	}
+
+	stop := d.Watch(running)
+	drift := <-drifts
+	time.Sleep(10 * time.Millisecond)
+	stop()
+	stop()
+
+	assert.Equal(t, []string{"a"}, drift.Keys)
+	assert.Empty(t, drifts, "Drift should be reported once")
+
+	buf.Lock()
+	defer buf.Unlock()
+	assert.Contains(t, buf.String(), "WARN config: sources have drifted from the running configuration")
+}
+
+// lockedBuffer is a buffer safe for concurrent writes.
+type lockedBuffer struct {
+	sync.Mutex
+	bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// A DumpOption configures Dump.
+type DumpOption func(*dumpOptions)
+
+type dumpOptions struct {
+	normalize bool
+	encrypter Encrypter
+	encrypted []string
+	layers    bool
+}
+
+// WithNormalizedUnits writes durations and sizes in canonical forms, see
+// NormalizeUnits.
+func WithNormalizedUnits() DumpOption {
+	return func(o *dumpOptions) {
+		o.normalize = true
+	}
+}
+
+// WithLayers writes providers of a group with their priorities in a comment
+// before the configuration, see Layers.
+func WithLayers() DumpOption {
+	return func(o *dumpOptions) {
+		o.layers = true
+	}
+}
+
+// Dump writes the whole merged configuration of a provider as YAML, e.g. to
+// diff configurations of environments. Keys of mappings are sorted, unless
+// the provider keeps their order, see WithKeyOrder.
+func Dump(w io.Writer, p Provider, options ...DumpOption) error {
+	var o dumpOptions
+	for _, option := range options {
+		option(&o)
+	}
+
+	value := p.Get(Root).Value()
+	if o.normalize {
+		value = NormalizeUnits(value)
+	}
+
+	if o.encrypter != nil {
+		var err error
+		if value, err = encryptKeys(value, o.encrypter, o.encrypted, separatorOf(p)); err != nil {
+			return errors.Wrap(err, "can't encrypt configuration")
+		}
+	}
+
+	if order := keyOrderOf(p); order != nil {
+		value = order.ordered(value)
+	}
+
+	b, err := yaml.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, "can't marshal configuration")
+	}
+
+	if o.layers {
+		var header bytes.Buffer
+		header.WriteString("# Layers, later ones override earlier ones:\n")
+		for _, l := range Layers(p) {
+			fmt.Fprintf(&header, "#   %d: %s\n", l.Priority, l)
+		}
+
+		b = append(header.Bytes(), b...)
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// _size matches sizes with units, e.g. 1.5GB or 512 KiB.
+var _size = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*(B|KB|MB|GB|TB|KiB|MiB|GiB|TiB)$`)
+
+var _sizeUnits = map[string]float64{
+	"B":   1,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+	"TiB": 1 << 40,
+}
+
+// NormalizeUnits returns a copy of a configuration value with strings of
+// durations and sizes in canonical forms, so equal values are written the
+// same way, e.g. 60s and 1m are both 1m0s. Durations are written by
+// time.Duration.String and sizes with the largest binary unit dividing
+// them, or the largest decimal one, e.g. 1024KiB is 1MiB and 1000MB is 1GB.
+// Numbers without units are kept as is.
+func NormalizeUnits(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(v))
+		for key, val := range v {
+			m[key] = NormalizeUnits(val)
+		}
+
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = NormalizeUnits(val)
+		}
+
+		return s
+	case string:
+		return normalizeUnits(v)
+	}
+
+	return value
+}
+
+func normalizeUnits(s string) string {
+	if d, err := time.ParseDuration(s); err == nil && strings.IndexFunc(s, isUnitLetter) >= 0 {
+		return d.String()
+	}
+
+	m := _size.FindStringSubmatch(s)
+	if m == nil {
+		return s
+	}
+
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return s
+	}
+
+	bytes := n * _sizeUnits[m[2]]
+	if bytes != float64(uint64(bytes)) {
+		return s
+	}
+
+	for _, units := range [][]string{{"TiB", "GiB", "MiB", "KiB"}, {"TB", "GB", "MB", "KB"}} {
+		for _, unit := range units {
+			if q := bytes / _sizeUnits[unit]; q >= 1 && q == float64(uint64(q)) {
+				return fmt.Sprintf("%d%s", uint64(q), unit)
+			}
+		}
+	}
+
+	return fmt.Sprintf("%dB", uint64(bytes))
+}
+
+func isUnitLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r == 'µ'
+}
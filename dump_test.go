@@ -0,0 +1,76 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDump(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+timeouts: {read: 60s, write: 1500ms}
+cache: {size: 1024KiB, disk: 1000MB}
+name: 5m
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var buf bytes.Buffer
+	require.NoError(t, Dump(&buf, p))
+	assert.Equal(t, "cache:\n  disk: 1000MB\n  size: 1024KiB\nname: 5m\n"+
+		"timeouts:\n  read: 60s\n  write: 1500ms\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, Dump(&buf, p, WithNormalizedUnits()))
+	assert.Equal(t, "cache:\n  disk: 1GB\n  size: 1MiB\nname: 5m0s\n"+
+		"timeouts:\n  read: 1m0s\n  write: 1.5s\n", buf.String())
+	assert.Equal(t, "60s", p.Get("timeouts.read").Value(), "The provider shouldn't change")
+}
+
+func TestNormalizeUnits(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"90s":    "1m30s",
+		"1h0m":   "1h0m0s",
+		"0":      "0",
+		"10":     "10",
+		"1024B":  "1KiB",
+		"1500B":  "1500B",
+		"1.5GB":  "1500MB",
+		"2 MiB":  "2MiB",
+		"0.5KiB": "512B",
+		"0.1B":   "0.1B",
+		"text":   "text",
+		"5 GB!":  "5 GB!",
+	}
+
+	for in, out := range tests {
+		assert.Equal(t, out, NormalizeUnits(in), "Wrong normalization of %q", in)
+	}
+
+	assert.Equal(t, []interface{}{"1m0s", 60}, NormalizeUnits([]interface{}{"60s", 60}))
+}
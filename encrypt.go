@@ -0,0 +1,282 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	_encryptedPrefix = "ENC["
+	_encryptedSuffix = "]"
+)
+
+// An Encrypter encrypts values written with WithEncryptedKeys, e.g. with
+// age or a KMS.
+type Encrypter interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+}
+
+// A Decrypter decrypts values encrypted by an Encrypter.
+type Decrypter interface {
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// A Cipher both encrypts and decrypts values.
+type Cipher interface {
+	Encrypter
+	Decrypter
+}
+
+// NewAESCipher returns a Cipher using AES-GCM with a 16, 24 or 32 byte key.
+func NewAESCipher(key []byte) (Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return aesCipher{gcm}, nil
+}
+
+type aesCipher struct {
+	gcm cipher.AEAD
+}
+
+func (c aesCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return c.gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c aesCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	n := c.gcm.NonceSize()
+	if len(ciphertext) < n {
+		return nil, errors.New("ciphertext is too short")
+	}
+
+	return c.gcm.Open(nil, ciphertext[:n], ciphertext[n:], nil)
+}
+
+// WithEncryptedKeys encrypts values of the keys before they are written, so
+// secrets are never stored in plaintext. Segments of keys equal to the
+// Wildcard match any map key or array index, e.g. clients.*.password.
+// Values, including collections, are replaced with ENC[base64] strings that
+// providers created by NewProviderWithDecryption decrypt. Ciphertexts are
+// bound to their keys: the encrypted YAML includes segments of the key along
+// with the value, so a value moved to another key fails to decrypt.
+func WithEncryptedKeys(e Encrypter, keys ...string) DumpOption {
+	return func(o *dumpOptions) {
+		o.encrypter = e
+		o.encrypted = append(o.encrypted, keys...)
+	}
+}
+
+// encryptKeys replaces values of the keys in a tree with encrypted ones.
+func encryptKeys(value interface{}, e Encrypter, keys []string, sep string) (interface{}, error) {
+	patterns := make([][]string, len(keys))
+	for i, key := range keys {
+		patterns[i] = splitKey(key, sep)
+	}
+
+	var walk func(value interface{}, path []string) (interface{}, error)
+	walk = func(value interface{}, path []string) (interface{}, error) {
+		if len(path) > 0 && matchesAny(patterns, path, sep) {
+			return encryptValue(e, value, path)
+		}
+
+		switch v := value.(type) {
+		case map[interface{}]interface{}:
+			m := make(map[interface{}]interface{}, len(v))
+			for key, val := range v {
+				res, err := walk(val, append(path[:len(path):len(path)], fmt.Sprint(key)))
+				if err != nil {
+					return nil, err
+				}
+
+				m[key] = res
+			}
+
+			return m, nil
+		case []interface{}:
+			s := make([]interface{}, len(v))
+			for i, val := range v {
+				res, err := walk(val, append(path[:len(path):len(path)], strconv.Itoa(i)))
+				if err != nil {
+					return nil, err
+				}
+
+				s[i] = res
+			}
+
+			return s, nil
+		}
+
+		return value, nil
+	}
+
+	return walk(value, nil)
+}
+
+func matchesAny(patterns [][]string, path []string, sep string) bool {
+	for _, pattern := range patterns {
+		if len(pattern) != len(path) {
+			continue
+		}
+
+		matched := true
+		for i := range pattern {
+			if pattern[i] != Wildcard && unescapeSeparators(pattern[i], sep) != path[i] {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// encryptedValue is the plaintext of encrypted values, that binds them to
+// segments of their keys.
+type encryptedValue struct {
+	Key   []string    `yaml:"key"`
+	Value interface{} `yaml:"value"`
+}
+
+func encryptValue(e Encrypter, value interface{}, path []string) (interface{}, error) {
+	b, err := yaml.Marshal(encryptedValue{Key: path, Value: value})
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := e.Encrypt(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return _encryptedPrefix + base64.StdEncoding.EncodeToString(ciphertext) + _encryptedSuffix, nil
+}
+
+// NewProviderWithDecryption creates a provider, that decrypts ENC[base64]
+// values written with WithEncryptedKeys.
+func NewProviderWithDecryption(p Provider, d Decrypter) (Provider, error) {
+	if p == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	sep := separatorOf(p)
+	root, err := decryptValues(deepCopy(p.Get(Root).Value()), d, Root, nil, sep)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDerivedProvider(p, root)
+}
+
+// decryptValues decrypts values in a tree, path contains unescaped segments
+// of the key.
+func decryptValues(value interface{}, d Decrypter, key string, path []string, sep string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		for k, val := range v {
+			segment := fmt.Sprint(k)
+			res, err := decryptValues(val, d, joinKey(key, escapeSeparators(segment, sep), sep), append(path[:len(path):len(path)], segment), sep)
+			if err != nil {
+				return nil, err
+			}
+
+			v[k] = res
+		}
+	case []interface{}:
+		for i, val := range v {
+			res, err := decryptValues(val, d, joinKey(key, strconv.Itoa(i), sep), append(path[:len(path):len(path)], strconv.Itoa(i)), sep)
+			if err != nil {
+				return nil, err
+			}
+
+			v[i] = res
+		}
+	case string:
+		if !strings.HasPrefix(v, _encryptedPrefix) || !strings.HasSuffix(v, _encryptedSuffix) {
+			return value, nil
+		}
+
+		ciphertext, err := base64.StdEncoding.DecodeString(v[len(_encryptedPrefix) : len(v)-len(_encryptedSuffix)])
+		if err != nil {
+			return nil, errorWithKey(err, key)
+		}
+
+		plaintext, err := d.Decrypt(ciphertext)
+		if err != nil {
+			return nil, errorWithKey(err, key)
+		}
+
+		var res encryptedValue
+		if err := yaml.Unmarshal(plaintext, &res); err != nil {
+			return nil, errorWithKey(err, key)
+		}
+
+		if !sameSegments(res.Key, path) {
+			return nil, errorWithKey(fmt.Errorf("value is encrypted for another key %q", strings.Join(res.Key, sep)), key)
+		}
+
+		return res.Value, nil
+	}
+
+	return value, nil
+}
+
+// sameSegments compares segments of keys case insensitively, like lookups
+// do.
+func sameSegments(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
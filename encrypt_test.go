@@ -0,0 +1,111 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingCipher struct{}
+
+func (failingCipher) Encrypt([]byte) ([]byte, error) { return nil, errors.New("kms is unavailable") }
+func (failingCipher) Decrypt([]byte) ([]byte, error) { return nil, errors.New("kms is unavailable") }
+
+func TestWithEncryptedKeys(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewAESCipher(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+db:
+  host: localhost
+  password: secret
+clients:
+  - {name: a, token: 1234}
+  - {name: b, token: [x, y]}
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var buf bytes.Buffer
+	require.NoError(t, Dump(&buf, p, WithEncryptedKeys(c, "db.password", "clients.*.token")))
+	assert.NotContains(t, buf.String(), "secret")
+	assert.NotContains(t, buf.String(), "1234")
+	assert.Contains(t, buf.String(), "password: ENC[")
+	assert.Contains(t, buf.String(), "host: localhost")
+
+	saved, err := NewYAMLProviderFromBytes(buf.Bytes())
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	decrypted, err := NewProviderWithDecryption(saved, c)
+	require.NoError(t, err)
+	assert.Equal(t, p.Get(Root).Value(), decrypted.Get(Root).Value())
+	assert.Equal(t, 1234, decrypted.Get("clients.0.token").Value())
+
+	moved := bytes.Replace(buf.Bytes(), []byte("password:"), []byte("token:"), 1)
+	saved, err = NewYAMLProviderFromBytes(moved)
+	require.NoError(t, err, "Can't create a YAML provider")
+	_, err = NewProviderWithDecryption(saved, c)
+	require.Error(t, err, "Values moved to other keys shouldn't decrypt")
+	assert.Contains(t, err.Error(), `for key "db.token": value is encrypted for another key "db.password"`)
+
+	_, err = NewProviderWithDecryption(saved, failingCipher{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `for key "`)
+	assert.Contains(t, err.Error(), "kms is unavailable")
+
+	err = Dump(&buf, p, WithEncryptedKeys(failingCipher{}, "db.password"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't encrypt configuration: kms is unavailable")
+}
+
+func TestAESCipher(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewAESCipher([]byte("short"))
+	require.Error(t, err)
+
+	c, err := NewAESCipher(bytes.Repeat([]byte{1}, 16))
+	require.NoError(t, err)
+
+	ciphertext, err := c.Encrypt([]byte("secret"))
+	require.NoError(t, err)
+
+	plaintext, err := c.Decrypt(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+
+	_, err = c.Decrypt(ciphertext[:4])
+	assert.Error(t, err)
+
+	other, err := NewAESCipher(bytes.Repeat([]byte{2}, 16))
+	require.NoError(t, err)
+	_, err = other.Decrypt(ciphertext)
+	assert.Error(t, err, "Expected an error with a wrong key")
+
+	_, err = NewProviderWithDecryption(nil, c)
+	assert.Error(t, err)
+}
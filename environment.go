@@ -0,0 +1,65 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"path/filepath"
+)
+
+const (
+	_baseFile    = "base.yaml"
+	_secretsFile = "secrets.yaml"
+	_localFile   = "local.yaml"
+)
+
+// NewYAMLProviderForEnvironment creates a configuration provider from the
+// YAML files in a directory, which are merged in the following order,
+// from the lowest priority to the highest:
+//
+// 	base.yaml    - shared by all environments, required.
+// 	<env>.yaml   - environment specific, e.g. production.yaml, required.
+// 	secrets.yaml - secrets, optional.
+// 	local.yaml   - local overrides for development, optional.
+func NewYAMLProviderForEnvironment(dir string, env string) (Provider, error) {
+	return NewYAML(Environment(dir, env))
+}
+
+// Environment adds the YAML files of the environment in the directory to
+// read configuration from, see NewYAMLProviderForEnvironment.
+func Environment(dir string, env string) YAMLOption {
+	return func(o *yamlOptions) {
+		if env == "" {
+			if o.err == nil {
+				o.err = errors.New("empty environment name")
+			}
+
+			return
+		}
+
+		o.sources = append(o.sources,
+			yamlSource{file: filepath.Join(dir, _baseFile)},
+			yamlSource{file: filepath.Join(dir, env+".yaml")},
+			yamlSource{file: filepath.Join(dir, _secretsFile), optional: true, quiet: true},
+			yamlSource{file: filepath.Join(dir, _localFile), optional: true, quiet: true},
+		)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewYAMLProviderForEnvironment(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderForEnvironment("./testdata", "dev")
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	assert.Equal(t, "base_only", p.Get("value").String())
+	assert.Equal(t, "dev_setting", p.Get("value_override").String())
+	assert.Equal(t, "my_${secret}", p.Get("secret").String())
+}
+
+func TestNewYAMLProviderForEnvironmentWithLocalOverrides(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestNewYAMLProviderForEnvironment")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+
+	write("base.yaml", "a: base\nb: base\nc: base\nd: base")
+	write("prod.yaml", "b: prod\nc: prod\nd: prod")
+	write("local.yaml", "d: local")
+
+	p, err := NewYAMLProviderForEnvironment(dir, "prod")
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "base", p.Get("a").String())
+	assert.Equal(t, "prod", p.Get("b").String())
+	assert.Equal(t, "prod", p.Get("c").String())
+	assert.Equal(t, "local", p.Get("d").String())
+
+	write("secrets.yaml", "c: secret\nd: secret")
+	p, err = NewYAMLProviderForEnvironment(dir, "prod")
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "secret", p.Get("c").String())
+	assert.Equal(t, "local", p.Get("d").String())
+}
+
+func TestNewYAMLProviderForEnvironmentErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewYAMLProviderForEnvironment("./testdata", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty environment name")
+
+	_, err = NewYAMLProviderForEnvironment("./testdata", "staging")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "staging.yaml")
+
+	_, err = NewYAMLProviderForEnvironment("./missing", "dev")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "base.yaml")
+}
+
+func TestEnvironmentWithOptions(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(key string) (string, bool) { return "expanded", key == "secret" }
+
+	var buf bytes.Buffer
+	p, err := NewYAML(
+		Environment("./testdata", "dev"),
+		WithExpand(lookup),
+		WithLogger(StdLogger(log.New(&buf, "", 0))),
+	)
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "dev_setting", p.Get("value_override").String())
+	assert.Equal(t, "my_expanded", p.Get("secret").String())
+	assert.NotContains(t, buf.String(), "local.yaml", "Missing conventional files should be skipped quietly")
+
+	_, err = NewYAML(Environment("./testdata", ""), Environment("./testdata", "dev"))
+	assert.EqualError(t, err, "empty environment name")
+}
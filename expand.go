@@ -30,6 +30,9 @@ import (
 type expandTransformer struct {
 	transform.NopResetter
 	expand func(string) (string, error)
+
+	// Number of sequences expanded.
+	expansions int
 }
 
 // First char of shell variable may be [a-zA-Z_]
@@ -191,6 +194,7 @@ func (e *expandTransformer) Transform(dst, src []byte, atEOF bool) (int, int, er
 		cnt := copy(dst[dstPos:], replacement)
 		srcPos = tokenEnd
 		dstPos += cnt
+		e.expansions++
 	}
 
 	return dstPos, srcPos, nil
@@ -0,0 +1,91 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExportEnv flattens the subtree of a provider at the prefix into sorted
+// NAME=value pairs for environments of child processes, e.g.
+//
+// 	cmd.Env = append(os.Environ(), config.ExportEnv(p, "sidecar", "SIDECAR")...)
+//
+// exports sidecar.http.port as SIDECAR_HTTP_PORT. Names are segments of keys
+// under the prefix joined with underscores, uppercased, with characters
+// other than letters and digits replaced with underscores. Sequence elements
+// are named by their indexes, nulls are exported as empty strings and empty
+// collections are skipped.
+func ExportEnv(p Provider, prefix, envPrefix string) []string {
+	var env []string
+	var walk func(name string, value interface{})
+	walk = func(name string, value interface{}) {
+		switch v := value.(type) {
+		case map[interface{}]interface{}:
+			for key, child := range v {
+				walk(joinEnvName(name, fmt.Sprint(key)), child)
+			}
+		case []interface{}:
+			for i, child := range v {
+				walk(joinEnvName(name, strconv.Itoa(i)), child)
+			}
+		case nil:
+			env = append(env, name+"=")
+		default:
+			env = append(env, name+"="+fmt.Sprint(v))
+		}
+	}
+
+	v := p.Get(prefix)
+	if !v.HasValue() {
+		return nil
+	}
+
+	walk(envName(envPrefix), v.Value())
+	sort.Strings(env)
+	return env
+}
+
+func joinEnvName(name, segment string) string {
+	if name == "" {
+		return envName(segment)
+	}
+
+	return name + "_" + envName(segment)
+}
+
+// envName uppercases a name and replaces characters other than letters and
+// digits with underscores.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+
+		return '_'
+	}, name)
+}
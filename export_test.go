@@ -0,0 +1,56 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportEnv(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+sidecar:
+  http:
+    port: 8080
+    read-timeout: 1s
+  peers: [a, b]
+  token: ~
+  labels: {}
+other: x
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	assert.Equal(t, []string{
+		"SIDECAR_HTTP_PORT=8080",
+		"SIDECAR_HTTP_READ_TIMEOUT=1s",
+		"SIDECAR_PEERS_0=a",
+		"SIDECAR_PEERS_1=b",
+		"SIDECAR_TOKEN=",
+	}, ExportEnv(p, "sidecar", "SIDECAR"))
+
+	assert.Equal(t, []string{"PORT=8080", "READ_TIMEOUT=1s"}, ExportEnv(p, "sidecar.http", ""))
+	assert.Equal(t, []string{"APP_OTHER=x"}, ExportEnv(p, "other", "app_other"))
+	assert.Nil(t, ExportEnv(p, "missing", "APP"))
+}
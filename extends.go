@@ -0,0 +1,237 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// _extendsKey references a block the enclosing block inherits values from.
+const _extendsKey = "extends"
+
+// NewProviderWithExtends creates a provider, where blocks with an extends key
+// inherit values of the referenced blocks and override them, e.g.
+//
+// 	clusters:
+// 	  default:
+// 	    replicas: 3
+// 	    region: us-east
+// 	  west:
+// 	    extends: default
+// 	    region: us-west
+//
+// the west cluster has 3 replicas in us-west. References are looked up
+// among siblings of the extending block first, and then from the root,
+// e.g. "clusters.default". Referenced blocks can extend other blocks too,
+// but cycles are reported as errors. Extends keys are not visible in the
+// new provider.
+func NewProviderWithExtends(p Provider) (Provider, error) {
+	if p == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	e := extender{
+		root:       p.Get(Root).Value(),
+		separator:  separatorOf(p),
+		resolved:   make(map[string]interface{}),
+		inProgress: make(map[string]bool),
+	}
+
+	root, err := e.resolve(nil, e.root)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDerivedProvider(p, root)
+}
+
+type extender struct {
+	root       interface{}
+	separator  string
+	resolved   map[string]interface{}
+	inProgress map[string]bool
+}
+
+// resolve returns a copy of the node at the path with all the extends
+// resolved in it and its children.
+func (e *extender) resolve(path []string, node interface{}) (interface{}, error) {
+	key := strings.Join(path, e.separator)
+	if v, ok := e.resolved[key]; ok {
+		return v, nil
+	}
+
+	if e.inProgress[key] {
+		return nil, fmt.Errorf("extends cycle detected at %q", key)
+	}
+
+	e.inProgress[key] = true
+	defer delete(e.inProgress, key)
+
+	var res interface{}
+	switch n := node.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(n))
+		for k, v := range n {
+			if k == _extendsKey {
+				continue
+			}
+
+			child, err := e.resolve(childPath(path, fmt.Sprint(k)), v)
+			if err != nil {
+				return nil, err
+			}
+
+			m[k] = child
+		}
+
+		res = m
+		if ref, ok := n[_extendsKey]; ok {
+			base, err := e.base(path, ref)
+			if err != nil {
+				return nil, err
+			}
+
+			if res, err = mergeMaps(base, m); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		s := make([]interface{}, len(n))
+		for i, v := range n {
+			child, err := e.resolve(childPath(path, strconv.Itoa(i)), v)
+			if err != nil {
+				return nil, err
+			}
+
+			s[i] = child
+		}
+
+		res = s
+	default:
+		res = node
+	}
+
+	e.resolved[key] = res
+	return res, nil
+}
+
+// base returns a resolved copy of a block referenced by the block at the path.
+func (e *extender) base(path []string, ref interface{}) (interface{}, error) {
+	key := strings.Join(path, e.separator)
+	name, ok := ref.(string)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("%s in %q must be a name of a block, found %v", _extendsKey, key, ref)
+	}
+
+	segments := splitKey(name, e.separator)
+	for i := range segments {
+		segments[i] = unescapeSeparators(segments[i], e.separator)
+	}
+
+	// Look up the siblings first, then the root.
+	candidates := [][]string{segments}
+	if len(path) > 1 {
+		parent := append([]string{}, path[:len(path)-1]...)
+		candidates = [][]string{append(parent, segments...), segments}
+	}
+
+	for _, candidate := range candidates {
+		basePath, node, ok := lookup(e.root, candidate)
+		if !ok {
+			continue
+		}
+
+		base, err := e.resolve(basePath, node)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := base.(map[interface{}]interface{}); !ok {
+			return nil, fmt.Errorf("%q extended by %q is not a block", name, key)
+		}
+
+		// Merging modifies the destination, so use a copy of the base.
+		return deepCopy(base), nil
+	}
+
+	return nil, fmt.Errorf("can't find %q extended by %q", name, key)
+}
+
+// lookup finds a node by the path segments in a tree and returns it together
+// with its actual path, since keys are matched case insensitively.
+func lookup(root interface{}, segments []string) ([]string, interface{}, bool) {
+	node := root
+	var path []string
+	for _, segment := range segments {
+		found := false
+		switch n := node.(type) {
+		case map[interface{}]interface{}:
+			for k, v := range n {
+				if key := fmt.Sprint(k); strings.EqualFold(key, segment) {
+					path, node, found = append(path, key), v, true
+					break
+				}
+			}
+		case []interface{}:
+			if i, err := strconv.Atoi(segment); err == nil && i >= 0 && i < len(n) {
+				path, node, found = append(path, segment), n[i], true
+			}
+		}
+
+		if !found {
+			return nil, nil, false
+		}
+	}
+
+	return path, node, true
+}
+
+// childPath returns a copy of the path with the key appended.
+func childPath(path []string, key string) []string {
+	res := make([]string, len(path), len(path)+1)
+	copy(res, path)
+	return append(res, key)
+}
+
+// deepCopy copies maps and slices of a tree recursively.
+func deepCopy(node interface{}) interface{} {
+	switch n := node.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(n))
+		for k, v := range n {
+			m[k] = deepCopy(v)
+		}
+
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(n))
+		for i, v := range n {
+			s[i] = deepCopy(v)
+		}
+
+		return s
+	}
+
+	return node
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProviderWithExtends(t *testing.T) {
+	t.Parallel()
+
+	y, err := NewYAMLProviderFromBytes([]byte(`
+templates:
+  web:
+    port: 80
+    tls:
+      enabled: false
+      ciphers: [a, b]
+clusters:
+  default:
+    extends: templates.web
+    replicas: 3
+    region: us-east
+  west:
+    extends: default
+    region: us-west
+    tls:
+      enabled: true
+  north:
+    extends: west
+    replicas: 5
+  list:
+    - extends: clusters.default
+      name: first
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	p, err := NewProviderWithExtends(y)
+	require.NoError(t, err)
+
+	type cluster struct {
+		Port     int
+		Replicas int
+		Region   string
+		TLS      struct {
+			Enabled bool
+			Ciphers []string
+		}
+	}
+
+	var west cluster
+	require.NoError(t, p.Get("clusters.west").Populate(&west))
+	assert.Equal(t, 80, west.Port)
+	assert.Equal(t, 3, west.Replicas)
+	assert.Equal(t, "us-west", west.Region)
+	assert.True(t, west.TLS.Enabled)
+	assert.Equal(t, []string{"a", "b"}, west.TLS.Ciphers)
+
+	var north cluster
+	require.NoError(t, p.Get("clusters.north").Populate(&north))
+	assert.Equal(t, 5, north.Replicas)
+	assert.Equal(t, "us-west", north.Region)
+
+	assert.Equal(t, "us-east", p.Get("clusters.list.0.region").Value())
+	assert.Equal(t, "first", p.Get("clusters.list.0.name").Value())
+	assert.False(t, p.Get("clusters.west.extends").HasValue())
+	assert.False(t, p.Get("templates.web.replicas").HasValue(), "Base blocks must not be modified")
+	assert.False(t, p.Get("clusters.default.tls.enabled").Value().(bool), "Base blocks must not be modified")
+}
+
+func TestNewProviderWithExtendsCustomSeparator(t *testing.T) {
+	t.Parallel()
+
+	y, err := NewYAMLProviderFromReaderWithSeparator("/", bytes.NewBufferString(`
+hosts:
+  base.example.com:
+    port: 80
+  www.example.com:
+    extends: hosts/base.example.com
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	p, err := NewProviderWithExtends(y)
+	require.NoError(t, err)
+	assert.Equal(t, 80, p.Get("hosts/www.example.com/port").Value())
+}
+
+func TestNewProviderWithExtendsErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewProviderWithExtends(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "received a nil provider")
+
+	tests := map[string]string{
+		"a:\n  extends: b\nb:\n  extends: a":           "extends cycle detected",
+		"a:\n  extends: a":                             `extends cycle detected at "a"`,
+		"a:\n  b:\n    extends: a":                     `extends cycle detected at "a"`,
+		"a:\n  extends: missing":                       `can't find "missing" extended by "a"`,
+		"a:\n  extends: [b]\nb: {}":                    `extends in "a" must be a name of a block, found [b]`,
+		"a:\n  extends: b\nb: 42":                      `"b" extended by "a" is not a block`,
+		"a:\n  extends: b\n  c:\n    d: 1\nb:\n  c: 1": "can't merge",
+	}
+
+	for yaml, msg := range tests {
+		y, err := NewYAMLProviderFromBytes([]byte(yaml))
+		require.NoError(t, err, "Can't create a YAML provider")
+
+		_, err = NewProviderWithExtends(y)
+		require.Error(t, err, "Expected an error for %q", yaml)
+		assert.Contains(t, err.Error(), msg, "Unexpected error for %q", yaml)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// _factPrefix starts keys of runtime facts in expanded values.
+const _factPrefix = "runtime:"
+
+var _facts = struct {
+	sync.RWMutex
+	byName map[string]func() (string, error)
+}{byName: map[string]func() (string, error){
+	"hostname": os.Hostname,
+	"pid":      func() (string, error) { return strconv.Itoa(os.Getpid()), nil },
+	"num_cpu":  func() (string, error) { return strconv.Itoa(runtime.NumCPU()), nil },
+	"goos":     func() (string, error) { return runtime.GOOS, nil },
+	"goarch":   func() (string, error) { return runtime.GOARCH, nil },
+}}
+
+// RegisterFact registers a runtime fact for RuntimeMapping, e.g. a datacenter
+// of the host:
+//
+// 	config.RegisterFact("datacenter", func() (string, error) {
+// 		return readDatacenter("/etc/datacenter")
+// 	})
+//
+// The hostname, pid, num_cpu, goos and goarch facts are registered by
+// default. Registration is usually done in init functions.
+func RegisterFact(name string, fact func() (string, error)) error {
+	if name == "" {
+		return errors.New("empty fact name")
+	}
+
+	if fact == nil {
+		return errors.New("received a nil fact")
+	}
+
+	_facts.Lock()
+	defer _facts.Unlock()
+
+	if _, ok := _facts.byName[name]; ok {
+		return fmt.Errorf("fact %q is already registered", name)
+	}
+
+	_facts.byName[name] = fact
+	return nil
+}
+
+// RuntimeMapping returns a mapping function for expansion, that replaces
+// ${runtime:name} sequences with runtime facts and looks up other keys with
+// the mapping it wraps, if it is not nil, e.g.
+//
+// 	p, err := config.NewYAML(
+// 		config.File("base.yaml"),
+// 		config.WithExpand(config.RuntimeMapping(os.LookupEnv)),
+// 	)
+//
+// expands "${runtime:hostname}" to the name of the host. Facts that fail
+// are treated as missing values.
+func RuntimeMapping(mapping func(string) (string, bool)) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		if !strings.HasPrefix(key, _factPrefix) {
+			if mapping == nil {
+				return "", false
+			}
+
+			return mapping(key)
+		}
+
+		_facts.RLock()
+		fact, ok := _facts.byName[key[len(_factPrefix):]]
+		_facts.RUnlock()
+
+		if !ok {
+			return "", false
+		}
+
+		val, err := fact()
+		if err != nil {
+			return "", false
+		}
+
+		return val, true
+	}
+}
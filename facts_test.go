@@ -0,0 +1,86 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeMapping(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, RegisterFact("TestRuntimeMapping", func() (string, error) { return "dc1", nil }))
+	require.NoError(t, RegisterFact("TestRuntimeMappingFailure", func() (string, error) {
+		return "", errors.New("unknown datacenter")
+	}))
+
+	assert.Error(t, RegisterFact("hostname", os.Hostname), "Duplicate registration")
+	assert.Error(t, RegisterFact("", os.Hostname))
+	assert.Error(t, RegisterFact("nil", nil))
+
+	env := func(key string) (string, bool) {
+		return "env", key == "VAR"
+	}
+
+	p, err := NewYAML(
+		Source(bytes.NewBufferString(`
+host: ${runtime:hostname}
+pid: ${runtime:pid}
+os: ${runtime:goos}/${runtime:goarch}
+dc: ${runtime:TestRuntimeMapping}
+failed: ${runtime:TestRuntimeMappingFailure:fallback}
+var: ${VAR}
+default: ${MISSING:default}
+`)),
+		WithExpand(RuntimeMapping(env)),
+	)
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	host, err := os.Hostname()
+	require.NoError(t, err)
+
+	assert.Equal(t, host, p.Get("host").String())
+	assert.Equal(t, os.Getpid(), p.Get("pid").Value())
+	assert.Equal(t, runtime.GOOS+"/"+runtime.GOARCH, p.Get("os").Value())
+	assert.Equal(t, "dc1", p.Get("dc").Value())
+	assert.Equal(t, "fallback", p.Get("failed").Value())
+	assert.Equal(t, "env", p.Get("var").Value())
+	assert.Equal(t, "default", p.Get("default").Value())
+
+	m := RuntimeMapping(nil)
+	n, ok := m("runtime:num_cpu")
+	assert.True(t, ok)
+	assert.Equal(t, strconv.Itoa(runtime.NumCPU()), n)
+
+	_, ok = m("runtime:missing")
+	assert.False(t, ok)
+
+	_, ok = m("VAR")
+	assert.False(t, ok)
+}
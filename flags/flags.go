@@ -0,0 +1,138 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package flags evaluates feature flags defined in configuration, e.g.
+//
+// 	flags:
+// 	  new-checkout:
+// 	    enabled: true
+// 	    percentage: 25
+// 	    attributes:
+// 	      country: [US, CA]
+//
+// enables the new-checkout flag for a quarter of users from the US and Canada.
+// Users are assigned to the percentage by a stable hash of their keys, so
+// the same user always gets the same result for the same flag definition.
+package flags // import "go.uber.org/config/flags"
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+
+	"go.uber.org/config"
+)
+
+// _buckets is a number of buckets keys are hashed into,
+// it allows percentages with precision of 0.01.
+const _buckets = 10000
+
+// Flag is a definition of a feature flag.
+type Flag struct {
+	// Enabled turns the flag on, all the other conditions are checked
+	// only for enabled flags.
+	Enabled bool `yaml:"enabled"`
+
+	// Percentage of keys the flag is on for, 100 by default.
+	Percentage float64 `yaml:"percentage" default:"100" validate:"min=0,max=100"`
+
+	// Attributes the flag is on for. Each attribute must have one of the
+	// listed values.
+	Attributes map[string][]string `yaml:"attributes"`
+}
+
+// Flags evaluates feature flags defined in a provider.
+type Flags struct {
+	provider config.Provider
+}
+
+// New creates feature flags defined at the root of the provider, use
+// config.NewScopedProvider to evaluate flags defined in a nested section.
+// Flag definitions are read from the provider on each evaluation.
+func New(provider config.Provider) (*Flags, error) {
+	if provider == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	return &Flags{provider: provider}, nil
+}
+
+// Get returns a definition of the flag. Flags that are not defined
+// are disabled.
+func (f *Flags) Get(name string) (Flag, error) {
+	var flag Flag
+	v := f.provider.Get(name)
+	if !v.HasValue() {
+		return flag, nil
+	}
+
+	if err := v.Populate(&flag); err != nil {
+		return flag, fmt.Errorf("invalid definition of flag %q: %v", name, err)
+	}
+
+	return flag, nil
+}
+
+// Enabled evaluates the flag for the key, e.g. a user ID, with the attributes.
+func (f *Flags) Enabled(name string, key string, attributes map[string]string) (bool, error) {
+	flag, err := f.Get(name)
+	if err != nil {
+		return false, err
+	}
+
+	return flag.Evaluate(name, key, attributes), nil
+}
+
+// Evaluate returns whether the flag with the name is on for the key with the
+// attributes. The name is used to assign keys to different percentages for
+// different flags.
+func (f Flag) Evaluate(name string, key string, attributes map[string]string) bool {
+	if !f.Enabled {
+		return false
+	}
+
+	for attr, allowed := range f.Attributes {
+		val, ok := attributes[attr]
+		if !ok || !contains(allowed, val) {
+			return false
+		}
+	}
+
+	return float64(bucket(name, key)) < f.Percentage*_buckets/100
+}
+
+// bucket hashes the key into one of the buckets.
+func bucket(name string, key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return h.Sum32() % _buckets
+}
+
+func contains(values []string, val string) bool {
+	for _, v := range values {
+		if v == val {
+			return true
+		}
+	}
+
+	return false
+}
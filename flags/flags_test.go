@@ -0,0 +1,175 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package flags
+
+import (
+	"fmt"
+	"testing"
+
+	"go.uber.org/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFlags(t *testing.T, yaml string) *Flags {
+	p, err := config.NewYAMLProviderFromBytes([]byte(yaml))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	f, err := New(config.NewScopedProvider("flags", p))
+	require.NoError(t, err)
+	return f
+}
+
+func TestNewWithNilProvider(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "received a nil provider")
+}
+
+func TestBooleanFlags(t *testing.T) {
+	t.Parallel()
+
+	f := newFlags(t, `
+flags:
+  green:
+    enabled: true
+  red:
+    enabled: false
+`)
+
+	for _, tt := range []struct {
+		name     string
+		expected bool
+	}{
+		{"green", true},
+		{"red", false},
+		{"undefined", false},
+	} {
+		on, err := f.Enabled(tt.name, "user", nil)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, on, "Unexpected value for %q", tt.name)
+	}
+}
+
+func TestPercentageFlags(t *testing.T) {
+	t.Parallel()
+
+	f := newFlags(t, `
+flags:
+  quarter:
+    enabled: true
+    percentage: 25
+  none:
+    enabled: true
+    percentage: 0
+`)
+
+	on := 0
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprint("user-", i)
+		res, err := f.Enabled("quarter", key, nil)
+		require.NoError(t, err)
+		if res {
+			on++
+		}
+
+		again, err := f.Enabled("quarter", key, nil)
+		require.NoError(t, err)
+		assert.Equal(t, res, again, "Evaluation must be stable")
+
+		res, err = f.Enabled("none", key, nil)
+		require.NoError(t, err)
+		assert.False(t, res)
+	}
+
+	assert.InDelta(t, 2500, on, 150)
+}
+
+func TestAttributeFlags(t *testing.T) {
+	t.Parallel()
+
+	f := newFlags(t, `
+flags:
+  north-america:
+    enabled: true
+    attributes:
+      country: [US, CA]
+      tier: [gold]
+`)
+
+	for _, tt := range []struct {
+		attrs    map[string]string
+		expected bool
+	}{
+		{map[string]string{"country": "US", "tier": "gold"}, true},
+		{map[string]string{"country": "CA", "tier": "gold", "os": "ios"}, true},
+		{map[string]string{"country": "MX", "tier": "gold"}, false},
+		{map[string]string{"country": "US"}, false},
+		{nil, false},
+	} {
+		on, err := f.Enabled("north-america", "user", tt.attrs)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, on, "Unexpected value for %v", tt.attrs)
+	}
+}
+
+func TestInvalidFlags(t *testing.T) {
+	t.Parallel()
+
+	f := newFlags(t, `
+flags:
+  negative:
+    enabled: true
+    percentage: -1
+  type:
+    enabled: maybe
+`)
+
+	_, err := f.Enabled("negative", "user", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid definition of flag "negative"`)
+
+	_, err = f.Enabled("type", "user", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid definition of flag "type"`)
+}
+
+func TestGetFlag(t *testing.T) {
+	t.Parallel()
+
+	f := newFlags(t, `
+flags:
+  green:
+    enabled: true
+`)
+
+	flag, err := f.Get("green")
+	require.NoError(t, err)
+	assert.Equal(t, Flag{Enabled: true, Percentage: 100}, flag)
+	assert.True(t, flag.Evaluate("green", "user", nil))
+
+	flag, err = f.Get("undefined")
+	require.NoError(t, err)
+	assert.False(t, flag.Evaluate("undefined", "user", nil))
+}
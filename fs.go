@@ -0,0 +1,94 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.16
+// +build go1.16
+
+package config
+
+import (
+	"io"
+	"io/fs"
+)
+
+// NewYAMLProviderFromFS creates a configuration provider from YAML files in
+// a file system, e.g. files embedded with go:embed:
+//
+// 	//go:embed config/*.yaml
+// 	var configs embed.FS
+//
+// 	p, err := config.NewYAMLProviderFromFS(configs, "config/base.yaml", "config/production.yaml")
+//
+// As with NewYAMLProviderFromFiles, all the objects are going to be merged
+// and arrays/values overridden in the order of the paths. Compressed files
+// are decompressed like files on disk.
+func NewYAMLProviderFromFS(fsys fs.FS, paths ...string) (Provider, error) {
+	return NewYAML(FS(fsys, paths...))
+}
+
+// FS adds YAML files in a file system to read configuration from, see
+// NewYAMLProviderFromFS.
+func FS(fsys fs.FS, paths ...string) YAMLOption {
+	return func(o *yamlOptions) {
+		for _, name := range paths {
+			name := name
+			o.sources = append(o.sources, yamlSource{
+				file: name,
+				open: func(int64) (io.ReadCloser, error) { return openFSFile(fsys, name) },
+			})
+		}
+	}
+}
+
+// openFSFile opens a file in a file system, decompressing it if needed.
+func openFSFile(fsys fs.FS, name string) (io.ReadCloser, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	d := decompressorFor(name)
+	if d == nil {
+		return f, nil
+	}
+
+	rc, err := d(f)
+	if err != nil {
+		f.Close()
+		return nil, &fs.PathError{Op: "decompress", Path: name, Err: err}
+	}
+
+	return decompressedFSFile{ReadCloser: rc, file: f}, nil
+}
+
+// decompressedFSFile is like decompressedFile for files of file systems.
+type decompressedFSFile struct {
+	io.ReadCloser
+	file fs.File
+}
+
+func (f decompressedFSFile) Close() error {
+	err := f.ReadCloser.Close()
+	if ferr := f.file.Close(); err == nil {
+		err = ferr
+	}
+
+	return err
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.16
+// +build go1.16
+
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewYAMLProviderFromFS(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromFS(os.DirFS("testdata/fs"), "base.yaml", "dev.yaml")
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "base", p.Get("a").Value())
+	assert.Equal(t, "dev", p.Get("b").Value())
+}
+
+func TestNewYAMLProviderFromMapFS(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte("a: compressed"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	fsys := fstest.MapFS{
+		"a.yaml.gz":   {Data: buf.Bytes()},
+		"bad.yaml.gz": {Data: []byte("a: b")},
+		"bad.yaml":    {Data: []byte("a: [")},
+	}
+
+	p, err := NewYAMLProviderFromFS(fsys, "a.yaml.gz")
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "compressed", p.Get("a").Value())
+
+	_, err = NewYAMLProviderFromFS(fsys, "bad.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `in file: "bad.yaml"`)
+
+	_, err = NewYAMLProviderFromFS(fsys, "bad.yaml.gz")
+	assert.Error(t, err)
+
+	_, err = NewYAMLProviderFromFS(fsys, "missing.yaml")
+	assert.Error(t, err)
+}
+
+func TestFSWithOptions(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"a.yaml":     {Data: []byte("a: ${A}")},
+		"large.yaml": {Data: bytes.Repeat([]byte("a"), 1024)},
+	}
+
+	p, err := NewYAML(FS(fsys, "a.yaml"), WithExpand(func(string) (string, bool) { return "expanded", true }))
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "expanded", p.Get("a").Value())
+
+	_, err = NewYAML(FS(fsys, "large.yaml"), WithLimits(Limits{MaxFileSize: 64}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `in file: "large.yaml"`)
+	assert.Contains(t, err.Error(), "exceeds the limit of 64 bytes")
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultOverrideHeader is a header HeaderOverrides read by default.
+const DefaultOverrideHeader = "X-Config-Override"
+
+// HeaderOverrides extracts overrides of configuration values from requests,
+// e.g. to debug features in staging with
+//
+// 	X-Config-Override: feature.x=true, db.timeout=1s
+//
+// Only the allowed keys can be overridden. Values are parsed as YAML
+// scalars, mappings and sequences are rejected, so overrides can't reach
+// children of the allowed keys. Values with commas must be quoted, e.g.
+// msg="a, b". Keys use the default separator.
+type HeaderOverrides struct {
+	// Header is a name of the header, DefaultOverrideHeader if it is empty.
+	Header string
+
+	// Allowed are keys, that can be overridden, segments equal to the
+	// Wildcard match any segment, e.g. feature.*.
+	Allowed []string
+}
+
+// Parse returns overrides of values of the header.
+func (h HeaderOverrides) Parse(header http.Header) (map[string]interface{}, error) {
+	name := h.Header
+	if name == "" {
+		name = DefaultOverrideHeader
+	}
+
+	overrides := make(map[string]interface{})
+	for _, line := range header[http.CanonicalHeaderKey(name)] {
+		for _, pair := range splitPairs(line) {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("override %q must be key=value", strings.TrimSpace(pair))
+			}
+
+			key := strings.TrimSpace(kv[0])
+			var value interface{}
+			if err := yaml.Unmarshal([]byte(strings.TrimSpace(kv[1])), &value); err != nil {
+				return nil, errorWithKey(err, key)
+			}
+
+			overrides[key] = value
+		}
+	}
+
+	return h.filter(overrides)
+}
+
+// splitPairs splits a header value by commas outside of quotes.
+func splitPairs(line string) []string {
+	var pairs []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			pairs = append(pairs, line[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(pairs, line[start:])
+}
+
+// Claims returns overrides of claims of a verified token, e.g. JWT claims
+// decoded by the caller, which map keys to values.
+func (h HeaderOverrides) Claims(claims map[string]interface{}) (map[string]interface{}, error) {
+	overrides := make(map[string]interface{}, len(claims))
+	for key, value := range claims {
+		overrides[key] = value
+	}
+
+	return h.filter(overrides)
+}
+
+// Middleware layers overrides of requests on their contexts, so
+// FromContext returns providers with them for the requests. Requests with
+// malformed or not allowed overrides are rejected with 400 Bad Request.
+func (h HeaderOverrides) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		overrides, err := h.Parse(r.Header)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if len(overrides) > 0 {
+			r = r.WithContext(WithOverrides(r.Context(), overrides))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h HeaderOverrides) filter(overrides map[string]interface{}) (map[string]interface{}, error) {
+	patterns := make([][]string, 0, len(h.Allowed))
+	for _, key := range h.Allowed {
+		patterns = append(patterns, splitKey(key, _separator))
+	}
+
+	for key, value := range overrides {
+		switch value.(type) {
+		case map[interface{}]interface{}, map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("override of key %q must be a scalar", key)
+		}
+
+		path := splitKey(key, _separator)
+		for i := range path {
+			path[i] = unescapeSeparators(path[i], _separator)
+		}
+
+		if !matchesAny(patterns, path, _separator) {
+			return nil, fmt.Errorf("override of key %q is not allowed", key)
+		}
+	}
+
+	return overrides, nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaderOverridesParse(t *testing.T) {
+	t.Parallel()
+
+	h := HeaderOverrides{Allowed: []string{"feature.*", "db.timeout"}}
+	header := http.Header{}
+	header.Add("x-config-override", "feature.x=true, db.timeout=1s")
+	header.Add("x-config-override", "feature.y=2")
+
+	overrides, err := h.Parse(header)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"feature.x":  true,
+		"feature.y":  2,
+		"db.timeout": "1s",
+	}, overrides)
+
+	header.Set(DefaultOverrideHeader, "db.host=evil")
+	_, err = h.Parse(header)
+	assert.EqualError(t, err, `override of key "db.host" is not allowed`)
+
+	header.Set(DefaultOverrideHeader, `feature.x="a, b", feature.y='c,d',feature.z=e`)
+	overrides, err = h.Parse(header)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"feature.x": "a, b", "feature.y": "c,d", "feature.z": "e"}, overrides)
+
+	for _, value := range []string{"{a: 1}", "[1]", "a: 1"} {
+		header.Set(DefaultOverrideHeader, "feature.x="+value)
+		_, err = h.Parse(header)
+		assert.EqualError(t, err, `override of key "feature.x" must be a scalar`, "Unexpected error for %q", value)
+	}
+
+	header.Set(DefaultOverrideHeader, "feature.x")
+	_, err = h.Parse(header)
+	assert.EqualError(t, err, `override "feature.x" must be key=value`)
+
+	overrides, err = HeaderOverrides{Header: "X-Debug"}.Parse(header)
+	require.NoError(t, err)
+	assert.Empty(t, overrides)
+}
+
+func TestHeaderOverridesClaims(t *testing.T) {
+	t.Parallel()
+
+	h := HeaderOverrides{Allowed: []string{"feature.x"}}
+	overrides, err := h.Claims(map[string]interface{}{"feature.x": true})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"feature.x": true}, overrides)
+
+	_, err = h.Claims(map[string]interface{}{"feature.y": true})
+	assert.Error(t, err)
+
+	_, err = h.Claims(map[string]interface{}{"feature.x": map[string]interface{}{"a": 1}})
+	assert.EqualError(t, err, `override of key "feature.x" must be a scalar`)
+}
+
+func TestHeaderOverridesMiddleware(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte("feature: {x: false}"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var ctx context.Context
+	handler := HeaderOverrides{Allowed: []string{"feature.x"}}.Middleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { ctx = r.Context() }))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(DefaultOverrideHeader, "feature.x=true")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	scoped, err := FromContext(ctx, p)
+	require.NoError(t, err)
+	assert.Equal(t, true, scoped.Get("feature.x").Value())
+	assert.Equal(t, false, p.Get("feature.x").Value())
+
+	r.Header.Set(DefaultOverrideHeader, "feature.y=true")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
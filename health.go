@@ -0,0 +1,75 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// HealthReporter is implemented by providers that can tell whether they are
+// able to serve configuration, e.g. if their backend is reachable.
+type HealthReporter interface {
+	// Healthy returns nil if the provider is healthy, or an error describing
+	// the problem otherwise.
+	Healthy() error
+}
+
+// Healthy reports the health of a provider. Providers that don't implement
+// the HealthReporter interface are considered to be always healthy.
+func Healthy(p Provider) error {
+	if p == nil {
+		return errors.New("received a nil provider")
+	}
+
+	if h, ok := p.(HealthReporter); ok {
+		return h.Healthy()
+	}
+
+	return nil
+}
+
+// Healthy aggregates health reports of all the providers in the group.
+func (p providerGroup) Healthy() error {
+	var msgs []string
+	for _, provider := range p.providers {
+		if err := Healthy(provider); err != nil {
+			msgs = append(msgs, fmt.Sprintf("%q: %v", provider.Name(), err))
+		}
+	}
+
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("unhealthy providers in group %q: %s", p.name, strings.Join(msgs, "; "))
+}
+
+// Healthy returns health of the underlying provider.
+func (sp scopedProvider) Healthy() error {
+	return Healthy(sp.Provider)
+}
+
+// Healthy returns health of the underlying provider.
+func (p *cachedProvider) Healthy() error {
+	return Healthy(p.Provider)
+}
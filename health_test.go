@@ -0,0 +1,91 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type unhealthyProvider struct {
+	NopProvider
+	err error
+}
+
+func (u unhealthyProvider) Name() string {
+	return "unhealthy"
+}
+
+func (u unhealthyProvider) Healthy() error {
+	return u.err
+}
+
+func TestHealthyNilProvider(t *testing.T) {
+	t.Parallel()
+
+	err := Healthy(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "received a nil provider")
+}
+
+func TestHealthyProviderWithoutReporter(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewStaticProvider(map[string]string{"a": "b"})
+	require.NoError(t, err, "Can't create a static provider")
+
+	assert.NoError(t, Healthy(p))
+	assert.NoError(t, Healthy(NopProvider{}))
+}
+
+func TestHealthyProviderGroup(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewStaticProvider(map[string]string{"a": "b"})
+	require.NoError(t, err, "Can't create a static provider")
+
+	u := unhealthyProvider{err: errors.New("backend is unreachable")}
+	pg, err := NewProviderGroup("group", s, u, unhealthyProvider{})
+	require.NoError(t, err)
+
+	err = Healthy(pg)
+	require.Error(t, err)
+	assert.Equal(t, `unhealthy providers in group "group": "unhealthy": backend is unreachable`, err.Error())
+
+	healthy, err := NewProviderGroup("healthy", s, unhealthyProvider{})
+	require.NoError(t, err)
+	assert.NoError(t, Healthy(healthy))
+}
+
+func TestHealthyWrappedProviders(t *testing.T) {
+	t.Parallel()
+
+	u := unhealthyProvider{err: errors.New("down")}
+
+	c, err := newCachedProvider(u)
+	require.NoError(t, err, "Can't create a cached provider")
+	assert.EqualError(t, Healthy(c), "down")
+
+	assert.EqualError(t, Healthy(NewScopedProvider("prefix", u)), "down")
+}
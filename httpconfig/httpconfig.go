@@ -0,0 +1,207 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package httpconfig builds HTTP clients and TLS configurations from
+// configuration subtrees of the following shape:
+//
+//	client:
+//	  timeout: 10s
+//	  transport:
+//	    dialTimeout: 1s
+//	    maxIdleConnsPerHost: 10
+//	    tls:
+//	      caFile: /etc/ssl/ca.pem
+//	      serverName: api.internal
+//
+// Settings of transports can't be changed while they are in use, so build
+// a new client after configuration is reloaded.
+package httpconfig // import "go.uber.org/config/httpconfig"
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"go.uber.org/config"
+)
+
+// TLS configures certificates of connections.
+type TLS struct {
+	// CAFile is a PEM file with certificates of authorities to verify
+	// servers with, system ones are used if it is empty.
+	CAFile string `yaml:"caFile"`
+
+	// CertFile and KeyFile are PEM files with a certificate and its key,
+	// e.g. of a server or of a client for mutual TLS.
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+
+	// CA, Cert and Key are inline PEM alternatives to the files, e.g. for
+	// values of secret stores.
+	CA   string `yaml:"ca"`
+	Cert string `yaml:"cert"`
+	Key  string `yaml:"key"`
+
+	// ServerName overrides the name servers are verified with.
+	ServerName string `yaml:"serverName"`
+
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify"`
+}
+
+// Config builds a TLS configuration, or returns nil if all the settings are
+// empty.
+func (t TLS) Config() (*tls.Config, error) {
+	if t == (TLS{}) {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+
+	if t.CAFile != "" && t.CA != "" {
+		return nil, errors.New("caFile and ca can't be set together")
+	}
+
+	if t.CAFile != "" || t.CA != "" {
+		pem, name := []byte(t.CA), "ca"
+		if t.CAFile != "" {
+			var err error
+			if pem, err = ioutil.ReadFile(t.CAFile); err != nil {
+				return nil, err
+			}
+
+			name = t.CAFile
+		}
+
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %q", name)
+		}
+	}
+
+	cert, err := t.certificate()
+	if err != nil {
+		return nil, err
+	}
+
+	if cert != nil {
+		cfg.Certificates = []tls.Certificate{*cert}
+	}
+
+	return cfg, nil
+}
+
+// certificate loads the certificate, or returns nil if it isn't set.
+func (t TLS) certificate() (*tls.Certificate, error) {
+	files, inline := t.CertFile != "" || t.KeyFile != "", t.Cert != "" || t.Key != ""
+	switch {
+	case files && inline:
+		return nil, errors.New("certificate files and inline certificates can't be set together")
+	case files && (t.CertFile == "" || t.KeyFile == ""):
+		return nil, errors.New("certFile and keyFile must be set together")
+	case inline && (t.Cert == "" || t.Key == ""):
+		return nil, errors.New("cert and key must be set together")
+	case files:
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		return &cert, err
+	case inline:
+		cert, err := tls.X509KeyPair([]byte(t.Cert), []byte(t.Key))
+		return &cert, err
+	}
+
+	return nil, nil
+}
+
+// Transport configures connections of clients.
+type Transport struct {
+	DialTimeout           time.Duration `yaml:"dialTimeout" default:"30s"`
+	KeepAlive             time.Duration `yaml:"keepAlive" default:"30s"`
+	TLSHandshakeTimeout   time.Duration `yaml:"tlsHandshakeTimeout" default:"10s"`
+	ResponseHeaderTimeout time.Duration `yaml:"responseHeaderTimeout"`
+	IdleConnTimeout       time.Duration `yaml:"idleConnTimeout" default:"90s"`
+	MaxIdleConns          int           `yaml:"maxIdleConns" default:"100"`
+	MaxIdleConnsPerHost   int           `yaml:"maxIdleConnsPerHost"`
+	TLS                   TLS           `yaml:"tls"`
+}
+
+// Build builds a transport with the settings.
+func (t Transport) Build() (*http.Transport, error) {
+	tlsConfig, err := t.TLS.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: t.DialTimeout, KeepAlive: t.KeepAlive}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   t.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: t.ResponseHeaderTimeout,
+		IdleConnTimeout:       t.IdleConnTimeout,
+		MaxIdleConns:          t.MaxIdleConns,
+		MaxIdleConnsPerHost:   t.MaxIdleConnsPerHost,
+	}, nil
+}
+
+// Client configures HTTP clients.
+type Client struct {
+	// Timeout limits requests including reading responses, zero means no
+	// limit.
+	Timeout   time.Duration `yaml:"timeout"`
+	Transport Transport     `yaml:"transport"`
+}
+
+// Build builds a client with the settings.
+func (c Client) Build() (*http.Client, error) {
+	t, err := c.Transport.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{Timeout: c.Timeout, Transport: t}, nil
+}
+
+// NewClient builds a client with the configuration from the key.
+func NewClient(p config.Provider, key string) (*http.Client, error) {
+	var c Client
+	if err := p.Get(key).Populate(&c); err != nil {
+		return nil, err
+	}
+
+	return c.Build()
+}
+
+// NewTLSConfig builds a TLS configuration from the key, e.g. for servers.
+func NewTLSConfig(p config.Provider, key string) (*tls.Config, error) {
+	var t TLS
+	if err := p.Get(key).Populate(&t); err != nil {
+		return nil, err
+	}
+
+	return t.Config()
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package httpconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCert writes a self-signed certificate and its key to the directory.
+func writeCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		DNSNames:     []string{"test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "httpconfig")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := writeCert(t, dir)
+	p, err := config.NewYAMLProviderFromBytes([]byte(fmt.Sprintf(`
+client:
+  timeout: 10s
+  transport:
+    dialTimeout: 1s
+    maxIdleConnsPerHost: 10
+    tls:
+      caFile: %s
+      certFile: %s
+      keyFile: %s
+      serverName: api.internal
+`, certFile, certFile, keyFile)))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	c, err := NewClient(p, "client")
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, c.Timeout)
+
+	tr := c.Transport.(*http.Transport)
+	assert.Equal(t, 10, tr.MaxIdleConnsPerHost)
+	assert.Equal(t, 100, tr.MaxIdleConns)
+	assert.Equal(t, 90*time.Second, tr.IdleConnTimeout)
+	require.NotNil(t, tr.TLSClientConfig)
+	assert.Equal(t, "api.internal", tr.TLSClientConfig.ServerName)
+	assert.Len(t, tr.TLSClientConfig.Certificates, 1)
+	assert.NotNil(t, tr.TLSClientConfig.RootCAs)
+
+	c, err = NewClient(p, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, c.Transport.(*http.Transport).TLSClientConfig, "Expected no TLS configuration by default")
+}
+
+func TestNewTLSConfigErrors(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "httpconfig")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	empty := filepath.Join(dir, "empty.pem")
+	require.NoError(t, ioutil.WriteFile(empty, nil, 0600))
+
+	tests := map[string]string{
+		"tls: {caFile: " + empty + "}":           "no certificates in",
+		"tls: {caFile: " + dir + "/missing.pem}": "missing.pem",
+		"tls: {certFile: cert.pem}":              "certFile and keyFile must be set together",
+		"tls: {insecureSkipVerify: maybe}":       `for key "tls.insecureSkipVerify"`,
+	}
+
+	for src, msg := range tests {
+		p, err := config.NewYAMLProviderFromBytes([]byte(src))
+		require.NoError(t, err, "Can't create a YAML provider")
+
+		_, err = NewTLSConfig(p, "tls")
+		require.Error(t, err, "Expected an error for %q", src)
+		assert.Contains(t, err.Error(), msg)
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package httpconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/config"
+)
+
+// A Rotator serves a certificate that can be rotated without restarts,
+// either when its files change or when new settings are provided, e.g.
+// after configuration is reloaded:
+//
+// 	r, err := httpconfig.NewRotator(p, "server.tls")
+// 	...
+// 	stop := r.Watch(time.Minute, logError)
+// 	defer stop()
+// 	server.TLSConfig = r.Config()
+//
+// It is safe for concurrent use. Rotated certificates are served by servers
+// and, since Go 1.8, presented by clients to servers requiring them. Rotated
+// authorities verify certificates of clients on servers since Go 1.8, and
+// certificates of servers on clients since Go 1.15, earlier clients verify
+// servers with the authorities at the time Config is called.
+type Rotator struct {
+	reload   sync.Mutex // serializes updates and reloads
+	mu       sync.RWMutex
+	settings TLS
+	base     *tls.Config
+	cert     *tls.Certificate
+	modTimes [3]time.Time
+}
+
+// NewRotator loads a certificate with the settings from the key.
+func NewRotator(p config.Provider, key string) (*Rotator, error) {
+	var t TLS
+	if err := p.Get(key).Populate(&t); err != nil {
+		return nil, err
+	}
+
+	r := &Rotator{}
+	if err := r.Update(t); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Update replaces the settings and loads the certificate with them. The
+// current certificate is kept on errors.
+func (r *Rotator) Update(t TLS) error {
+	r.reload.Lock()
+	defer r.reload.Unlock()
+	return r.update(t)
+}
+
+func (r *Rotator) update(t TLS) error {
+	modTimes, err := t.modTimes()
+	if err != nil {
+		return err
+	}
+
+	base, err := t.Config()
+	if err != nil {
+		return err
+	}
+
+	if base == nil || len(base.Certificates) == 0 {
+		return errors.New("certificate is missing")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.settings, r.base, r.cert, r.modTimes = t, base, &base.Certificates[0], modTimes
+	return nil
+}
+
+// Reload loads the certificate and the authorities again if modification
+// times of their files changed and reports whether it did. The current certificate is kept on
+// errors.
+func (r *Rotator) Reload() (bool, error) {
+	// Updates wait for the reload, so it can't revert them to the settings
+	// it started with.
+	r.reload.Lock()
+	defer r.reload.Unlock()
+
+	r.mu.RLock()
+	t, old := r.settings, r.modTimes
+	r.mu.RUnlock()
+
+	modTimes, err := t.modTimes()
+	if err != nil || modTimes == old {
+		return false, err
+	}
+
+	return true, r.update(t)
+}
+
+// Watch calls Reload with the interval, a minute if it isn't positive, until
+// the returned function is called. Errors are passed to the function, if it
+// isn't nil.
+func (r *Rotator) Watch(interval time.Duration, onError func(error)) (stop func()) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, err := r.Reload(); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// GetCertificate returns the current certificate, it is meant for the field
+// of tls.Config.
+func (r *Rotator) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// rootCAs returns the current authorities, or nil if system ones are used.
+func (r *Rotator) rootCAs() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.base.RootCAs
+}
+
+// Config returns a TLS configuration, that serves the current certificate
+// and presents it to servers requesting certificates of clients.
+func (r *Rotator) Config() *tls.Config {
+	r.mu.RLock()
+	cfg := &tls.Config{
+		RootCAs:            r.base.RootCAs,
+		ServerName:         r.base.ServerName,
+		InsecureSkipVerify: r.base.InsecureSkipVerify,
+	}
+	r.mu.RUnlock()
+
+	cfg.GetCertificate = r.GetCertificate
+	r.configureClient(cfg)
+	return cfg
+}
+
+// modTimes returns modification times of the authorities and certificate
+// files, which are zero for inline ones.
+func (t TLS) modTimes() ([3]time.Time, error) {
+	var res [3]time.Time
+	for i, name := range []string{t.CAFile, t.CertFile, t.KeyFile} {
+		if name == "" {
+			continue
+		}
+
+		info, err := os.Stat(name)
+		if err != nil {
+			return res, err
+		}
+
+		res[i] = info.ModTime()
+	}
+
+	return res, nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.8
+// +build go1.8
+
+package httpconfig
+
+import "crypto/tls"
+
+// GetClientCertificate returns the current certificate, it is meant for the
+// field of tls.Config.
+func (r *Rotator) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.GetCertificate(nil)
+}
+
+func (r *Rotator) configureClient(cfg *tls.Config) {
+	cfg.GetClientCertificate = r.GetClientCertificate
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		return r.serverConfig(cfg), nil
+	}
+
+	r.verifyServers(cfg)
+}
+
+// serverConfig returns a copy of the configuration for a connection of
+// a client, that verifies clients with the current authorities, unless
+// the configuration sets other ones.
+func (r *Rotator) serverConfig(cfg *tls.Config) *tls.Config {
+	c := cfg.Clone()
+	c.GetConfigForClient = nil
+	withoutServerVerification(c)
+	if cfg.ClientCAs == nil {
+		c.ClientCAs = r.rootCAs()
+	}
+
+	return c
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.8
+// +build go1.8
+
+package httpconfig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"go.uber.org/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatorClient(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "httpconfig")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := writeCert(t, dir)
+	p, err := config.NewYAMLProviderFromBytes([]byte(fmt.Sprintf("tls: {certFile: %s, keyFile: %s}", certFile, keyFile)))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	r, err := NewRotator(p, "tls")
+	require.NoError(t, err)
+
+	cfg := r.Config()
+	require.NotNil(t, cfg.GetClientCertificate, "Expected clients to present the certificate")
+
+	served, err := cfg.GetCertificate(nil)
+	require.NoError(t, err)
+	presented, err := cfg.GetClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, served, presented)
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package httpconfig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"go.uber.org/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotator(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "httpconfig")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := writeCert(t, dir)
+	p, err := config.NewYAMLProviderFromBytes([]byte(fmt.Sprintf(
+		"tls: {certFile: %s, keyFile: %s, serverName: api}", certFile, keyFile)))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	r, err := NewRotator(p, "tls")
+	require.NoError(t, err)
+
+	cfg := r.Config()
+	assert.Equal(t, "api", cfg.ServerName)
+	first, err := cfg.GetCertificate(nil)
+	require.NoError(t, err)
+
+	changed, err := r.Reload()
+	require.NoError(t, err)
+	assert.False(t, changed, "Expected no changes without modifications")
+
+	writeCert(t, dir)
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+
+	changed, err = r.Reload()
+	require.NoError(t, err)
+	assert.True(t, changed, "Expected a new certificate")
+
+	second, err := cfg.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Certificate, second.Certificate, "Expected the certificate to rotate")
+
+	require.NoError(t, os.Remove(keyFile))
+	_, err = r.Reload()
+	require.Error(t, err)
+	current, err := cfg.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, second, current, "Expected the certificate to be kept on errors")
+}
+
+func TestRotatorUpdate(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "httpconfig")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := writeCert(t, dir)
+	cert, err := ioutil.ReadFile(certFile)
+	require.NoError(t, err)
+	key, err := ioutil.ReadFile(keyFile)
+	require.NoError(t, err)
+
+	p, err := config.NewYAMLProviderFromBytes([]byte(fmt.Sprintf("tls: {certFile: %s, keyFile: %s}", certFile, keyFile)))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	r, err := NewRotator(p, "tls")
+	require.NoError(t, err)
+
+	writeCert(t, dir)
+	require.NoError(t, r.Update(TLS{Cert: string(cert), Key: string(key)}))
+	current, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+
+	first, err := TLS{Cert: string(cert), Key: string(key)}.certificate()
+	require.NoError(t, err)
+	assert.Equal(t, first.Certificate, current.Certificate)
+
+	assert.Error(t, r.Update(TLS{Cert: string(cert)}), "Expected an error without a key")
+	assert.Error(t, r.Update(TLS{ServerName: "api"}), "Expected an error without a certificate")
+
+	_, err = NewRotator(config.NopProvider{}, "tls")
+	assert.Error(t, err)
+}
+
+func TestRotatorUpdateDuringReloads(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "httpconfig")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := writeCert(t, dir)
+	cert, err := ioutil.ReadFile(certFile)
+	require.NoError(t, err)
+	key, err := ioutil.ReadFile(keyFile)
+	require.NoError(t, err)
+
+	p, err := config.NewYAMLProviderFromBytes([]byte(fmt.Sprintf("tls: {certFile: %s, keyFile: %s}", certFile, keyFile)))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	r, err := NewRotator(p, "tls")
+	require.NoError(t, err)
+
+	// Keep files changing, so every reload loads them again.
+	done := make(chan struct{})
+	reloaded := make(chan struct{})
+	go func() {
+		defer close(reloaded)
+		for i := 1; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			mtime := time.Now().Add(time.Duration(i) * time.Second)
+			if os.Chtimes(certFile, mtime, mtime) == nil {
+				r.Reload()
+			}
+		}
+	}()
+
+	files := TLS{CertFile: certFile, KeyFile: keyFile}
+	inline := TLS{Cert: string(cert), Key: string(key)}
+	for i := 0; i < 20; i++ {
+		require.NoError(t, r.Update(files))
+		require.NoError(t, r.Update(inline))
+
+		// Let reloads started with the files finish.
+		time.Sleep(5 * time.Millisecond)
+		r.mu.RLock()
+		settings := r.settings
+		r.mu.RUnlock()
+		require.Equal(t, inline, settings, "Reloads must not revert updates")
+	}
+
+	close(done)
+	<-reloaded
+}
+
+func TestRotatorWatch(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "httpconfig")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := writeCert(t, dir)
+	p, err := config.NewYAMLProviderFromBytes([]byte(fmt.Sprintf("tls: {certFile: %s, keyFile: %s}", certFile, keyFile)))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	r, err := NewRotator(p, "tls")
+	require.NoError(t, err)
+
+	errs := make(chan error, 10)
+	stop := r.Watch(time.Millisecond, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+	defer stop()
+
+	require.NoError(t, os.Remove(certFile))
+	select {
+	case err := <-errs:
+		assert.Contains(t, err.Error(), "cert.pem")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected an error of a reload")
+	}
+
+	stop()
+	stop()
+
+	r.Watch(0, nil)()
+}
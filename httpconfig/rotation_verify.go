@@ -0,0 +1,71 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.15
+// +build go1.15
+
+package httpconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+)
+
+// verifyServers makes clients verify servers with the current authorities.
+// Verification is done in VerifyConnection the way crypto/tls does it, so
+// the default one is skipped.
+func (r *Rotator) verifyServers(cfg *tls.Config) {
+	if cfg.InsecureSkipVerify {
+		return
+	}
+
+	cfg.InsecureSkipVerify = true
+	cfg.VerifyConnection = r.verifyServer
+}
+
+func (r *Rotator) verifyServer(cs tls.ConnectionState) error {
+	if cs.ServerName == "" {
+		return errors.New("serverName must be set to verify servers")
+	}
+
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server presented no certificates")
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         r.rootCAs(),
+		DNSName:       cs.ServerName,
+		Intermediates: x509.NewCertPool(),
+	}
+
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// withoutServerVerification removes verification of servers from
+// a configuration of a server.
+func withoutServerVerification(cfg *tls.Config) {
+	cfg.VerifyConnection = nil
+}
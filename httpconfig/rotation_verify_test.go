@@ -0,0 +1,122 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.15
+// +build go1.15
+
+package httpconfig
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// handshake connects a client with the configuration to a server with
+// the certificate.
+func handshake(cfg *tls.Config, cert tls.Certificate) error {
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	conn, err := net.DialTimeout("tcp", ln.Addr().String(), time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return tls.Client(conn, cfg).Handshake()
+}
+
+func TestRotatorRootCAs(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "httpconfig")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, sub := range []string{"old", "new"} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, sub), 0700))
+	}
+
+	oldCert, oldKey := writeCert(t, filepath.Join(dir, "old"))
+	newCert, newKey := writeCert(t, filepath.Join(dir, "new"))
+	oldPair, err := tls.LoadX509KeyPair(oldCert, oldKey)
+	require.NoError(t, err)
+	newPair, err := tls.LoadX509KeyPair(newCert, newKey)
+	require.NoError(t, err)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	pem, err := ioutil.ReadFile(oldCert)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(caFile, pem, 0600))
+
+	p, err := config.NewYAMLProviderFromBytes([]byte(fmt.Sprintf(
+		"tls: {caFile: %s, certFile: %s, keyFile: %s, serverName: test}", caFile, oldCert, oldKey)))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	r, err := NewRotator(p, "tls")
+	require.NoError(t, err)
+
+	cfg := r.Config()
+	require.NoError(t, handshake(cfg, oldPair))
+	require.Error(t, handshake(cfg, newPair), "Expected servers of other authorities to fail")
+
+	pem, err = ioutil.ReadFile(newCert)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(caFile, pem, 0600))
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(caFile, future, future))
+
+	changed, err := r.Reload()
+	require.NoError(t, err)
+	assert.True(t, changed, "Expected changes of authorities to be reloaded")
+	assert.NoError(t, handshake(cfg, newPair), "Expected rotated authorities to verify servers")
+	assert.Error(t, handshake(cfg, oldPair), "Expected old authorities to be dropped")
+
+	server, err := cfg.GetConfigForClient(nil)
+	require.NoError(t, err)
+	assert.Nil(t, server.VerifyConnection, "Servers shouldn't verify clients as servers")
+	assert.Equal(t, r.rootCAs(), server.ClientCAs, "Expected clients to be verified with the authorities")
+
+	withoutName := r.Config()
+	withoutName.ServerName = ""
+	assert.Error(t, handshake(withoutName, newPair), "Expected an error without a server name")
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// _includeKey is a top level key listing files to include.
+const _includeKey = "_include"
+
+// NewYAMLProviderFromFilesWithIncludes creates a configuration provider from
+// a set of YAML file names, like NewYAMLProviderFromFiles does, and resolves
+// include directives in them. A file can include other files by listing them
+// under the top level _include key, e.g.
+//
+// 	_include:
+// 	  - common/db.yaml
+// 	  - common/logging.yaml
+//
+// Relative paths are resolved relative to the directory of the including file
+// and a leading ~ is expanded with ExpandPath.
+// Included files are merged in the order they are listed and the including
+// file overrides them. Included files can include other files too, but
+// cycles are reported as errors.
+func NewYAMLProviderFromFilesWithIncludes(files ...string) (Provider, error) {
+	return NewYAML(Includes(files...))
+}
+
+// Includes adds YAML files to read configuration from, resolving include
+// directives in them, see NewYAMLProviderFromFilesWithIncludes. Other
+// options apply to the included files too.
+func Includes(files ...string) YAMLOption {
+	return func(o *yamlOptions) {
+		for _, file := range files {
+			o.sources = append(o.sources, yamlSource{file: file, includes: true})
+		}
+	}
+}
+
+// includedFile is a file read while include directives are resolved.
+type includedFile struct {
+	name    string
+	content []byte
+}
+
+// resolveIncludes reads a file and the files it includes, up to the limit
+// of bytes each, and returns them in the order they are merged: included
+// files before the including one. The stack holds absolute names of the
+// including files.
+func resolveIncludes(file string, stack []string, limit int64) ([]includedFile, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range stack {
+		if f == abs {
+			return nil, fmt.Errorf("include cycle detected: %s", strings.Join(append(stack, abs), " -> "))
+		}
+	}
+
+	reader, err := openFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := readLimited(reader, limit)
+	if cerr := reader.Close(); err == nil {
+		err = cerr
+	}
+
+	var curr interface{}
+	if err == nil {
+		err = unmarshalYAMLValue(newByteSource(content), &curr)
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "in file: %q", file)
+	}
+
+	var includes []string
+	if m, ok := curr.(map[interface{}]interface{}); ok {
+		if includes, err = includeList(m[_includeKey]); err != nil {
+			return nil, errors.Wrapf(err, "in file: %q", file)
+		}
+	}
+
+	// Copy the stack, so siblings can include the same files.
+	stack = append(stack[:len(stack):len(stack)], abs)
+
+	var res []includedFile
+	for _, include := range includes {
+		include = ExpandPath(include)
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(file), include)
+		}
+
+		files, err := resolveIncludes(include, stack, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, files...)
+	}
+
+	return append(res, includedFile{name: file, content: content}), nil
+}
+
+// withoutIncludes removes the include directive from a parsed file.
+func withoutIncludes(value interface{}) {
+	if m, ok := value.(map[interface{}]interface{}); ok {
+		delete(m, _includeKey)
+	}
+}
+
+// includeList converts a value of the include key to a list of file names.
+func includeList(val interface{}) ([]string, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		res := make([]string, len(v))
+		for i, f := range v {
+			s, ok := f.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must contain only file names, found %v", _includeKey, f)
+			}
+
+			res[i] = s
+		}
+
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("%s must be a file name or a list of file names, found %v", _includeKey, val)
+}
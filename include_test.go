@@ -0,0 +1,195 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withTempFiles creates files with the given content in a temporary directory.
+func withTempFiles(t *testing.T, files map[string]string, f func(dir string)) {
+	dir, err := ioutil.TempDir("", "config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for name, content := range files {
+		name = filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(name), 0700))
+		require.NoError(t, ioutil.WriteFile(name, []byte(content), 0600))
+	}
+
+	f(dir)
+}
+
+func TestNewYAMLProviderFromFilesWithIncludes(t *testing.T) {
+	t.Parallel()
+
+	withTempFiles(t, map[string]string{
+		"service.yaml": `
+_include:
+  - common/db.yaml
+  - common/logging.yaml
+db:
+  name: service
+`,
+		"common/db.yaml": `
+_include: defaults.yaml
+db:
+  host: localhost
+  name: common
+`,
+		"common/logging.yaml": `
+_include: defaults.yaml
+logging:
+  level: info
+`,
+		"common/defaults.yaml": `
+db:
+  port: 5432
+logging:
+  level: debug
+`,
+		"dev.yaml": `
+logging:
+  level: warn
+`,
+	}, func(dir string) {
+		p, err := NewYAMLProviderFromFilesWithIncludes(
+			filepath.Join(dir, "service.yaml"),
+			filepath.Join(dir, "dev.yaml"))
+		require.NoError(t, err, "Can't create a YAML provider")
+
+		assert.Equal(t, "localhost", p.Get("db.host").Value())
+		assert.Equal(t, "service", p.Get("db.name").Value())
+		assert.Equal(t, 5432, p.Get("db.port").Value())
+		assert.Equal(t, "warn", p.Get("logging.level").Value())
+		assert.False(t, p.Get(_includeKey).HasValue())
+	})
+}
+
+func TestNewYAMLProviderFromFilesWithIncludesAbsolutePath(t *testing.T) {
+	t.Parallel()
+
+	abs, err := filepath.Abs("./testdata/base.yaml")
+	require.NoError(t, err)
+
+	withTempFiles(t, map[string]string{
+		"main.yaml": "_include: " + abs + "\nvalue: main",
+	}, func(dir string) {
+		p, err := NewYAMLProviderFromFilesWithIncludes(filepath.Join(dir, "main.yaml"))
+		require.NoError(t, err, "Can't create a YAML provider")
+		assert.Equal(t, "main", p.Get("value").Value())
+		assert.Equal(t, "base_setting", p.Get("value_override").Value())
+	})
+}
+
+func TestNewYAMLProviderFromFilesWithIncludesErrors(t *testing.T) {
+	t.Parallel()
+
+	withTempFiles(t, map[string]string{
+		"a.yaml":        "_include: b.yaml",
+		"b.yaml":        "_include: [c.yaml]",
+		"c.yaml":        "_include: a.yaml",
+		"self.yaml":     "_include: self.yaml",
+		"missing.yaml":  "_include: nothing.yaml",
+		"bad-list.yaml": "_include: [1]",
+		"bad-type.yaml": "_include: {a: b}",
+		"invalid.yaml":  "\t",
+		"scalar.yaml":   "42",
+		"merge.yaml":    "_include: scalar.yaml\na: b",
+	}, func(dir string) {
+		tests := map[string]string{
+			"a.yaml":        "include cycle detected: " + filepath.Join(dir, "a.yaml"),
+			"self.yaml":     "self.yaml -> " + filepath.Join(dir, "self.yaml"),
+			"missing.yaml":  "nothing.yaml",
+			"bad-list.yaml": "_include must contain only file names, found 1",
+			"bad-type.yaml": "_include must be a file name or a list of file names",
+			"invalid.yaml":  "invalid.yaml",
+			"merge.yaml":    "can't merge",
+			"none.yaml":     "none.yaml",
+		}
+
+		for file, msg := range tests {
+			_, err := NewYAMLProviderFromFilesWithIncludes(filepath.Join(dir, file))
+			require.Error(t, err, "Expected an error for %q", file)
+			assert.Contains(t, err.Error(), msg, "Unexpected error for %q", file)
+		}
+
+		p, err := NewYAMLProviderFromFilesWithIncludes(filepath.Join(dir, "scalar.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, 42, p.Get(Root).Value())
+	})
+}
+
+func TestIncludesWithOptions(t *testing.T) {
+	t.Parallel()
+
+	withTempFiles(t, map[string]string{
+		"main.yaml":   "_include: common.yaml\nname: ${NAME}",
+		"common.yaml": "host: ${HOST:localhost}\nport: 80",
+		"dup.yaml":    "_include: common.yaml\na: 1\na: 2",
+		"large.yaml":  "_include: common.yaml\nvalue: " + strings.Repeat("a", 1024),
+	}, func(dir string) {
+		lookup := func(key string) (string, bool) {
+			return strings.ToLower(key), key == "NAME"
+		}
+
+		p, err := NewYAML(
+			Includes(filepath.Join(dir, "main.yaml")),
+			Source(strings.NewReader("port: 8080")),
+			WithExpand(lookup),
+		)
+		require.NoError(t, err, "Can't create a YAML provider")
+		assert.Equal(t, "name", p.Get("name").Value())
+		assert.Equal(t, "localhost", p.Get("host").Value(), "Included files must be expanded")
+		assert.Equal(t, 8080, p.Get("port").Value())
+		assert.False(t, p.Get(_includeKey).HasValue())
+		assert.Equal(t, filepath.Join(dir, "common.yaml"), p.Get("host").Metadata().File)
+
+		_, err = NewYAML(Includes(filepath.Join(dir, "dup.yaml")), WithStrict())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `duplicate key "a"`)
+
+		_, err = NewYAML(Includes(filepath.Join(dir, "large.yaml")), WithLimits(Limits{MaxFileSize: 64}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "large.yaml")
+		assert.Contains(t, err.Error(), "size of the source exceeds the limit of 64 bytes")
+	})
+}
+
+func TestIncludesKeepOtherSources(t *testing.T) {
+	t.Parallel()
+
+	withTempFiles(t, map[string]string{
+		"main.yaml": "_include: []\na: 1",
+	}, func(dir string) {
+		p, err := NewYAML(Includes(filepath.Join(dir, "main.yaml")), Source(strings.NewReader("_include: kept")))
+		require.NoError(t, err, "Can't create a YAML provider")
+		assert.Equal(t, "kept", p.Get(_includeKey).Value(), "Only include directives of included files are removed")
+	})
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import "errors"
+
+// Lookup returns a configuration value by key.
+type Lookup func(key string) Value
+
+// Interceptor wraps lookups of values for cross-cutting concerns, e.g.
+// logging, metrics or redaction, like HTTP middleware wraps handlers.
+type Interceptor func(next Lookup) Lookup
+
+// NewProviderWithInterceptors creates a provider, where lookups of values go
+// through the interceptors, e.g.
+//
+// 	logging := func(next config.Lookup) config.Lookup {
+// 		return func(key string) config.Value {
+// 			v := next(key)
+// 			log.Printf("config lookup %q, found: %v", key, v.HasValue())
+// 			return v
+// 		}
+// 	}
+//
+// 	p, err := config.NewProviderWithInterceptors(group, logging)
+//
+// The first interceptor is the outermost one. Lookups of values, that
+// Populate makes for fields, go through the interceptors too.
+func NewProviderWithInterceptors(p Provider, interceptors ...Interceptor) (Provider, error) {
+	if p == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	ip := &interceptedProvider{Provider: p}
+	ip.lookup = p.Get
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		if interceptors[i] == nil {
+			return nil, errors.New("received a nil interceptor")
+		}
+
+		ip.lookup = interceptors[i](ip.lookup)
+	}
+
+	return ip, nil
+}
+
+type interceptedProvider struct {
+	Provider
+
+	lookup Lookup
+}
+
+func (p *interceptedProvider) Get(key string) Value {
+	v := p.lookup(key)
+
+	// Route lookups of children and fields through the interceptors too.
+	v.provider = p
+	v.root = nil
+	return v
+}
+
+func (p *interceptedProvider) separator() string {
+	return separatorOf(p.Provider)
+}
+
+// Healthy returns health of the underlying provider.
+func (p *interceptedProvider) Healthy() error {
+	return Healthy(p.Provider)
+}
+
+// Metadata returns metadata of the underlying provider.
+func (p *interceptedProvider) Metadata(key string) Metadata {
+	return metadataOf(p.Provider, key)
+}
+
+// Describe returns the description of the underlying provider.
+func (p *interceptedProvider) Describe() Description {
+	return Describe(p.Provider)
+}
+
+func (p *interceptedProvider) keyOrder() *keyOrder {
+	return keyOrderOf(p.Provider)
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProviderWithInterceptors(t *testing.T) {
+	t.Parallel()
+
+	base, err := NewYAMLProviderFromBytes([]byte(`
+db:
+  user: admin
+  password: secret
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	pg, err := NewProviderGroup("group", base)
+	require.NoError(t, err)
+
+	var calls []string
+	record := func(name string) Interceptor {
+		return func(next Lookup) Lookup {
+			return func(key string) Value {
+				calls = append(calls, name+":"+key)
+				return next(key)
+			}
+		}
+	}
+
+	redact := func(next Lookup) Lookup {
+		return func(key string) Value {
+			v := next(key)
+			if strings.HasSuffix(strings.ToLower(key), "password") && v.HasValue() {
+				return NewValue(v.provider, key, "***", true)
+			}
+
+			return v
+		}
+	}
+
+	p, err := NewProviderWithInterceptors(pg, record("outer"), record("inner"), redact)
+	require.NoError(t, err)
+
+	assert.Equal(t, "***", p.Get("db.password").Value())
+	assert.Equal(t, []string{"outer:db.password", "inner:db.password"}, calls)
+
+	assert.Equal(t, "***", p.Get("db").Get("password").Value(), "Children should be intercepted")
+
+	var cfg struct {
+		User     string
+		Password string
+	}
+
+	calls = nil
+	require.NoError(t, p.Get("db").Populate(&cfg))
+	assert.Equal(t, "admin", cfg.User)
+	assert.Equal(t, "***", cfg.Password, "Populate should be intercepted")
+	assert.Contains(t, calls, "outer:db.Password")
+
+	assert.Equal(t, "group", p.Name())
+	assert.NoError(t, Healthy(p))
+	assert.Equal(t, "yaml", p.Get("db.user").Metadata().Source)
+}
+
+func TestNewProviderWithInterceptorsErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewProviderWithInterceptors(nil)
+	assert.Error(t, err)
+
+	_, err = NewProviderWithInterceptors(NopProvider{}, nil)
+	assert.Error(t, err)
+
+	p, err := NewProviderWithInterceptors(NopProvider{})
+	require.NoError(t, err)
+	assert.Nil(t, p.Get("a").Value())
+}
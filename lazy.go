@@ -0,0 +1,102 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import "sync"
+
+// WithLazyLoad defers opening and parsing the sources until a value is
+// looked up for the first time, e.g. so subcommands of a CLI, that never
+// read configuration, don't pay for parsing it. Options are still checked
+// by NewYAML, but errors of loading the sources are reported later: values
+// are missing then, and Populate of the values, also of provider groups
+// including the provider, and Healthy of the provider return the errors.
+func WithLazyLoad() YAMLOption {
+	return func(o *yamlOptions) {
+		o.lazy = true
+	}
+}
+
+// lazyProvider loads YAML sources on the first call of a method.
+type lazyProvider struct {
+	settings yamlSettings
+	sources  []yamlSource
+
+	once sync.Once
+	p    Provider
+	err  error
+}
+
+func (l *lazyProvider) load() (Provider, error) {
+	l.once.Do(func() {
+		l.p, l.err = loadYAMLSources(l.settings, l.sources)
+		l.sources = nil
+	})
+
+	return l.p, l.err
+}
+
+func (l *lazyProvider) Name() string {
+	return "yaml"
+}
+
+func (l *lazyProvider) Get(key string) Value {
+	p, err := l.load()
+	if err != nil {
+		v := NewValue(l, key, nil, false)
+		v.err = err
+		return v
+	}
+
+	return p.Get(key)
+}
+
+// Healthy loads the sources and returns errors of loading them.
+func (l *lazyProvider) Healthy() error {
+	p, err := l.load()
+	if err != nil {
+		return err
+	}
+
+	return Healthy(p)
+}
+
+// Metadata returns metadata of the loaded provider.
+func (l *lazyProvider) Metadata(key string) Metadata {
+	p, err := l.load()
+	if err != nil {
+		return Metadata{Source: l.Name()}
+	}
+
+	return metadataOf(p, key)
+}
+
+func (l *lazyProvider) separator() string {
+	return separatorOrDefault(l.settings.separator)
+}
+
+func (l *lazyProvider) keyOrder() *keyOrder {
+	p, err := l.load()
+	if err != nil {
+		return nil
+	}
+
+	return keyOrderOf(p)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLazyLoad(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestWithLazyLoad")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.yaml")
+	p, err := NewYAML(File(file), WithLazyLoad(), WithSeparator("/"))
+	require.NoError(t, err, "Sources shouldn't be read before the first Get")
+	assert.Equal(t, "yaml", p.Name())
+	assert.Equal(t, "/", separatorOf(p))
+
+	require.NoError(t, ioutil.WriteFile(file, []byte("a: {b: 1}"), 0644))
+	assert.Equal(t, 1, p.Get("a/b").Value())
+	assert.Equal(t, 1, p.Get("a").Get("b").Value())
+	assert.Equal(t, file, p.Get("a/b").Metadata().File)
+	assert.NoError(t, Healthy(p))
+
+	require.NoError(t, ioutil.WriteFile(file, []byte("a: {b: 2}"), 0644))
+	assert.Equal(t, 1, p.Get("a/b").Value(), "Sources should be read once")
+}
+
+func TestWithLazyLoadErrors(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAML(File("./testdata/missing.yaml"), WithLazyLoad())
+	require.NoError(t, err)
+	assert.False(t, p.Get("a").HasValue())
+
+	err = Healthy(p)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing.yaml")
+
+	var cfg struct {
+		A string `default:"default"`
+	}
+	err = p.Get(Root).Populate(&cfg)
+	require.Error(t, err, "Populate should fail instead of using defaults")
+	assert.Contains(t, err.Error(), "missing.yaml")
+	assert.Error(t, p.Get("a").Get("b").Populate(&cfg.A), "Values of children should fail too")
+
+	static, err := NewStaticProvider(map[string]string{"a": "static"})
+	require.NoError(t, err)
+	group, err := NewProviderGroup("group", static, p)
+	require.NoError(t, err)
+	assert.Equal(t, "static", group.Get("a").Value())
+	assert.Error(t, group.Get(Root).Populate(&cfg), "Groups should fail too")
+
+	_, err = NewYAML(WithLazyLoad(), WithStrict(), WithUnmarshaler(func([]byte, interface{}) error { return nil }))
+	assert.Error(t, err, "Options should be checked eagerly")
+}
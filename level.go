@@ -0,0 +1,96 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// A LevelBinding keeps a log level in sync with a configuration key, so
+// operators can change verbosity of a service by pushing configuration, e.g.
+//
+// 	level := new(slog.LevelVar) // or zap.NewAtomicLevel()
+// 	b, err := config.BindLevel(p, "logging.level", level)
+// 	...
+// 	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+// 	...
+// 	// After every configuration reload.
+// 	err = b.Update(reloaded)
+//
+// Levels are set with UnmarshalText, which *slog.LevelVar and
+// *zap.AtomicLevel implement.
+type LevelBinding struct {
+	key   string
+	level encoding.TextUnmarshaler
+
+	mu      sync.Mutex
+	current string
+}
+
+// BindLevel sets the level from the key of the provider, if it has a value,
+// and returns a binding to update it on reloads.
+func BindLevel(p Provider, key string, level encoding.TextUnmarshaler) (*LevelBinding, error) {
+	if level == nil {
+		return nil, errors.New("received a nil level")
+	}
+
+	b := &LevelBinding{key: key, level: level}
+	if err := b.Update(p); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Update sets the level from the key of a reloaded provider, if the value
+// changed. The level is kept if the value is missing or invalid.
+func (b *LevelBinding) Update(p Provider) error {
+	if p == nil {
+		return errors.New("received a nil provider")
+	}
+
+	v := p.Get(b.key)
+	if !v.HasValue() || v.IsNull() {
+		return nil
+	}
+
+	s, ok := v.Value().(string)
+	if !ok {
+		return errorWithKey(fmt.Errorf("can't convert %T to a log level", v.Value()), b.key)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if s == b.current {
+		return nil
+	}
+
+	if err := b.level.UnmarshalText([]byte(s)); err != nil {
+		return errorWithKey(err, b.key)
+	}
+
+	b.current = s
+	return nil
+}
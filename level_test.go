@@ -0,0 +1,86 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLevel counts updates of a level.
+type testLevel struct {
+	level   string
+	updates int
+}
+
+func (l *testLevel) UnmarshalText(text []byte) error {
+	switch s := strings.ToLower(string(text)); s {
+	case "debug", "info", "warn", "error":
+		l.level = s
+		l.updates++
+		return nil
+	}
+
+	return fmt.Errorf("unrecognized level: %q", text)
+}
+
+func TestBindLevel(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte("logging: {level: info}"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var level testLevel
+	b, err := BindLevel(p, "logging.level", &level)
+	require.NoError(t, err)
+	assert.Equal(t, testLevel{level: "info", updates: 1}, level)
+
+	require.NoError(t, b.Update(p))
+	assert.Equal(t, 1, level.updates, "Unchanged level shouldn't be set")
+
+	reloaded, err := NewYAMLProviderFromBytes([]byte("logging: {level: DEBUG}"))
+	require.NoError(t, err, "Can't create a YAML provider")
+	require.NoError(t, b.Update(reloaded))
+	assert.Equal(t, testLevel{level: "debug", updates: 2}, level)
+
+	broken, err := NewYAMLProviderFromBytes([]byte("logging: {level: loud}"))
+	require.NoError(t, err, "Can't create a YAML provider")
+	err = b.Update(broken)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `for key "logging.level": unrecognized level: "loud"`)
+	assert.Equal(t, "debug", level.level)
+
+	numeric, err := NewYAMLProviderFromBytes([]byte("logging: {level: 1}"))
+	require.NoError(t, err, "Can't create a YAML provider")
+	err = b.Update(numeric)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't convert int to a log level")
+
+	require.NoError(t, b.Update(NopProvider{}), "Missing level should be kept")
+	assert.Equal(t, "debug", level.level)
+
+	_, err = BindLevel(p, "logging.level", nil)
+	assert.EqualError(t, err, "received a nil level")
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"io"
+)
+
+// Limits bound resources used to load configuration from untrusted sources.
+// Only the sizes are checked before documents are decoded, the depth and the
+// number of keys are checked after it, so they don't protect from documents
+// expanding aliases exponentially, e.g. "billion laughs" ones. Zero fields
+// mean no limit.
+type Limits struct {
+	// MaxFileSize is the maximum size of a source in bytes.
+	MaxFileSize int64
+
+	// MaxExpandedSize is the maximum size of a source in bytes after
+	// expansion of variables with WithExpand.
+	MaxExpandedSize int64
+
+	// MaxDepth is the maximum nesting depth of mappings and sequences, e.g.
+	// 2 for `a: {b: c}`.
+	MaxDepth int
+
+	// MaxKeys is the maximum number of map keys and sequence elements in
+	// a source after expansion of aliases.
+	MaxKeys int
+}
+
+// WithLimits fails to create a provider if any of the sources exceeds the
+// limits.
+func WithLimits(limits Limits) YAMLOption {
+	return func(o *yamlOptions) {
+		o.settings.limits = limits
+	}
+}
+
+// limitReaders wraps readers to fail on reading more than n bytes, unless
+// n is zero. Names of files are kept for errors and metadata.
+func limitReaders(readers []io.Reader, n int64, what string) []io.Reader {
+	if n <= 0 {
+		return readers
+	}
+
+	res := make([]io.Reader, len(readers))
+	for i, r := range readers {
+		res[i] = &limitedReader{
+			r:   r,
+			n:   n,
+			err: fmt.Errorf("%s exceeds the limit of %d bytes", what, n),
+		}
+
+		if name, disk := readerName(r); name != "" {
+			res[i] = namedReader{Reader: res[i], name: name, disk: disk}
+		}
+	}
+
+	return res
+}
+
+// readLimited reads the reader to the end, failing if it has more than
+// n bytes, unless n is zero.
+func readLimited(r io.Reader, n int64) ([]byte, error) {
+	return readAll(limitReaders([]io.Reader{r}, n, "size of the source")[0])
+}
+
+// limitedReader is like io.LimitedReader, but fails instead of stopping
+// when the limit is exceeded.
+type limitedReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, l.err
+	}
+
+	// Read one more byte to tell sources of exactly n bytes from larger ones.
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.r.Read(p)
+	if l.n -= int64(n); l.n < 0 {
+		return 0, l.err
+	}
+
+	return n, err
+}
+
+// check checks the depth and the number of keys of a decoded source.
+func (l Limits) check(value interface{}) error {
+	if l.MaxDepth <= 0 && l.MaxKeys <= 0 {
+		return nil
+	}
+
+	keys := 0
+	var walk func(value interface{}, depth int) error
+	walk = func(value interface{}, depth int) error {
+		var children []interface{}
+		switch v := value.(type) {
+		case map[interface{}]interface{}:
+			for _, child := range v {
+				children = append(children, child)
+			}
+		case []interface{}:
+			children = v
+		default:
+			return nil
+		}
+
+		if l.MaxDepth > 0 && depth > l.MaxDepth {
+			return fmt.Errorf("nesting depth exceeds the limit of %d", l.MaxDepth)
+		}
+
+		if keys += len(children); l.MaxKeys > 0 && keys > l.MaxKeys {
+			return fmt.Errorf("number of keys exceeds the limit of %d", l.MaxKeys)
+		}
+
+		for _, child := range children {
+			if err := walk(child, depth+1); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return walk(value, 1)
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLimits(t *testing.T) {
+	t.Parallel()
+
+	const src = "a:\n  b: [1, 2]\nc: ${VAR}\n"
+	lookup := func(string) (string, bool) { return strings.Repeat("x", 100), true }
+
+	tests := []struct {
+		msg    string
+		limits Limits
+		err    string
+	}{
+		{
+			msg:    "within limits",
+			limits: Limits{MaxFileSize: int64(len(src)), MaxExpandedSize: 200, MaxDepth: 3, MaxKeys: 5},
+		},
+		{
+			msg:    "file size",
+			limits: Limits{MaxFileSize: int64(len(src)) - 1},
+			err:    "size of the source exceeds the limit of 24 bytes",
+		},
+		{
+			msg:    "expanded size",
+			limits: Limits{MaxExpandedSize: 100},
+			err:    "expanded size of the source exceeds the limit of 100 bytes",
+		},
+		{
+			msg:    "depth",
+			limits: Limits{MaxDepth: 2},
+			err:    "nesting depth exceeds the limit of 2",
+		},
+		{
+			msg:    "keys",
+			limits: Limits{MaxKeys: 4},
+			err:    "number of keys exceeds the limit of 4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			p, err := NewYAML(
+				Source(bytes.NewBufferString(src)),
+				WithExpand(lookup),
+				WithLimits(tt.limits),
+			)
+
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, 2, p.Get("a.b.1").Value())
+		})
+	}
+}
+
+func TestWithLimitsKeepsFileNames(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewYAML(File("testdata/fs/base.yaml"), WithLimits(Limits{MaxFileSize: 1}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `in file: "testdata/fs/base.yaml"`)
+
+	p, err := NewYAML(File("testdata/fs/base.yaml"), WithLimits(Limits{MaxFileSize: 1 << 10}))
+	require.NoError(t, err)
+	assert.Contains(t, p.Get("a").Metadata().File, "base.yaml")
+}
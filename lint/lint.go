@@ -0,0 +1,252 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package lint checks configuration beyond parsing with pluggable rules,
+// e.g. naming conventions, banned keys or units of durations:
+//
+// 	issues := lint.Lint(p, lint.BannedKeys("db.password"), lint.DurationUnits("timeout"))
+// 	lint.Log(logger, issues)
+//
+// Services can log issues at startup and the configlint command reports them
+// for files in CI. Keys of issues are joined with dots.
+package lint // import "go.uber.org/config/lint"
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/config"
+)
+
+// Severity of an issue.
+type Severity int
+
+const (
+	// Info issues are suggestions.
+	Info Severity = iota
+
+	// Warning issues should be fixed.
+	Warning
+
+	// Error issues must be fixed, e.g. the configlint command fails on them.
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// A Node is a value of the configuration tree checked by rules.
+type Node struct {
+	// Key of the value, empty for the root.
+	Key string
+
+	// Name is the map key of the value, empty for the root and sequence
+	// elements.
+	Name string
+
+	Value interface{}
+}
+
+// A Rule checks every node of the configuration tree, including mappings
+// and sequences.
+type Rule struct {
+	Name     string
+	Severity Severity
+
+	// Check returns a description of a problem with the node, or an empty
+	// string if there is none.
+	Check func(Node) string
+}
+
+// An Issue is a problem reported by a rule.
+type Issue struct {
+	Key      string
+	Rule     string
+	Severity Severity
+	Message  string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%v: %s: %s (%s)", i.Severity, i.Key, i.Message, i.Rule)
+}
+
+// Lint checks all the nodes of the provider's configuration with the rules
+// and returns issues ordered by keys.
+func Lint(p config.Provider, rules ...Rule) []Issue {
+	var issues []Issue
+	var walk func(n Node)
+	walk = func(n Node) {
+		for _, r := range rules {
+			if msg := r.Check(n); msg != "" {
+				issues = append(issues, Issue{Key: n.Key, Rule: r.Name, Severity: r.Severity, Message: msg})
+			}
+		}
+
+		switch v := n.Value.(type) {
+		case map[interface{}]interface{}:
+			names := make([]string, 0, len(v))
+			values := make(map[string]interface{}, len(v))
+			for key, val := range v {
+				name := fmt.Sprint(key)
+				names = append(names, name)
+				values[name] = val
+			}
+
+			sort.Strings(names)
+			for _, name := range names {
+				walk(Node{Key: join(n.Key, name), Name: name, Value: values[name]})
+			}
+		case []interface{}:
+			for i, val := range v {
+				walk(Node{Key: join(n.Key, strconv.Itoa(i)), Value: val})
+			}
+		}
+	}
+
+	walk(Node{Value: p.Get(config.Root).Value()})
+	return issues
+}
+
+// Log reports issues to the logger, errors and warnings as warnings and
+// the rest as debug messages.
+func Log(l config.Logger, issues []Issue) {
+	for _, i := range issues {
+		if i.Severity >= Warning {
+			l.Warnf("config: %v", i)
+		} else {
+			l.Debugf("config: %v", i)
+		}
+	}
+}
+
+// NamingConvention warns about map keys that don't match the expression,
+// e.g. ^[a-z][a-z0-9_]*$ for snake case.
+func NamingConvention(re *regexp.Regexp) Rule {
+	return Rule{
+		Name:     "naming",
+		Severity: Warning,
+		Check: func(n Node) string {
+			if n.Name == "" || re.MatchString(n.Name) {
+				return ""
+			}
+
+			return fmt.Sprintf("name %q doesn't match %s", n.Name, re)
+		},
+	}
+}
+
+// BannedKeys reports errors for keys that must not be set, e.g. secrets
+// that belong in a secret store. Segments of keys equal to * match any
+// segment, e.g. clients.*.password.
+func BannedKeys(keys ...string) Rule {
+	return Rule{
+		Name:     "banned-keys",
+		Severity: Error,
+		Check: func(n Node) string {
+			for _, key := range keys {
+				if n.Key != "" && matches(key, n.Key) {
+					return "key is banned"
+				}
+			}
+
+			return ""
+		},
+	}
+}
+
+// DurationUnits reports errors for durations without units, e.g. a timeout
+// of 30 instead of 30s. Scalars are durations if their map keys end with
+// any of the suffixes in any case, e.g. "timeout" for read_timeout.
+func DurationUnits(suffixes ...string) Rule {
+	return Rule{
+		Name:     "duration-units",
+		Severity: Error,
+		Check: func(n Node) string {
+			if !hasSuffix(n.Name, suffixes) {
+				return ""
+			}
+
+			switch n.Value.(type) {
+			case map[interface{}]interface{}, []interface{}, nil:
+				return ""
+			}
+
+			s := fmt.Sprint(n.Value)
+			if _, err := time.ParseDuration(s); err == nil {
+				return ""
+			}
+
+			if _, err := strconv.ParseFloat(s, 64); err == nil {
+				return fmt.Sprintf("duration %s needs a unit, e.g. %ss", s, s)
+			}
+
+			return fmt.Sprintf("%q isn't a duration", s)
+		},
+	}
+}
+
+func hasSuffix(name string, suffixes []string) bool {
+	name = strings.ToLower(name)
+	for _, s := range suffixes {
+		if strings.HasSuffix(name, strings.ToLower(s)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matches checks a key against a pattern with * segments.
+func matches(pattern, key string) bool {
+	ps, ks := strings.Split(pattern, "."), strings.Split(key, ".")
+	if len(ps) != len(ks) {
+		return false
+	}
+
+	for i := range ps {
+		if ps[i] != "*" && ps[i] != ks[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func join(key, child string) string {
+	if key == "" {
+		return child
+	}
+
+	return key + "." + child
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package lint
+
+import (
+	"bytes"
+	"log"
+	"regexp"
+	"testing"
+
+	"go.uber.org/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	p, err := config.NewYAMLProviderFromBytes([]byte(`
+clients:
+  - name: users
+    password: secret
+    readTimeout: 30
+    write_timeout: 1s
+    idle_timeout: soon
+db:
+  connect_timeout: {}
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	issues := Lint(p,
+		NamingConvention(regexp.MustCompile(`^[a-z][a-z0-9_]*$`)),
+		BannedKeys("clients.*.password", "missing"),
+		DurationUnits("timeout"),
+	)
+
+	assert.Equal(t, []Issue{
+		{Key: "clients.0.idle_timeout", Rule: "duration-units", Severity: Error, Message: `"soon" isn't a duration`},
+		{Key: "clients.0.password", Rule: "banned-keys", Severity: Error, Message: "key is banned"},
+		{Key: "clients.0.readTimeout", Rule: "naming", Severity: Warning,
+			Message: `name "readTimeout" doesn't match ^[a-z][a-z0-9_]*$`},
+		{Key: "clients.0.readTimeout", Rule: "duration-units", Severity: Error,
+			Message: "duration 30 needs a unit, e.g. 30s"},
+	}, issues)
+
+	assert.Equal(t, "error: clients.0.password: key is banned (banned-keys)", issues[1].String())
+	assert.Empty(t, Lint(p), "Expected no issues without rules")
+}
+
+func TestLog(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	Log(config.StdLogger(log.New(&buf, "", 0)), []Issue{
+		{Key: "a", Rule: "r", Severity: Info, Message: "m"},
+		{Key: "b", Rule: "r", Severity: Error, Message: "m"},
+	})
+
+	assert.Equal(t, "DEBUG config: info: a: m (r)\nWARN config: error: b: m (r)\n", buf.String())
+}
+
+func TestSeverityString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "warning", Warning.String())
+	assert.Equal(t, "Severity(5)", Severity(5).String())
+}
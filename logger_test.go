@@ -0,0 +1,58 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	l := StdLogger(log.New(&buf, "", 0))
+	l.Debugf("loaded %q", "a.yaml")
+	l.Warnf("key %q is deprecated", "a")
+	assert.Equal(t, "DEBUG loaded \"a.yaml\"\nWARN key \"a\" is deprecated\n", buf.String())
+}
+
+func TestNewYAMLWithLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	p, err := NewYAML(
+		File("testdata/fs/base.yaml"),
+		Source(bytes.NewBufferString("b: source")),
+		WithLogger(StdLogger(log.New(&buf, "", 0))),
+	)
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "source", p.Get("b").Value())
+	assert.Equal(t, "DEBUG config: loaded YAML source 0 from \"testdata/fs/base.yaml\"\n"+
+		"DEBUG config: loaded YAML source 1\n", buf.String())
+
+	_, err = NewYAML(Source(bytes.NewBufferString("a: 1")), WithLogger(nil))
+	assert.NoError(t, err, "Expected nil loggers to discard messages")
+}
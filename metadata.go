@@ -0,0 +1,102 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import "time"
+
+// Metadata describes where a value comes from and how fresh it is.
+type Metadata struct {
+	// Source is a name of the provider the value comes from.
+	Source string
+
+	// LoadedAt is the time the value was loaded or last refreshed at, it is
+	// zero if unknown.
+	LoadedAt time.Time
+
+	// Version of the source, e.g. a revision in a remote store, if known.
+	Version string
+
+	// File is an absolute name of the file a scalar value is defined in, if
+	// known.
+	File string
+
+	// Description of the provider, if it is described, see
+	// NewDescribedProvider.
+	Description string
+}
+
+// MetadataReporter is implemented by providers that know when and from which
+// version of their sources values were loaded.
+type MetadataReporter interface {
+	// Metadata returns metadata of the value for the key.
+	Metadata(key string) Metadata
+}
+
+// Metadata returns metadata of the value. Providers that don't implement
+// the MetadataReporter interface report only their names.
+func (cv Value) Metadata() Metadata {
+	return metadataOf(cv.provider, cv.key)
+}
+
+func metadataOf(p Provider, key string) Metadata {
+	if p == nil {
+		return Metadata{}
+	}
+
+	if m, ok := p.(MetadataReporter); ok {
+		return m.Metadata(key)
+	}
+
+	return Metadata{Source: p.Name()}
+}
+
+// Metadata returns the time the YAML was loaded at and the file the value is
+// defined in.
+func (y yamlConfigProvider) Metadata(key string) Metadata {
+	return Metadata{Source: y.Name(), LoadedAt: y.loadedAt, File: y.file(key)}
+}
+
+// Metadata returns metadata of the last provider in the group that has
+// a value for the key, because its value overrides the others.
+func (p providerGroup) Metadata(key string) Metadata {
+	for i := len(p.providers) - 1; i >= 0; i-- {
+		if p.providers[i].Get(key).HasValue() {
+			return metadataOf(p.providers[i], key)
+		}
+	}
+
+	return Metadata{Source: p.Name()}
+}
+
+// Metadata returns metadata of the underlying provider.
+func (sp scopedProvider) Metadata(key string) Metadata {
+	return metadataOf(sp.Provider, sp.addPrefix(key))
+}
+
+// Metadata returns metadata of the underlying provider.
+func (p *cachedProvider) Metadata(key string) Metadata {
+	return metadataOf(p.Provider, key)
+}
+
+// Metadata returns metadata of the underlying provider.
+func (p hiddenKeyProvider) Metadata(key string) Metadata {
+	return metadataOf(p.Provider, key)
+}
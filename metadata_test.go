@@ -0,0 +1,102 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type versionedProvider struct {
+	staticProvider
+	version string
+}
+
+func (v versionedProvider) Name() string {
+	return "versioned"
+}
+
+func (v versionedProvider) Metadata(key string) Metadata {
+	return Metadata{Source: v.Name(), Version: v.version}
+}
+
+func TestYAMLMetadata(t *testing.T) {
+	t.Parallel()
+
+	before := time.Now()
+	p, err := NewYAMLProviderFromBytes([]byte("a: b"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	m := p.Get("a").Metadata()
+	assert.Equal(t, "yaml", m.Source)
+	assert.False(t, m.LoadedAt.Before(before))
+	assert.False(t, m.LoadedAt.After(time.Now()))
+	assert.Empty(t, m.Version)
+
+	assert.Equal(t, m, NewScopedProvider("a", p).Get(Root).Metadata())
+}
+
+func TestYAMLMetadataFile(t *testing.T) {
+	t.Parallel()
+
+	abs, err := filepath.Abs("testdata/fs/dev.yaml")
+	require.NoError(t, err)
+
+	p, err := NewYAML(File("testdata/fs/base.yaml"), Source(bytes.NewBufferString("a: reader")), File(abs))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	assert.Equal(t, abs, p.Get("b").Metadata().File)
+	assert.Empty(t, p.Get("a").Metadata().File, "Values from readers have no files")
+	assert.Empty(t, p.Get(Root).Metadata().File, "Maps have no files")
+	assert.Empty(t, p.Get("missing").Metadata().File)
+}
+
+func TestProviderGroupMetadata(t *testing.T) {
+	t.Parallel()
+
+	base, err := NewYAMLProviderFromBytes([]byte("a: base\nb: base"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	s, err := NewStaticProvider(map[string]string{"b": "remote"})
+	require.NoError(t, err, "Can't create a static provider")
+
+	remote := versionedProvider{staticProvider: s.(staticProvider), version: "42"}
+	pg, err := NewProviderGroup("group", base, remote)
+	require.NoError(t, err)
+
+	assert.Equal(t, Metadata{Source: "versioned", Version: "42"}, pg.Get("b").Metadata())
+	assert.Equal(t, "yaml", pg.Get("a").Metadata().Source)
+	assert.False(t, pg.Get("a").Metadata().LoadedAt.IsZero())
+	assert.Equal(t, Metadata{Source: "group"}, pg.Get("missing").Metadata())
+	assert.Equal(t, "42", NewScopedProvider("b", pg).Get(Root).Metadata().Version)
+}
+
+func TestMetadataWithoutReporter(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, Metadata{}, Value{}.Metadata())
+	assert.Equal(t, Metadata{Source: "NopProvider"}, NopProvider{}.Get("a").Metadata())
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// A Migration moves a value of a deprecated key to a new key, so old files
+// keep working after configuration layouts are restructured.
+type Migration struct {
+	// From is the deprecated key.
+	From string
+
+	// To is the key the value is moved to.
+	To string
+
+	// Transform converts the value, e.g. milliseconds to a duration string.
+	// The value is moved as is if the function is nil.
+	Transform func(interface{}) (interface{}, error)
+}
+
+// WithMigrations applies migrations to every source in order before it is
+// merged, e.g.
+//
+// 	config.NewYAML(
+// 		config.File("service.yaml"),
+// 		config.WithMigrations(config.Migration{From: "db.host", To: "storage.host"}),
+// 		config.WithLogger(logger),
+// 	)
+//
+// Every applied migration is reported to the logger of WithLogger as
+// a warning. Sources setting both the deprecated and the new key fail to
+// load.
+func WithMigrations(migrations ...Migration) YAMLOption {
+	return func(o *yamlOptions) {
+		o.settings.migrations = append(o.settings.migrations, migrations...)
+	}
+}
+
+// migrate applies the migrations to a parsed source.
+func migrate(value interface{}, migrations []Migration, sep string, name string, logger Logger) error {
+	root, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil
+	}
+
+	for _, m := range migrations {
+		old, ok := removeValue(root, splitKey(m.From, sep), sep)
+		if !ok {
+			continue
+		}
+
+		if m.Transform != nil {
+			var err error
+			if old, err = m.Transform(old); err != nil {
+				return errors.Wrapf(err, "can't migrate %q to %q", m.From, m.To)
+			}
+		}
+
+		if err := setValue(root, splitKey(m.To, sep), old, sep); err != nil {
+			return errors.Wrapf(err, "can't migrate %q to %q", m.From, m.To)
+		}
+
+		source := ""
+		if name != "" {
+			source = fmt.Sprintf(" in %q", name)
+		}
+
+		logger.Warnf("config: key %q%s is deprecated, use %q instead", m.From, source, m.To)
+	}
+
+	return nil
+}
+
+// removeValue removes a value from a tree of maps by the key segments and
+// returns it, if it is found.
+func removeValue(root map[interface{}]interface{}, segments []string, sep string) (interface{}, bool) {
+	node := root
+	for i, segment := range segments {
+		segment = unescapeSeparators(segment, sep)
+		child, ok := node[segment]
+		if !ok {
+			return nil, false
+		}
+
+		if i == len(segments)-1 {
+			delete(node, segment)
+			return child, true
+		}
+
+		if node, ok = child.(map[interface{}]interface{}); !ok {
+			return nil, false
+		}
+	}
+
+	return nil, false
+}
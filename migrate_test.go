@@ -0,0 +1,83 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMigrations(t *testing.T) {
+	t.Parallel()
+
+	toDuration := func(v interface{}) (interface{}, error) {
+		ms, ok := v.(int)
+		if !ok {
+			return nil, fmt.Errorf("expected milliseconds, got %v", v)
+		}
+
+		return fmt.Sprintf("%dms", ms), nil
+	}
+
+	migrations := WithMigrations(
+		Migration{From: "db.host", To: "storage.host"},
+		Migration{From: "db.timeout_ms", To: "storage.timeout", Transform: toDuration},
+		Migration{From: "missing", To: "other"},
+	)
+
+	var buf bytes.Buffer
+	p, err := NewYAML(
+		File("testdata/migrate.yaml"),
+		Source(bytes.NewBufferString("storage: {port: 5432}")),
+		migrations,
+		WithLogger(StdLogger(log.New(&buf, "", 0))),
+	)
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var storage struct {
+		Host    string
+		Port    int
+		Timeout string
+	}
+
+	require.NoError(t, p.Get("storage").Populate(&storage))
+	assert.Equal(t, "localhost", storage.Host)
+	assert.Equal(t, 5432, storage.Port)
+	assert.Equal(t, "100ms", storage.Timeout)
+	assert.Equal(t, map[interface{}]interface{}{"name": "users"}, p.Get("db").Value())
+	assert.Contains(t, buf.String(),
+		`WARN config: key "db.host" in "testdata/migrate.yaml" is deprecated, use "storage.host" instead`)
+	assert.Contains(t, buf.String(),
+		`WARN config: key "db.timeout_ms" in "testdata/migrate.yaml" is deprecated, use "storage.timeout" instead`)
+
+	_, err = NewYAML(Source(bytes.NewBufferString("db: {host: a}\nstorage: {host: b}")), migrations)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `can't migrate "db.host" to "storage.host": key conflicts with another key`)
+
+	_, err = NewYAML(Source(bytes.NewBufferString("db: {timeout_ms: 1s}")), migrations)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `can't migrate "db.timeout_ms" to "storage.timeout": expected milliseconds, got 1s`)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Module describes configuration of a module to populate with PopulateAll.
+type Module struct {
+	// Prefix is a key of the module configuration, e.g. "modules.http".
+	Prefix string
+
+	// Target is a pointer to populate with the configuration. Default values
+	// are set with the default tags of struct fields.
+	Target interface{}
+
+	// Validate checks the populated target, it is optional. Fields are
+	// validated with the validate tags before it is called.
+	Validate func() error
+}
+
+// moduleRegistry keeps modules in the order of registration.
+type moduleRegistry struct {
+	sync.Mutex
+
+	modules []Module
+}
+
+var _modules moduleRegistry
+
+// RegisterModule registers configuration of a module, so PopulateAll
+// populates it, e.g.
+//
+// 	var cfg struct {
+// 		Port int `default:"8080" validate:"min=1"`
+// 	}
+//
+// 	func init() {
+// 		config.RegisterModule(config.Module{Prefix: "modules.http", Target: &cfg})
+// 	}
+//
+// Registration is usually done in init functions.
+func RegisterModule(m Module) error {
+	return _modules.register(m)
+}
+
+// PopulateAll populates and validates configuration of all the registered
+// modules in the order of registration and reports errors of all of them at
+// once.
+func PopulateAll(p Provider) error {
+	return _modules.populateAll(p)
+}
+
+func (r *moduleRegistry) register(m Module) error {
+	if m.Target == nil {
+		return errors.New("received a nil target")
+	}
+
+	if v := reflect.ValueOf(m.Target); v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("target of module %q must be a non-nil pointer, got %T", m.Prefix, m.Target)
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	for _, registered := range r.modules {
+		if registered.Prefix == m.Prefix {
+			return fmt.Errorf("module %q is already registered", m.Prefix)
+		}
+	}
+
+	r.modules = append(r.modules, m)
+	return nil
+}
+
+func (r *moduleRegistry) populateAll(p Provider) error {
+	// Validate functions can register modules or call Preflight, so they
+	// run without the lock.
+	r.Lock()
+	modules := append([]Module(nil), r.modules...)
+	r.Unlock()
+
+	var msgs []string
+	for _, m := range modules {
+		err := p.Get(m.Prefix).Populate(m.Target)
+		if err == nil && m.Validate != nil {
+			err = m.Validate()
+		}
+
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("module %q: %v", m.Prefix, err))
+		}
+	}
+
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("can't populate modules: %s", strings.Join(msgs, "; "))
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleRegistry(t *testing.T) {
+	t.Parallel()
+
+	var http struct {
+		Port int    `default:"8080"`
+		Host string `validate:"nonzero"`
+	}
+
+	var rpc struct {
+		Port int `validate:"min=1"`
+	}
+
+	var db struct{ Name string }
+
+	var r moduleRegistry
+	require.NoError(t, r.register(Module{Prefix: "modules.http", Target: &http}))
+	require.NoError(t, r.register(Module{Prefix: "modules.rpc", Target: &rpc}))
+	require.NoError(t, r.register(Module{
+		Prefix: "db",
+		Target: &db,
+		Validate: func() error {
+			if db.Name == "" {
+				return errors.New("empty database name")
+			}
+
+			return nil
+		},
+	}))
+
+	assert.Error(t, r.register(Module{Prefix: "db", Target: &db}), "Duplicate prefix")
+	assert.Error(t, r.register(Module{Prefix: "nil"}))
+	assert.Error(t, r.register(Module{Prefix: "value", Target: db}))
+
+	p, err := NewYAMLProviderFromBytes([]byte("modules:\n  rpc:\n    port: 0"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	err = r.populateAll(p)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `module "modules.http":`)
+	assert.Contains(t, err.Error(), `module "modules.rpc":`)
+	assert.Contains(t, err.Error(), `module "db": empty database name`)
+
+	p, err = NewYAMLProviderFromBytes([]byte(`
+modules:
+  http:
+    host: localhost
+  rpc:
+    port: 9090
+db:
+  name: users
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	require.NoError(t, r.populateAll(p))
+	assert.Equal(t, 8080, http.Port)
+	assert.Equal(t, "localhost", http.Host)
+	assert.Equal(t, 9090, rpc.Port)
+	assert.Equal(t, "users", db.Name)
+}
+
+func TestModuleRegistryValidateRegisters(t *testing.T) {
+	t.Parallel()
+
+	var a, b struct{ Name string }
+	var r moduleRegistry
+	require.NoError(t, r.register(Module{
+		Prefix: "a",
+		Target: &a,
+		Validate: func() error {
+			// Would deadlock if the registry was locked during validation.
+			return r.register(Module{Prefix: "b", Target: &b})
+		},
+	}))
+
+	require.NoError(t, r.populateAll(NopProvider{}))
+	assert.Len(t, r.modules, 2)
+}
+
+func TestPopulateAll(t *testing.T) {
+	t.Parallel()
+
+	var cfg struct{ Value string }
+	require.NoError(t, RegisterModule(Module{Prefix: "TestPopulateAll", Target: &cfg}))
+
+	p, err := NewStaticProvider(map[string]interface{}{
+		"TestPopulateAll": map[string]string{"value": "populated"},
+	})
+	require.NoError(t, err, "Can't create a static provider")
+
+	require.NoError(t, PopulateAll(p))
+	assert.Equal(t, "populated", cfg.Value)
+}
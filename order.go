@@ -0,0 +1,196 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// WithKeyOrder keeps the order of keys in mappings of the sources, so Dump
+// writes them in the order they first appear instead of sorting them, e.g.
+// to avoid noisy diffs of files read by humans. Sources are parsed twice to
+// track the order, which isn't supported with WithUnmarshaler. Only YAML
+// providers and groups of them keep the order.
+func WithKeyOrder() YAMLOption {
+	return func(o *yamlOptions) {
+		o.settings.keyOrder = true
+	}
+}
+
+// keyOrder is a tree of keys of mappings in the order they first appear.
+// Children of sequences are keyed by indexes.
+type keyOrder struct {
+	keys     []interface{}
+	children map[interface{}]*keyOrder
+}
+
+func newKeyOrder() *keyOrder {
+	return &keyOrder{children: make(map[interface{}]*keyOrder)}
+}
+
+// child returns an order of the key, adding it if it doesn't exist.
+func (o *keyOrder) child(key interface{}) *keyOrder {
+	c, ok := o.children[key]
+	if !ok {
+		o.keys = append(o.keys, key)
+		c = newKeyOrder()
+		o.children[key] = c
+	}
+
+	return c
+}
+
+// get returns an order of the key, or nil if it isn't known.
+func (o *keyOrder) get(key interface{}) *keyOrder {
+	if o == nil {
+		return nil
+	}
+
+	return o.children[key]
+}
+
+// add adds keys of a document decoded to yaml.MapSlice values.
+func (o *keyOrder) add(value interface{}) {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		for _, item := range v {
+			o.child(item.Key).add(item.Value)
+		}
+	case []interface{}:
+		// Sequences replace each other, so orders of their elements do too.
+		*o = *newKeyOrder()
+		for i, item := range v {
+			o.child(i).add(item)
+		}
+	}
+}
+
+// merge adds keys of another order.
+func (o *keyOrder) merge(other *keyOrder) {
+	for _, key := range other.keys {
+		o.child(key).merge(other.children[key])
+	}
+}
+
+// addDocuments adds keys of the YAML documents, skipping documents that
+// aren't mappings.
+func (o *keyOrder) addDocuments(raw []byte) {
+	for _, doc := range splitDocuments(raw) {
+		var ms yaml.MapSlice
+		if yaml.Unmarshal(doc, &ms) == nil {
+			o.add(ms)
+		}
+	}
+}
+
+// ordered converts mappings of a value to yaml.MapSlice values with keys in
+// the order, keys missing from the order follow in sorted order. The order
+// isn't modified, so it is safe to call concurrently.
+func (o *keyOrder) ordered(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		res := make(yaml.MapSlice, 0, len(v))
+		for _, key := range o.orderedKeys(v) {
+			res = append(res, yaml.MapItem{Key: key, Value: o.get(key).ordered(v[key])})
+		}
+
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, val := range v {
+			res[i] = o.get(i).ordered(val)
+		}
+
+		return res
+	}
+
+	return value
+}
+
+// keyOrderOf returns an order of keys of a provider, or nil if the provider
+// doesn't keep it.
+func keyOrderOf(p Provider) *keyOrder {
+	if o, ok := p.(interface {
+		keyOrder() *keyOrder
+	}); ok {
+		return o.keyOrder()
+	}
+
+	return nil
+}
+
+// orderedKeys returns keys of a mapping in the order, keys missing from the
+// order follow sorted by their string forms.
+func (o *keyOrder) orderedKeys(m map[interface{}]interface{}) []interface{} {
+	keys := make([]interface{}, 0, len(m))
+	seen := make(map[interface{}]bool, len(m))
+	if o != nil {
+		for _, key := range o.keys {
+			if _, ok := m[key]; ok {
+				keys = append(keys, key)
+				seen[key] = true
+			}
+		}
+	}
+
+	var rest []string
+	names := make(map[string]interface{})
+	for key := range m {
+		if !seen[key] {
+			name := fmt.Sprint(key)
+			rest = append(rest, name)
+			names[name] = key
+		}
+	}
+
+	sort.Strings(rest)
+	for _, name := range rest {
+		keys = append(keys, names[name])
+	}
+
+	return keys
+}
+
+// find returns an order of the key split into segments, or nil if it isn't
+// known.
+func (o *keyOrder) find(segments []string, sep string) *keyOrder {
+	for _, segment := range segments {
+		if o == nil {
+			return nil
+		}
+
+		segment = unescapeSeparators(segment, sep)
+		var next *keyOrder
+		for _, key := range o.keys {
+			if fmt.Sprint(key) == segment {
+				next = o.children[key]
+				break
+			}
+		}
+
+		o = next
+	}
+
+	return o
+}
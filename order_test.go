@@ -0,0 +1,129 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithKeyOrder(t *testing.T) {
+	t.Parallel()
+
+	base := `
+service:
+  name: users
+  port: 80
+  hosts:
+    - {zone: west, addr: a}
+    - {zone: east, addr: b}
+`
+	override := `
+service:
+  port: 8080
+  debug: true
+logging: {level: info}
+`
+
+	p, err := NewYAML(
+		Source(bytes.NewBufferString(base), bytes.NewBufferString(override)),
+		WithKeyOrder(),
+	)
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var buf bytes.Buffer
+	require.NoError(t, Dump(&buf, p))
+	assert.Equal(t, `service:
+  name: users
+  port: 8080
+  hosts:
+  - zone: west
+    addr: a
+  - zone: east
+    addr: b
+  debug: true
+logging:
+  level: info
+`, buf.String())
+
+	computed, err := NewProviderWithComputed(p, map[string]ComputeFunc{
+		"service.alias": func(Provider) (interface{}, error) { return "u", nil },
+	})
+	require.NoError(t, err)
+
+	buf.Reset()
+	require.NoError(t, Dump(&buf, computed))
+	assert.Contains(t, buf.String(), "  debug: true\n  alias: u\nlogging:", "Expected unknown keys after known ones")
+
+	_, err = NewYAML(WithKeyOrder(), WithUnmarshaler(func([]byte, interface{}) error { return nil }))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "WithKeyOrder can't be combined with WithUnmarshaler")
+}
+
+func TestDumpWithoutKeyOrder(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte("b: 1\na: 2"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var buf bytes.Buffer
+	require.NoError(t, Dump(&buf, p))
+	assert.Equal(t, "a: 2\nb: 1\n", buf.String())
+}
+
+func TestKeyOrderOfWrappers(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAML(Source(bytes.NewBufferString("b: {d: 1, c: 2}\na: 3")), WithKeyOrder())
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	intercepted, err := NewProviderWithInterceptors(p)
+	require.NoError(t, err)
+	readThrough, err := NewReadThroughProvider(p, time.Minute)
+	require.NoError(t, err)
+
+	wrappers := map[string]Provider{
+		"intercepted":  intercepted,
+		"read through": readThrough,
+		"hidden key":   hiddenKeyProvider{Provider: p, key: "x"},
+		"snapshot":     snapshotProvider{Provider: p},
+	}
+
+	for name, w := range wrappers {
+		var buf bytes.Buffer
+		require.NoError(t, Dump(&buf, w), name)
+		assert.Equal(t, "b:\n  d: 1\n  c: 2\na: 3\n", buf.String(), "Unexpected order of %s", name)
+	}
+
+	described, err := NewDescribedProvider(p, "base", "base.yaml")
+	require.NoError(t, err)
+	intercepted, err = NewProviderWithInterceptors(described)
+	require.NoError(t, err)
+	assert.Equal(t, Description{Name: "base", Description: "base.yaml"}, Describe(intercepted))
+
+	var buf bytes.Buffer
+	require.NoError(t, Dump(&buf, NewScopedProvider("b", p)))
+	assert.Equal(t, "d: 1\nc: 2\n", buf.String())
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+type overridesKey struct{}
+
+// WithOverrides returns a copy of the context with overrides of configuration
+// values by keys, which are visible only to providers returned by
+// FromContext for the context, e.g. to tweak configuration in parallel tests
+// without modifying shared providers:
+//
+// 	ctx := config.WithOverrides(context.Background(), map[string]interface{}{
+// 		"db.timeout": "1ms",
+// 	})
+//
+// Overrides of nested contexts take precedence over the ones of their
+// parents.
+func WithOverrides(ctx context.Context, overrides map[string]interface{}) context.Context {
+	layers, _ := ctx.Value(overridesKey{}).([]map[string]interface{})
+	layers = append(layers[:len(layers):len(layers)], overrides)
+	return context.WithValue(ctx, overridesKey{}, layers)
+}
+
+// FromContext returns a provider with overrides of the context layered on
+// top of the provider, or the provider itself if the context has no
+// overrides.
+func FromContext(ctx context.Context, p Provider) (Provider, error) {
+	layers, _ := ctx.Value(overridesKey{}).([]map[string]interface{})
+	if len(layers) == 0 {
+		return p, nil
+	}
+
+	sep := separatorOf(p)
+	providers := []Provider{p}
+	for _, overrides := range layers {
+		keys := make([]string, 0, len(overrides))
+		for key := range overrides {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+		root := make(map[interface{}]interface{})
+		for _, key := range keys {
+			if err := setValue(root, splitKey(key, sep), overrides[key], sep); err != nil {
+				return nil, errorWithKey(err, key)
+			}
+		}
+
+		reader, err := toReader(root)
+		if err != nil {
+			return nil, err
+		}
+
+		values, err := NewYAMLProviderFromReaderWithSeparator(sep, reader)
+		if err != nil {
+			return nil, err
+		}
+
+		providers = append(providers, values)
+	}
+
+	return NewProviderGroup(fmt.Sprintf("%s with overrides", p.Name()), providers...)
+}
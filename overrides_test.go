@@ -0,0 +1,71 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithOverrides(t *testing.T) {
+	t.Parallel()
+
+	base, err := NewYAMLProviderFromBytes([]byte(`
+db:
+  host: localhost
+  timeout: 1s
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	p, err := FromContext(context.Background(), base)
+	require.NoError(t, err)
+	assert.Equal(t, base, p, "Expected the provider without overrides")
+
+	ctx := WithOverrides(context.Background(), map[string]interface{}{"db.timeout": "1ms", "db.user": "test"})
+	nested := WithOverrides(ctx, map[string]interface{}{"db.user": "nested"})
+	sibling := WithOverrides(ctx, map[string]interface{}{"db.host": "sibling"})
+
+	p, err = FromContext(ctx, base)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", p.Get("db.host").Value())
+	assert.Equal(t, "1ms", p.Get("db.timeout").Value())
+	assert.Equal(t, "test", p.Get("db.user").Value())
+	assert.Equal(t, `cached "yaml" with overrides`, p.Name())
+
+	p, err = FromContext(nested, base)
+	require.NoError(t, err)
+	assert.Equal(t, "nested", p.Get("db.user").Value())
+	assert.Equal(t, "localhost", p.Get("db.host").Value(), "Sibling overrides shouldn't be visible")
+
+	p, err = FromContext(sibling, base)
+	require.NoError(t, err)
+	assert.Equal(t, "sibling", p.Get("db.host").Value())
+	assert.Equal(t, "test", p.Get("db.user").Value())
+
+	assert.Equal(t, "1s", base.Get("db.timeout").Value(), "The provider shouldn't change")
+
+	_, err = FromContext(WithOverrides(ctx, map[string]interface{}{"a": 1, "a.b": 2}), base)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "key conflicts with another key")
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package policy provides standard configuration types for rate limits and
+// retries, so services don't define slightly different shapes for the same
+// concepts:
+//
+//	client:
+//	  rateLimit: 100/s
+//	  retry:
+//	    maxAttempts: 5
+//	    backoff: {initial: 50ms, max: 5s, jitter: 0.2}
+//
+// The types are validated when they are populated, e.g. with
+// config.Value.Populate. Zero fields mean defaults documented on them.
+package policy // import "go.uber.org/config/policy"
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit allows Rate events per the Per duration with bursts of up to
+// Burst events. It is populated either from a mapping or from a string of
+// the form rate/duration, e.g. 100/s or 5/100ms.
+type RateLimit struct {
+	Rate float64 `yaml:"rate"`
+
+	// Per is one second if it is zero.
+	Per time.Duration `yaml:"per"`
+
+	// Burst is the rate rounded up if it is zero.
+	Burst int `yaml:"burst"`
+}
+
+// ParseRateLimit parses a string of the form rate/duration, e.g. 100/s.
+// Units without numbers are durations of one unit, e.g. s is a second.
+func ParseRateLimit(s string) (RateLimit, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 {
+		return RateLimit{}, fmt.Errorf("rate limit %q isn't of the form rate/duration", s)
+	}
+
+	rate, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return RateLimit{}, fmt.Errorf("invalid rate of %q: %v", s, err)
+	}
+
+	per := strings.TrimSpace(parts[1])
+	if per != "" && (per[0] < '0' || per[0] > '9') {
+		per = "1" + per
+	}
+
+	d, err := time.ParseDuration(per)
+	if err != nil {
+		return RateLimit{}, fmt.Errorf("invalid duration of %q: %v", s, err)
+	}
+
+	r := RateLimit{Rate: rate, Per: d}
+	return r, r.Validate()
+}
+
+// Validate checks that the rate is positive and the rest isn't negative.
+func (r RateLimit) Validate() error {
+	if r.Rate <= 0 {
+		return errors.New("rate must be positive")
+	}
+
+	if r.Per < 0 {
+		return errors.New("per can't be negative")
+	}
+
+	if r.Burst < 0 {
+		return errors.New("burst can't be negative")
+	}
+
+	return nil
+}
+
+// Interval returns the time between events at the rate.
+func (r RateLimit) Interval() time.Duration {
+	per := r.Per
+	if per == 0 {
+		per = time.Second
+	}
+
+	return time.Duration(float64(per) / r.Rate)
+}
+
+// BurstSize returns the burst, or the rate rounded up if it is zero.
+func (r RateLimit) BurstSize() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+
+	return int(math.Ceil(r.Rate))
+}
+
+// UnmarshalYAML populates a rate limit from a string or a mapping.
+func (r *RateLimit) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		parsed, err := ParseRateLimit(s)
+		if err != nil {
+			return err
+		}
+
+		*r = parsed
+		return nil
+	}
+
+	type raw RateLimit
+	if err := unmarshal((*raw)(r)); err != nil {
+		return err
+	}
+
+	return r.Validate()
+}
+
+// Backoff computes exponentially growing delays between attempts.
+type Backoff struct {
+	// Initial is the delay before the second attempt, 100ms if it is zero.
+	Initial time.Duration `yaml:"initial"`
+
+	// Max caps delays, 10s if it is zero.
+	Max time.Duration `yaml:"max"`
+
+	// Multiplier grows delays between attempts, 2 if it is zero.
+	Multiplier float64 `yaml:"multiplier"`
+
+	// Jitter randomly reduces delays by up to the fraction of them, between
+	// 0 and 1.
+	Jitter float64 `yaml:"jitter"`
+}
+
+// Validate checks the durations and factors.
+func (b Backoff) Validate() error {
+	switch {
+	case b.Initial < 0 || b.Max < 0:
+		return errors.New("backoff durations can't be negative")
+	case b.Max > 0 && b.Initial > b.Max:
+		return errors.New("initial backoff can't be greater than max")
+	case b.Multiplier != 0 && b.Multiplier < 1:
+		return errors.New("backoff multiplier can't be less than 1")
+	case b.Jitter < 0 || b.Jitter > 1:
+		return errors.New("backoff jitter must be between 0 and 1")
+	}
+
+	return nil
+}
+
+// Delay returns the delay after the attempt, starting from 1, with the
+// jitter applied.
+func (b Backoff) Delay(attempt int) time.Duration {
+	initial, max, multiplier := b.Initial, b.Max, b.Multiplier
+	if initial == 0 {
+		initial = 100 * time.Millisecond
+	}
+
+	if max == 0 {
+		max = 10 * time.Second
+	}
+
+	if multiplier == 0 {
+		multiplier = 2
+	}
+
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	d := math.Min(float64(initial)*math.Pow(multiplier, float64(attempt-1)), float64(max))
+	return time.Duration(d * (1 - b.Jitter*rand.Float64()))
+}
+
+// UnmarshalYAML populates a backoff and validates it.
+func (b *Backoff) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type raw Backoff
+	if err := unmarshal((*raw)(b)); err != nil {
+		return err
+	}
+
+	return b.Validate()
+}
+
+// RetryPolicy configures retries of failed operations.
+type RetryPolicy struct {
+	// MaxAttempts limits attempts including the first one, 3 if it is zero.
+	MaxAttempts int `yaml:"maxAttempts"`
+
+	// PerAttemptTimeout limits every attempt, zero means no limit.
+	PerAttemptTimeout time.Duration `yaml:"perAttemptTimeout"`
+
+	Backoff Backoff `yaml:"backoff"`
+}
+
+// Validate checks the limits and the backoff.
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 0 {
+		return errors.New("max attempts can't be negative")
+	}
+
+	if p.PerAttemptTimeout < 0 {
+		return errors.New("per attempt timeout can't be negative")
+	}
+
+	return p.Backoff.Validate()
+}
+
+// Attempts returns the maximum number of attempts.
+func (p RetryPolicy) Attempts() int {
+	if p.MaxAttempts == 0 {
+		return 3
+	}
+
+	return p.MaxAttempts
+}
+
+// UnmarshalYAML populates a retry policy and validates it.
+func (p *RetryPolicy) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type raw RetryPolicy
+	if err := unmarshal((*raw)(p)); err != nil {
+		return err
+	}
+
+	return p.Validate()
+}
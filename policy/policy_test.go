@@ -0,0 +1,121 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type client struct {
+	RateLimit RateLimit   `yaml:"rateLimit"`
+	Retry     RetryPolicy `yaml:"retry"`
+}
+
+func TestPopulate(t *testing.T) {
+	t.Parallel()
+
+	p, err := config.NewYAMLProviderFromBytes([]byte(`
+short:
+  rateLimit: 100/s
+  retry:
+    maxAttempts: 5
+    backoff: {initial: 50ms, max: 5s, jitter: 0.2}
+long:
+  rateLimit: {rate: 5, per: 100ms, burst: 10}
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var c client
+	require.NoError(t, p.Get("short").Populate(&c))
+	assert.Equal(t, client{
+		RateLimit: RateLimit{Rate: 100, Per: time.Second},
+		Retry: RetryPolicy{
+			MaxAttempts: 5,
+			Backoff:     Backoff{Initial: 50 * time.Millisecond, Max: 5 * time.Second, Jitter: 0.2},
+		},
+	}, c)
+
+	c = client{}
+	require.NoError(t, p.Get("long").Populate(&c))
+	assert.Equal(t, RateLimit{Rate: 5, Per: 100 * time.Millisecond, Burst: 10}, c.RateLimit)
+	assert.Equal(t, 20*time.Millisecond, c.RateLimit.Interval())
+	assert.Equal(t, 3, c.Retry.Attempts(), "Expected the default number of attempts")
+}
+
+func TestPopulateErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"rateLimit: 100":                           `isn't of the form rate/duration`,
+		"rateLimit: x/s":                           "invalid rate",
+		"rateLimit: 1/x":                           "invalid duration",
+		"rateLimit: {rate: 0}":                     "rate must be positive",
+		"rateLimit: {rate: 1, burst: -1}":          "burst can't be negative",
+		"retry: {maxAttempts: -1}":                 "max attempts can't be negative",
+		"retry: {backoff: {initial: 2s, max: 1s}}": "initial backoff can't be greater than max",
+		"retry: {backoff: {multiplier: 0.5}}":      "backoff multiplier can't be less than 1",
+		"retry: {backoff: {jitter: 2}}":            "backoff jitter must be between 0 and 1",
+	}
+
+	for src, msg := range tests {
+		p, err := config.NewYAMLProviderFromBytes([]byte(src))
+		require.NoError(t, err, "Can't create a YAML provider")
+
+		var c client
+		err = p.Get(config.Root).Populate(&c)
+		require.Error(t, err, "Expected an error for %q", src)
+		assert.Contains(t, err.Error(), msg)
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	t.Parallel()
+
+	r, err := ParseRateLimit("2.5/1m")
+	require.NoError(t, err)
+	assert.Equal(t, RateLimit{Rate: 2.5, Per: time.Minute}, r)
+	assert.Equal(t, 24*time.Second, r.Interval())
+	assert.Equal(t, 3, r.BurstSize())
+
+	_, err = ParseRateLimit("1/-1s")
+	assert.Error(t, err)
+}
+
+func TestBackoffDelay(t *testing.T) {
+	t.Parallel()
+
+	var b Backoff
+	assert.Equal(t, 100*time.Millisecond, b.Delay(0))
+	assert.Equal(t, 400*time.Millisecond, b.Delay(3))
+	assert.Equal(t, 10*time.Second, b.Delay(100), "Expected delays to be capped")
+
+	b = Backoff{Initial: time.Second, Multiplier: 3, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		d := b.Delay(2)
+		assert.True(t, d > 1500*time.Millisecond && d <= 3*time.Second, "Unexpected delay %v", d)
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// A PopulateOption configures how Value.PopulateWith converts configuration
+// values to types of the target.
+type PopulateOption func(*populateOptions)
+
+type populateOptions struct {
+	scalars       scalarParsing
+	strictNumbers bool
+	bestEffort    bool
+	recoverPanics bool
+}
+
+// scalarParsing controls conversions of scalars to fields of other types.
+type scalarParsing int
+
+const (
+	_defaultScalars scalarParsing = iota
+	_lenientScalars
+	_strictScalars
+)
+
+// LenientScalars accepts "yes", "y", "on" as true and "no", "n", "off" as
+// false for boolean fields in any case, in addition to the values accepted
+// by strconv.ParseBool, and ignores surrounding spaces in strings populated
+// to numbers.
+func LenientScalars() PopulateOption {
+	return func(o *populateOptions) {
+		o.scalars = _lenientScalars
+	}
+}
+
+// StrictScalars fails to populate fields of scalar values of other types,
+// e.g. a string to an int field or a boolean to a string field. It catches
+// YAML 1.1 implicit booleans, e.g. `country: NO` populated to a string field
+// is an error instead of "false". Strings are still populated to
+// time.Duration fields and defaults from tags are always converted.
+func StrictScalars() PopulateOption {
+	return func(o *populateOptions) {
+		o.scalars = _strictScalars
+	}
+}
+
+// StrictNumbers rejects ambiguous values of numeric fields with errors
+// instead of converting them: floats, e.g. 1.0e+3 or 08, which YAML parses
+// as a float, populated to integer fields, and strings, that aren't plain
+// decimal numbers, e.g. 1e3, "1,000", "1 000", "0x10" or "010". YAML 1.1 parses
+// unquoted integers with leading zeros, e.g. 010, as octal numbers, which
+// can't be told apart from other integers after parsing, so quote them to
+// have them checked.
+func StrictNumbers() PopulateOption {
+	return func(o *populateOptions) {
+		o.strictNumbers = true
+	}
+}
+
+// BestEffort populates all the fields it can instead of stopping at the
+// first error and returns PopulateErrors of the fields it couldn't populate,
+// e.g. to show all the problems of configuration in a UI. Fields, that
+// failed, keep values decoded before their errors.
+func BestEffort() PopulateOption {
+	return func(o *populateOptions) {
+		o.bestEffort = true
+	}
+}
+
+// PopulateErrors are errors of fields populated with BestEffort.
+type PopulateErrors []error
+
+func (e PopulateErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("can't populate %d fields: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// RecoverPanics converts panics while populating a target, e.g. in
+// UnmarshalText methods of its fields, to PanicError errors, so a malformed
+// value can't crash a service reading configuration in a handler.
+func RecoverPanics() PopulateOption {
+	return func(o *populateOptions) {
+		o.recoverPanics = true
+	}
+}
+
+// PanicError is a panic converted to an error by RecoverPanics.
+type PanicError struct {
+	// Key is the last key, that was being populated.
+	Key string
+
+	// Value is the value passed to panic.
+	Value interface{}
+
+	// Stack is the stack trace of the panic.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic populating key %q: %v", e.Key, e.Value)
+}
+
+// PopulateWith fills in an object from configuration like Populate, but
+// with options controlling conversions of values.
+func (cv Value) PopulateWith(target interface{}, options ...PopulateOption) error {
+	var o populateOptions
+	for _, option := range options {
+		option(&o)
+	}
+
+	return cv.populate(target, o)
+}
+
+// parseScalar checks or adjusts a configuration value of a scalar before it
+// is converted to the type.
+func (o populateOptions) parseScalar(val interface{}, t reflect.Type) (interface{}, error) {
+	if o.strictNumbers {
+		if err := strictNumber(val, t); err != nil {
+			return nil, err
+		}
+	}
+
+	switch o.scalars {
+	case _lenientScalars:
+		return lenientScalar(val, t), nil
+	case _strictScalars:
+		return val, strictScalar(val, t)
+	}
+
+	return val, nil
+}
+
+func lenientScalar(val interface{}, t reflect.Type) interface{} {
+	s, ok := val.(string)
+	if !ok {
+		return val
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case "yes", "y", "on":
+			return true
+		case "no", "n", "off":
+			return false
+		}
+
+		return strings.TrimSpace(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return strings.TrimSpace(s)
+	}
+
+	return val
+}
+
+func strictScalar(val interface{}, t reflect.Type) error {
+	if t == _typeOfDuration {
+		return nil
+	}
+
+	src := reflect.TypeOf(val)
+	var ok bool
+	switch t.Kind() {
+	case reflect.Bool:
+		ok = src.Kind() == reflect.Bool
+	case reflect.String:
+		ok = src.Kind() == reflect.String
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		ok = isInteger(src.Kind())
+	case reflect.Float32, reflect.Float64:
+		ok = isInteger(src.Kind()) || src.Kind() == reflect.Float32 || src.Kind() == reflect.Float64
+	default:
+		ok = true
+	}
+
+	if !ok {
+		return fmt.Errorf("can't convert %v of type %v to %v with strict scalars", val, src, t)
+	}
+
+	return nil
+}
+
+var (
+	_plainInteger = regexp.MustCompile(`^[-+]?(0|[1-9][0-9]*)$`)
+	_plainFloat   = regexp.MustCompile(`^[-+]?(0|[1-9][0-9]*)(\.[0-9]+)?$`)
+)
+
+func strictNumber(val interface{}, t reflect.Type) error {
+	if t == _typeOfDuration {
+		return nil
+	}
+
+	integer := isInteger(t.Kind())
+	if !integer && t.Kind() != reflect.Float32 && t.Kind() != reflect.Float64 {
+		return nil
+	}
+
+	switch v := val.(type) {
+	case float32, float64:
+		if integer {
+			return fmt.Errorf("float %v is ambiguous for %v, use a plain integer", v, t)
+		}
+	case string:
+		plain := _plainFloat
+		if integer {
+			plain = _plainInteger
+		}
+
+		if !plain.MatchString(v) {
+			return fmt.Errorf("%q is not a plain decimal number for %v", v, t)
+		}
+	}
+
+	return nil
+}
+
+func isInteger(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+
+	return false
+}
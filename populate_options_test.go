@@ -0,0 +1,241 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type scalars struct {
+	Enabled bool          `yaml:"enabled"`
+	Port    int           `yaml:"port"`
+	Ratio   float64       `yaml:"ratio"`
+	Country string        `yaml:"country"`
+	Timeout time.Duration `yaml:"timeout"`
+	Retries uint          `yaml:"retries" default:"3"`
+}
+
+func TestLenientScalars(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+enabled: "Y"
+port: " 8080 "
+ratio: "0.5"
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var s scalars
+	require.Error(t, p.Get(Root).Populate(&s), "Expected an error without the option")
+
+	require.NoError(t, p.Get(Root).PopulateWith(&s, LenientScalars()))
+	assert.Equal(t, scalars{Enabled: true, Port: 8080, Ratio: 0.5, Retries: 3}, s)
+
+	for _, v := range []string{"no", "OFF", "n", " false "} {
+		p, err := NewStaticProvider(map[string]interface{}{"enabled": v})
+		require.NoError(t, err)
+
+		s := scalars{Enabled: true}
+		require.NoError(t, p.Get(Root).PopulateWith(&s, LenientScalars()), "Can't populate %q", v)
+		assert.False(t, s.Enabled, "Wrong value for %q", v)
+	}
+}
+
+func TestStrictScalars(t *testing.T) {
+	t.Parallel()
+
+	valid, err := NewYAMLProviderFromBytes([]byte(`
+enabled: true
+port: 8080
+ratio: 1
+country: "NO"
+timeout: 1s
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var s scalars
+	require.NoError(t, valid.Get(Root).PopulateWith(&s, StrictScalars()))
+	assert.Equal(t, scalars{
+		Enabled: true,
+		Port:    8080,
+		Ratio:   1,
+		Country: "NO",
+		Timeout: time.Second,
+		Retries: 3,
+	}, s)
+
+	tests := map[string]string{
+		"country: NO":           `for key "country": can't convert false of type bool to string with strict scalars`,
+		`port: "8080"`:          `for key "port": can't convert 8080 of type string to int with strict scalars`,
+		`enabled: "true"`:       `for key "enabled": can't convert true of type string to bool with strict scalars`,
+		"ratio: 0.5\nport: 1.5": `for key "port"`,
+	}
+
+	for src, msg := range tests {
+		p, err := NewYAMLProviderFromBytes([]byte(src))
+		require.NoError(t, err, "Can't create a YAML provider for %q", src)
+
+		var s scalars
+		require.NoError(t, p.Get(Root).Populate(&s), "Unexpected error without the option for %q", src)
+
+		err = p.Get(Root).PopulateWith(&s, StrictScalars())
+		require.Error(t, err, "Expected an error for %q", src)
+		assert.Contains(t, err.Error(), msg)
+	}
+}
+
+func TestBestEffort(t *testing.T) {
+	t.Parallel()
+
+	type db struct {
+		Host string `yaml:"host" validate:"nonzero"`
+		Port int    `yaml:"port"`
+	}
+
+	type server struct {
+		Name    string `yaml:"name"`
+		Workers int    `yaml:"workers"`
+		Mode    string `yaml:"mode" oneof:"fast,slow"`
+		DB      db     `yaml:"db"`
+	}
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+name: api
+workers: many
+mode: medium
+db:
+  port: 5432
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var s server
+	err = p.Get(Root).PopulateWith(&s, BestEffort())
+	require.Error(t, err)
+
+	errs, ok := err.(PopulateErrors)
+	require.True(t, ok, "Expected PopulateErrors, got %T", err)
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0].Error(), `for key "workers"`)
+	assert.Contains(t, errs[1].Error(), `for key "mode"`)
+	assert.Contains(t, errs[2].Error(), `for key "db"`)
+	assert.Contains(t, err.Error(), "can't populate 3 fields: ")
+	assert.Equal(t, server{Name: "api", Mode: "medium", DB: db{Port: 5432}}, s)
+
+	err = p.Get(Root).Populate(&s)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `for key "workers"`)
+	_, ok = err.(PopulateErrors)
+	assert.False(t, ok, "Populate should stop at the first error")
+}
+
+func TestStrictNumbers(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+octal: 08
+exponent: 1.0e+3
+shortExponent: 1e3
+grouped: "1,000"
+quoted: "010"
+hex: "0x10"
+plain: 42
+negative: "-7"
+ratio: 0.5
+ratioString: "1.5"
+timeout: 1s
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	tests := []struct {
+		key    string
+		target interface{}
+		want   interface{}
+		err    string
+	}{
+		{key: "octal", target: new(int), err: "float 8 is ambiguous for int, use a plain integer"},
+		{key: "exponent", target: new(int64), err: "float 1000 is ambiguous for int64"},
+		{key: "shortExponent", target: new(int), err: `"1e3" is not a plain decimal number for int`},
+		{key: "grouped", target: new(int), err: `"1,000" is not a plain decimal number for int`},
+		{key: "grouped", target: new(float64), err: `"1,000" is not a plain decimal number for float64`},
+		{key: "quoted", target: new(uint), err: `"010" is not a plain decimal number for uint`},
+		{key: "hex", target: new(int), err: `"0x10" is not a plain decimal number for int`},
+		{key: "plain", target: new(int), want: 42},
+		{key: "negative", target: new(int), want: -7},
+		{key: "octal", target: new(float64), want: 8.0},
+		{key: "ratio", target: new(float64), want: 0.5},
+		{key: "ratioString", target: new(float64), want: 1.5},
+		{key: "timeout", target: new(time.Duration), want: time.Second},
+		{key: "grouped", target: new(string), want: "1,000"},
+	}
+
+	for _, tt := range tests {
+		err := p.Get(tt.key).PopulateWith(tt.target, StrictNumbers())
+		if tt.err != "" {
+			require.Error(t, err, "Expected an error for %q", tt.key)
+			assert.Contains(t, err.Error(), tt.err)
+			continue
+		}
+
+		require.NoError(t, err, "Unexpected error for %q", tt.key)
+		assert.Equal(t, tt.want, reflect.ValueOf(tt.target).Elem().Interface(), "Wrong value for %q", tt.key)
+	}
+
+	var n int
+	require.NoError(t, p.Get("exponent").Populate(&n))
+	assert.Equal(t, 1000, n, "Populate should convert floats to integers")
+}
+
+// panicky panics unmarshaling text.
+type panicky struct{}
+
+func (*panicky) UnmarshalText([]byte) error {
+	panic("malformed")
+}
+
+func TestRecoverPanics(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte("outer: {inner: value}"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var target struct {
+		Outer struct {
+			Inner panicky `yaml:"inner"`
+		} `yaml:"outer"`
+	}
+
+	err = p.Get(Root).PopulateWith(&target, RecoverPanics())
+	require.Error(t, err)
+	assert.Equal(t, `panic populating key "outer.inner": malformed`, err.Error())
+
+	perr, ok := err.(*PanicError)
+	require.True(t, ok, "Expected a PanicError, got %T", err)
+	assert.Equal(t, "outer.inner", perr.Key)
+	assert.Equal(t, "malformed", perr.Value)
+	assert.Contains(t, string(perr.Stack), "UnmarshalText")
+
+	assert.Panics(t, func() { p.Get(Root).Populate(&target) })
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// A Report describes problems of configuration found by Preflight.
+type Report struct {
+	// Missing are keys of required fields, i.e. with the nonzero validation
+	// rule and no default value, without values.
+	Missing []string
+
+	// Invalid are errors of populating targets, e.g. type errors and failed
+	// validations, including the ones of missing required fields.
+	Invalid []string
+
+	// Deprecated are fallback keys fields are populated from, because their
+	// keys have no values.
+	Deprecated []string
+}
+
+// OK returns true if there are no missing or invalid values. Deprecated keys
+// are warnings.
+func (r Report) OK() bool {
+	return len(r.Missing) == 0 && len(r.Invalid) == 0
+}
+
+// String returns a multi-line report, e.g. to print before a service starts
+// serving.
+func (r Report) String() string {
+	if r.OK() && len(r.Deprecated) == 0 {
+		return "config preflight: no problems"
+	}
+
+	lines := []string{fmt.Sprintf("config preflight: %d problems", len(r.Missing)+len(r.Invalid)+len(r.Deprecated))}
+	for _, key := range r.Missing {
+		lines = append(lines, "missing: "+key)
+	}
+
+	for _, msg := range r.Invalid {
+		lines = append(lines, "invalid: "+msg)
+	}
+
+	for _, msg := range r.Deprecated {
+		lines = append(lines, "deprecated: "+msg)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Preflight checks configuration of all the registered modules and of the
+// targets, which are populated from the root, without modifying them.
+// Targets can also be Module values. Copies of targets are populated, so
+// Validate functions of modules, which check their targets, aren't called.
+func Preflight(p Provider, targets ...interface{}) Report {
+	_modules.Lock()
+	modules := append([]Module(nil), _modules.modules...)
+	_modules.Unlock()
+
+	for _, t := range targets {
+		if m, ok := t.(Module); ok {
+			modules = append(modules, m)
+		} else {
+			modules = append(modules, Module{Prefix: Root, Target: t})
+		}
+	}
+
+	var r Report
+	sep := separatorOf(p)
+	for i, m := range modules {
+		name := fmt.Sprintf("module %q", m.Prefix)
+		if m.Prefix == Root {
+			name = fmt.Sprintf("target %d", i)
+		}
+
+		t := reflect.TypeOf(m.Target)
+		if t == nil || t.Kind() != reflect.Ptr {
+			r.Invalid = append(r.Invalid, fmt.Sprintf("%s: target must be a pointer, got %T", name, m.Target))
+			continue
+		}
+
+		r.walk(p, m.Prefix, t.Elem(), sep)
+		if err := p.Get(m.Prefix).Populate(reflect.New(t.Elem()).Interface()); err != nil {
+			r.Invalid = append(r.Invalid, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+
+	return r
+}
+
+// walk checks required fields and fallback keys of a struct type.
+func (r *Report) walk(p Provider, key string, t reflect.Type, sep string) {
+	t = derefType(t)
+	if t.Kind() != reflect.Struct || isScalarStruct(t) {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Anonymous {
+			continue
+		}
+
+		info := getFieldInfo(field)
+		name := field.Name
+		if info.FieldName != "" {
+			name = info.FieldName
+		}
+
+		fieldKey := joinKey(key, name, sep)
+		if p.Get(fieldKey).HasValue() {
+			r.walk(p, fieldKey, field.Type, sep)
+			continue
+		}
+
+		if v := GetFirst(p, info.Fallback...); v.HasValue() {
+			r.Deprecated = append(r.Deprecated, fmt.Sprintf("%s is used instead of %s", v.key, fieldKey))
+			continue
+		}
+
+		if info.DefaultValue == "" && hasRule(field.Tag.Get("validate"), "nonzero") {
+			r.Missing = append(r.Missing, fieldKey)
+		}
+
+		r.walk(p, fieldKey, field.Type, sep)
+	}
+}
+
+// isScalarStruct returns true for structs populated from scalars, e.g.
+// time.Time.
+func isScalarStruct(t reflect.Type) bool {
+	for _, m := range []string{"UnmarshalText", "UnmarshalJSON", "UnmarshalYAML"} {
+		if _, ok := reflect.PtrTo(t).MethodByName(m); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasRule(tag, rule string) bool {
+	for _, r := range strings.Split(tag, ",") {
+		if strings.TrimSpace(r) == rule {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type preflightDB struct {
+	Host string `yaml:"host" validate:"nonzero" fallback:"legacy.db_host"`
+	User string `yaml:"user" validate:"nonzero"`
+	Port int    `yaml:"port" default:"5432" validate:"nonzero"`
+}
+
+type preflightServer struct {
+	Name    string `yaml:"name" validate:"nonzero"`
+	Workers int    `yaml:"workers"`
+	DB      preflightDB
+}
+
+func TestPreflight(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+legacy:
+  db_host: localhost
+server:
+  workers: many
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var server preflightServer
+	r := Preflight(p, Module{Prefix: "server", Target: &server}, 42)
+	assert.False(t, r.OK())
+	assert.Equal(t, []string{"server.name", "server.DB.user"}, r.Missing)
+	assert.Equal(t, []string{"legacy.db_host is used instead of server.DB.host"}, r.Deprecated)
+	require.Len(t, r.Invalid, 2)
+	assert.Contains(t, r.Invalid[0], `module "server": for key "server.workers"`)
+	assert.Equal(t, "target 1: target must be a pointer, got int", r.Invalid[1])
+	assert.Equal(t, preflightServer{}, server, "Targets shouldn't be modified")
+
+	assert.Contains(t, r.String(), "config preflight: 5 problems\nmissing: server.name\n")
+	assert.Contains(t, r.String(), "\ndeprecated: legacy.db_host is used instead of server.DB.host")
+}
+
+func TestPreflightOK(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte("host: db\nuser: admin"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var db preflightDB
+	r := Preflight(p, &db)
+	assert.True(t, r.OK())
+	assert.Equal(t, "config preflight: no problems", r.String())
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// ChangeKind tells how a value changes.
+type ChangeKind int
+
+const (
+	// Added values are missing in the existing configuration.
+	Added ChangeKind = iota + 1
+	// Removed values are replaced with values of other types, e.g. a map with
+	// a scalar.
+	Removed
+	// Modified values are replaced with different values.
+	Modified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	}
+
+	return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// A Change describes a change of a scalar value, an empty collection or
+// a null.
+type Change struct {
+	Key  string
+	Kind ChangeKind
+	Old  interface{}
+	New  interface{}
+}
+
+// Diff is a list of changes sorted by keys.
+type Diff []Change
+
+// PreviewMerge computes changes merging the incoming YAML on top of the
+// configuration of a provider would make, like a provider group does, e.g.
+// to review them before applying. The provider is not modified. Separators
+// in map keys are escaped.
+func PreviewMerge(existing Provider, incoming []byte) (Diff, error) {
+	var curr interface{}
+	if err := unmarshalYAMLValue(bytes.NewReader(incoming), &curr); err != nil {
+		return nil, err
+	}
+
+	old := existing.Get(Root).Value()
+	merged, err := mergeMaps(deepCopy(old), curr)
+	if err != nil {
+		return nil, err
+	}
+
+	separator := separatorOf(existing)
+	before := make(map[string]interface{})
+	flatten(before, Root, old, separator)
+	after := make(map[string]interface{})
+	flatten(after, Root, merged, separator)
+
+	keys := make([]string, 0, len(after))
+	for key := range after {
+		keys = append(keys, key)
+	}
+
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	var diff Diff
+	for _, key := range keys {
+		o, existed := before[key]
+		v, exists := after[key]
+		switch {
+		case !existed:
+			diff = append(diff, Change{Key: key, Kind: Added, New: v})
+		case !exists:
+			diff = append(diff, Change{Key: key, Kind: Removed, Old: o})
+		case !reflect.DeepEqual(o, v):
+			diff = append(diff, Change{Key: key, Kind: Modified, Old: o, New: v})
+		}
+	}
+
+	return diff, nil
+}
+
+// flatten puts scalars, empty collections and nulls of a value into the map by
+// their keys.
+func flatten(res map[string]interface{}, key string, value interface{}, separator string) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		if len(v) > 0 {
+			for k, child := range v {
+				flatten(res, joinKey(key, escapeSeparators(fmt.Sprint(k), separator), separator), child, separator)
+			}
+
+			return
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			for i, child := range v {
+				flatten(res, joinKey(key, strconv.Itoa(i), separator), child, separator)
+			}
+
+			return
+		}
+	}
+
+	if key != Root || value != nil {
+		res[key] = value
+	}
+}
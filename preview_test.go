@@ -0,0 +1,109 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewMerge(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+db:
+  host: localhost
+  port: 5432
+hosts: [a, b]
+cache:
+  size: 10
+same: 1
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	diff, err := PreviewMerge(p, []byte(`
+db:
+  port: 6432
+  user: admin
+hosts: [c]
+cache: off
+same: 1
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, Diff{
+		{Key: "cache", Kind: Added, New: false},
+		{Key: "cache.size", Kind: Removed, Old: 10},
+		{Key: "db.port", Kind: Modified, Old: 5432, New: 6432},
+		{Key: "db.user", Kind: Added, New: "admin"},
+		{Key: "hosts.0", Kind: Modified, Old: "a", New: "c"},
+		{Key: "hosts.1", Kind: Removed, Old: "b"},
+	}, diff)
+
+	assert.Equal(t, 10, p.Get("cache.size").Value(), "The provider shouldn't change")
+	assert.Equal(t, 5432, p.Get("db.port").Value(), "The provider shouldn't change")
+
+	diff, err = PreviewMerge(p, []byte("same: 1"))
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+}
+
+func TestPreviewMergeWithSeparator(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromReaderWithSeparator("/", bytes.NewBufferString("hosts:\n  a.com: 80"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	diff, err := PreviewMerge(p, []byte("hosts:\n  a/b: 81\n  a.com: 8080"))
+	require.NoError(t, err)
+	assert.Equal(t, Diff{
+		{Key: `hosts/a.com`, Kind: Modified, Old: 80, New: 8080},
+		{Key: `hosts/a\/b`, Kind: Added, New: 81},
+	}, diff)
+}
+
+func TestPreviewMergeErrors(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte("a:\n  b: c"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	_, err = PreviewMerge(p, []byte("a: ["))
+	assert.Error(t, err)
+
+	_, err = PreviewMerge(p, []byte("a:\n  b:\n    c: d"))
+	assert.Error(t, err, "Can't merge a map into a scalar")
+
+	e, err := NewYAMLProviderFromBytes(nil)
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	diff, err := PreviewMerge(e, []byte("a: b"))
+	require.NoError(t, err)
+	assert.Equal(t, Diff{{Key: "a", Kind: Added, New: "b"}}, diff)
+
+	assert.Equal(t, "added", Added.String())
+	assert.Equal(t, "removed", Removed.String())
+	assert.Equal(t, "modified", Modified.String())
+	assert.Equal(t, "ChangeKind(0)", ChangeKind(0).String())
+}
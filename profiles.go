@@ -0,0 +1,109 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// _profilesKey is a top level key with configuration profiles.
+const _profilesKey = "profiles"
+
+// NewProviderWithProfiles creates a provider, that overrides values of the
+// underlying provider with values from the active profiles. Profiles are
+// defined under the top level profiles key, e.g.
+//
+// 	db:
+// 	  host: localhost
+// 	profiles:
+// 	  production:
+// 	    db:
+// 	      host: db.prod
+//
+// Profiles are merged in the order they are passed, the last one has
+// the highest priority. Profiles that are not defined are ignored.
+// The profiles key itself is not visible in the new provider.
+func NewProviderWithProfiles(p Provider, profiles ...string) (Provider, error) {
+	if p == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	base := hiddenKeyProvider{Provider: p, key: _profilesKey}
+	providers := []Provider{base}
+	for _, profile := range profiles {
+		if profile == "" {
+			return nil, errors.New("empty profile name")
+		}
+
+		prefix := _profilesKey + base.separator() + escapeSeparators(profile, base.separator())
+		providers = append(providers, NewScopedProvider(prefix, p))
+	}
+
+	return NewProviderGroup(fmt.Sprintf("%s with profiles %v", p.Name(), profiles), providers...)
+}
+
+// hiddenKeyProvider hides a top level key of the underlying provider.
+type hiddenKeyProvider struct {
+	Provider
+
+	key string
+}
+
+func (p hiddenKeyProvider) separator() string {
+	return separatorOf(p.Provider)
+}
+
+// Healthy returns health of the underlying provider.
+func (p hiddenKeyProvider) Healthy() error {
+	return Healthy(p.Provider)
+}
+
+func (p hiddenKeyProvider) keyOrder() *keyOrder {
+	return keyOrderOf(p.Provider)
+}
+
+// Get returns values of the underlying provider, except the hidden one.
+func (p hiddenKeyProvider) Get(key string) Value {
+	v := p.Provider.Get(key)
+	if key == Root {
+		m, ok := v.Value().(map[interface{}]interface{})
+		if !ok {
+			return v
+		}
+
+		res := make(map[interface{}]interface{}, len(m))
+		for k, val := range m {
+			if s, ok := k.(string); !ok || !strings.EqualFold(s, p.key) {
+				res[k] = val
+			}
+		}
+
+		return NewValue(p, key, res, v.HasValue())
+	}
+
+	if segment := splitKey(key, p.separator())[0]; strings.EqualFold(segment, p.key) {
+		return NewValue(p, key, nil, false)
+	}
+
+	return v
+}
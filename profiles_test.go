@@ -0,0 +1,117 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _profilesYAML = []byte(`
+db:
+  host: localhost
+  port: 5432
+logging:
+  level: debug
+profiles:
+  staging:
+    db:
+      host: db.staging
+  production:
+    db:
+      host: db.prod
+    logging:
+      level: warn
+  debug:
+    logging:
+      level: debug
+`)
+
+func TestNewProviderWithProfiles(t *testing.T) {
+	t.Parallel()
+
+	y, err := NewYAMLProviderFromBytes(_profilesYAML)
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	p, err := NewProviderWithProfiles(y, "production", "debug", "undefined")
+	require.NoError(t, err)
+
+	type cfg struct {
+		DB struct {
+			Host string
+			Port int
+		}
+		Logging struct {
+			Level string
+		}
+	}
+
+	var c cfg
+	require.NoError(t, p.Get(Root).Populate(&c))
+	assert.Equal(t, "db.prod", c.DB.Host)
+	assert.Equal(t, 5432, c.DB.Port)
+	assert.Equal(t, "debug", c.Logging.Level)
+
+	assert.False(t, p.Get("profiles").HasValue())
+	assert.False(t, p.Get("Profiles.staging.db.host").HasValue())
+
+	var root map[string]interface{}
+	require.NoError(t, p.Get(Root).Populate(&root))
+	assert.NotContains(t, root, "profiles")
+	assert.Contains(t, root, "db")
+}
+
+func TestNewProviderWithoutProfiles(t *testing.T) {
+	t.Parallel()
+
+	y, err := NewYAMLProviderFromBytes(_profilesYAML)
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	p, err := NewProviderWithProfiles(y)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", p.Get("db.host").Value())
+	assert.Equal(t, `cached "yaml" with profiles []`, p.Name())
+}
+
+func TestNewProviderWithProfilesNonMapRoot(t *testing.T) {
+	t.Parallel()
+
+	y, err := NewYAMLProviderFromBytes([]byte(`- a`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	p, err := NewProviderWithProfiles(y, "production")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"a"}, p.Get(Root).Value())
+}
+
+func TestNewProviderWithProfilesErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewProviderWithProfiles(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "received a nil provider")
+
+	_, err = NewProviderWithProfiles(NopProvider{}, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty profile name")
+}
@@ -55,7 +55,28 @@ func (sp scopedProvider) addPrefix(key string) string {
 		return sp.prefix
 	}
 
-	return sp.prefix + "." + key
+	return sp.prefix + sp.separator() + key
+}
+
+func (sp scopedProvider) separator() string {
+	return separatorOf(sp.Provider)
+}
+
+func (sp scopedProvider) keyOrder() *keyOrder {
+	sep := sp.separator()
+	return keyOrderOf(sp.Provider).find(splitKey(sp.prefix, sep), sep)
+}
+
+// separatorOf returns the separator a provider uses to split keys into
+// path segments. Unless a provider uses a custom one, it is a dot.
+func separatorOf(p Provider) string {
+	if s, ok := p.(interface {
+		separator() string
+	}); ok {
+		return s.separator()
+	}
+
+	return _separator
 }
 
 // Get returns the configuration value found at key.
@@ -20,9 +20,15 @@
 
 package config
 
+import (
+	"fmt"
+	"strings"
+)
+
 type providerGroup struct {
-	providers []Provider
-	name      string
+	providers    []Provider
+	name         string
+	keySeparator string
 }
 
 // NewProviderGroup creates a configuration provider from a group of providers.
@@ -40,26 +46,55 @@ type providerGroup struct {
 // an error inside.
 //
 // In all the remaining cases B will overwrite A.
+//
+// All the providers in a group must use the same key separator.
 func NewProviderGroup(name string, providers ...Provider) (Provider, error) {
-	return providerGroup{providers: providers, name: name}, nil
+	separator := _separator
+	for i, p := range providers {
+		s := separatorOf(p)
+		if i == 0 {
+			separator = s
+		} else if s != separator {
+			return nil, fmt.Errorf("can't group providers with different key separators: %q and %q", separator, s)
+		}
+	}
+
+	return providerGroup{providers: providers, name: name, keySeparator: separator}, nil
 }
 
 func (p providerGroup) Get(key string) Value {
-	var res interface{}
-	found := false
+	var values []interface{}
+	var loadErr, missing error
 	for _, provider := range p.providers {
-		if val := provider.Get(key); val.HasValue() {
-			tmp, err := mergeMaps(res, val.value)
-			if err != nil {
-				return NewValue(p, key, nil, false)
-			}
+		val := provider.Get(key)
+		if val.err != nil && loadErr == nil {
+			loadErr = val.err
+		}
+
+		if val.missing != nil && missing == nil {
+			missing = val.missing
+		}
+
+		if val.HasValue() {
+			values = append(values, val.value)
+		} else if len(values) > 0 && p.shadows(provider, key) {
+			values = nil
+		}
+	}
 
-			res = tmp
-			found = true
+	var res interface{}
+	for _, val := range values {
+		tmp, err := mergeCopy(res, val)
+		if err != nil {
+			return NewValue(p, key, nil, false)
 		}
+
+		res = tmp
 	}
 
-	cv := NewValue(p, key, res, found)
+	cv := NewValue(p, key, res, len(values) > 0)
+	cv.err = loadErr
+	cv.missing = missing
 
 	// here we add a new root, which defines the "scope" at which
 	// Populates will look for values.
@@ -71,3 +106,84 @@ func (p providerGroup) Get(key string) Value {
 func (p providerGroup) Name() string {
 	return p.name
 }
+
+func (p providerGroup) separator() string {
+	return p.keySeparator
+}
+
+// keyOrder merges orders of keys of the providers in their order, it
+// returns nil if none of them keeps the order.
+func (p providerGroup) keyOrder() *keyOrder {
+	var res *keyOrder
+	for _, provider := range p.providers {
+		if o := keyOrderOf(provider); o != nil {
+			if res == nil {
+				res = newKeyOrder()
+			}
+
+			res.merge(o)
+		}
+	}
+
+	return res
+}
+
+// shadows returns true if the provider overrides a parent of the key with
+// a value that can't be merged, e.g. a scalar or a sequence. Such a value
+// replaces everything earlier providers have under the key.
+func (p providerGroup) shadows(provider Provider, key string) bool {
+	sep := p.separator()
+	segments := splitKey(key, sep)
+	for i := 1; i < len(segments); i++ {
+		v := provider.Get(strings.Join(segments[:i], sep)).Value()
+		if v == nil {
+			// Deeper parents are missing too.
+			return false
+		}
+
+		if _, ok := v.(map[interface{}]interface{}); !ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergeCopy merges the source into the destination like mergeMaps, but
+// modifies neither of them: maps merged with other maps are copied, other
+// values are shared with the result.
+func mergeCopy(dst, src interface{}) (interface{}, error) {
+	if dst == nil {
+		return src, nil
+	}
+
+	if src == nil {
+		return dst, nil
+	}
+
+	s, ok := src.(map[interface{}]interface{})
+	if !ok {
+		return src, nil
+	}
+
+	d, ok := dst.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("can't merge map[interface{}]interface{} and %T. Source: %q. Destination: %q", dst, src, dst)
+	}
+
+	res := make(map[interface{}]interface{}, len(d)+len(s))
+	for k, v := range d {
+		res[k] = v
+	}
+
+	for k, v := range s {
+		merged, err := mergeCopy(res[k], v)
+		if err != nil {
+			return nil, err
+		}
+
+		res[k] = merged
+	}
+
+	return res, nil
+}
@@ -21,6 +21,8 @@
 package config
 
 import (
+	"bytes"
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -113,3 +115,141 @@ func TestProviderGroup_GetChecksAllProviders(t *testing.T) {
 	require.NoError(t, pg.Get(Root).Populate(&svc))
 	assert.Equal(t, map[string]string{"name": "fx", "owner": "tst@example.com", "desc": "test"}, svc)
 }
+
+func TestProviderGroupWithDifferentSeparators(t *testing.T) {
+	t.Parallel()
+
+	dot, err := NewYAMLProviderFromBytes([]byte(`a: b`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	slash, err := NewYAMLProviderFromReaderWithSeparator("/", bytes.NewBufferString(`a: c`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	_, err = NewProviderGroup("group", dot, slash)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `can't group providers with different key separators: "." and "/"`)
+
+	pg, err := NewProviderGroup("group", slash, slash)
+	require.NoError(t, err)
+	assert.Equal(t, "/", separatorOf(pg))
+	assert.Equal(t, "/", separatorOf(NewScopedProvider("a", pg)))
+}
+
+func TestProviderGroupDoesNotModifyProviders(t *testing.T) {
+	t.Parallel()
+
+	base, err := NewYAMLProviderFromBytes([]byte(`
+limits:
+  quota: 100
+  nested:
+    a: base
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	mid, err := NewYAMLProviderFromBytes([]byte(`
+limits:
+  extra:
+    b: mid
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	top, err := NewYAMLProviderFromBytes([]byte(`
+limits:
+  quota: 500
+  nested:
+    a: top
+  extra:
+    b: top
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	pg, err := NewProviderGroup("group", base, mid, top)
+	require.NoError(t, err)
+
+	assert.Equal(t, 500, pg.Get("limits.quota").Value())
+	assert.Equal(t, "top", pg.Get("limits").Get("nested.a").Value())
+	assert.Equal(t, map[interface{}]interface{}{
+		"quota":  500,
+		"nested": map[interface{}]interface{}{"a": "top"},
+		"extra":  map[interface{}]interface{}{"b": "top"},
+	}, pg.Get("limits").Value())
+
+	assert.Equal(t, 100, base.Get("limits.quota").Value())
+	assert.Equal(t, "base", base.Get("limits.nested.a").Value())
+	assert.Equal(t, "mid", mid.Get("limits.extra.b").Value())
+	assert.Equal(t, map[interface{}]interface{}{
+		"quota":  100,
+		"nested": map[interface{}]interface{}{"a": "base"},
+	}, base.Get("limits").Value())
+}
+
+func TestProviderGroupShadowsSequences(t *testing.T) {
+	t.Parallel()
+
+	base, err := NewYAMLProviderFromBytes([]byte(`
+ports: [80, 8080]
+clusters:
+  - name: east
+  - name: west
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	prod, err := NewYAMLProviderFromBytes([]byte(`
+ports: [443]
+clusters:
+  - size: 3
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	pg, err := NewProviderGroup("group", base, prod)
+	require.NoError(t, err)
+
+	assert.Equal(t, 443, pg.Get("ports.0").Value())
+	assert.False(t, pg.Get("ports.1").HasValue())
+	assert.Equal(t, 3, pg.Get("clusters.0.size").Value())
+	assert.False(t, pg.Get("clusters.0.name").HasValue())
+	assert.False(t, pg.Get("clusters.1").HasValue())
+	assert.Equal(t, 8080, base.Get("ports.1").Value())
+}
+
+func TestProviderGroupSharesValues(t *testing.T) {
+	t.Parallel()
+
+	base, err := NewYAMLProviderFromBytes([]byte("a: {b: 1}\nlarge: {c: [1, 2]}"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	top, err := NewYAMLProviderFromBytes([]byte("a: {d: 2}"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	pg, err := NewProviderGroup("group", base, top)
+	require.NoError(t, err)
+
+	root := pg.Get(Root).Value().(map[interface{}]interface{})
+	assert.Equal(t, map[interface{}]interface{}{"b": 1, "d": 2}, root["a"])
+
+	original := base.Get("large").Value()
+	assert.Equal(t, reflect.ValueOf(original).Pointer(), reflect.ValueOf(root["large"]).Pointer(),
+		"Values, that aren't merged, should not be copied")
+	assert.Equal(t, map[interface{}]interface{}{"b": 1}, base.Get("a").Value())
+}
+
+func TestMergeCopy(t *testing.T) {
+	t.Parallel()
+
+	dst := map[interface{}]interface{}{"a": map[interface{}]interface{}{"b": 1}, "c": 1}
+	src := map[interface{}]interface{}{"a": map[interface{}]interface{}{"d": 2}, "c": nil, "e": []interface{}{1}}
+
+	res, err := mergeCopy(dst, src)
+	require.NoError(t, err)
+	assert.Equal(t, map[interface{}]interface{}{
+		"a": map[interface{}]interface{}{"b": 1, "d": 2},
+		"c": 1,
+		"e": []interface{}{1},
+	}, res)
+	assert.Equal(t, map[interface{}]interface{}{"a": map[interface{}]interface{}{"b": 1}, "c": 1}, dst)
+	assert.Equal(t, map[interface{}]interface{}{"d": 2}, src["a"])
+
+	_, err = mergeCopy([]interface{}{1}, map[interface{}]interface{}{"a": 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't merge map[interface{}]interface{} and []interface {}")
+}
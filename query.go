@@ -0,0 +1,220 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmespath/go-jmespath"
+	"github.com/pkg/errors"
+)
+
+// Wildcard matches any single segment of a key in GetAll patterns.
+const Wildcard = "*"
+
+// GetAll returns values for all the keys matching the pattern, indexed by
+// the matching keys. Segments of the pattern equal to the Wildcard match any
+// map key or array index at that level, e.g. "modules.*.port" returns ports
+// of all the modules. Keys without values are omitted.
+func GetAll(p Provider, pattern string) map[string]Value {
+	separator := separatorOf(p)
+	keys := []string{Root}
+	for _, segment := range splitKey(pattern, separator) {
+		var next []string
+		for _, key := range keys {
+			if segment != Wildcard {
+				next = append(next, joinKey(key, segment, separator))
+				continue
+			}
+
+			for _, child := range childKeys(p.Get(key).Value()) {
+				next = append(next, joinKey(key, escapeSeparators(child, separator), separator))
+			}
+		}
+
+		keys = next
+	}
+
+	res := make(map[string]Value, len(keys))
+	for _, key := range keys {
+		if v := p.Get(key); v.HasValue() {
+			res[key] = v
+		}
+	}
+
+	return res
+}
+
+// GetFirst returns the value of the first key with a value, e.g. to read
+// a new key falling back to a deprecated one during migrations:
+//
+// 	host := config.GetFirst(p, "storage.host", "db.host").String()
+//
+// It returns the value of the first key if none of them has a value.
+func GetFirst(p Provider, keys ...string) Value {
+	if len(keys) == 0 {
+		return NewValue(p, Root, nil, false)
+	}
+
+	for _, key := range keys {
+		if v := p.Get(key); v.HasValue() {
+			return v
+		}
+	}
+
+	return p.Get(keys[0])
+}
+
+// Keys returns sorted keys of all the scalar values, empty collections and
+// nulls of a provider, e.g. to complete overrides in command line tools.
+// Separators in map keys are escaped.
+func Keys(p Provider) []string {
+	var keys []string
+	var walk func(key string, value interface{})
+	separator := separatorOf(p)
+	walk = func(key string, value interface{}) {
+		children := childKeys(value)
+		if len(children) == 0 {
+			if key != Root {
+				keys = append(keys, key)
+			}
+
+			return
+		}
+
+		for _, child := range children {
+			childKey := joinKey(key, escapeSeparators(child, separator), separator)
+			walk(childKey, p.Get(childKey).Value())
+		}
+	}
+
+	walk(Root, p.Get(Root).Value())
+	sort.Strings(keys)
+	return keys
+}
+
+// Query evaluates a JMESPath expression (http://jmespath.org) over the whole
+// configuration of a provider. The configuration is queried in its JSON form,
+// i.e. map keys are strings and numbers are float64, e.g.
+//
+// 	Query(p, "modules.*.port | max(@)")
+//
+// returns the highest port of all the modules.
+func Query(p Provider, expression string) (interface{}, error) {
+	jp, err := jmespath.Compile(expression)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't compile query %q", expression)
+	}
+
+	b, err := json.Marshal(stringifyKeys(p.Get(Root).Value()))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't convert configuration to JSON")
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, errors.Wrap(err, "can't convert configuration to JSON")
+	}
+
+	return jp.Search(data)
+}
+
+// stringifyKeys recursively converts map keys to strings, because JSON
+// encoder fails to serialize maps with interface{} keys.
+func stringifyKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			res[fmt.Sprint(key)] = stringifyKeys(val)
+		}
+
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, val := range v {
+			res[i] = stringifyKeys(val)
+		}
+
+		return res
+	}
+
+	return value
+}
+
+// childKeys returns map keys or array indexes of a collection.
+func childKeys(value interface{}) []string {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Map:
+		keys := make([]string, 0, rv.Len())
+		for _, k := range rv.MapKeys() {
+			keys = append(keys, fmt.Sprint(k.Interface()))
+		}
+
+		sort.Strings(keys)
+		return keys
+	case reflect.Slice, reflect.Array:
+		keys := make([]string, rv.Len())
+		for i := range keys {
+			keys[i] = strconv.Itoa(i)
+		}
+
+		return keys
+	}
+
+	return nil
+}
+
+// splitKey splits a key into segments by separators not escaped with
+// a backslash.
+func splitKey(key string, separator string) []string {
+	var segments []string
+	for last := lastSeparatorIndex(key, separator); last > 0; last = lastSeparatorIndex(key, separator) {
+		segments = append(segments, key[last+len(separator):])
+		key = key[:last]
+	}
+
+	segments = append(segments, key)
+	for i, j := 0, len(segments)-1; i < j; i, j = i+1, j-1 {
+		segments[i], segments[j] = segments[j], segments[i]
+	}
+
+	return segments
+}
+
+func joinKey(prefix string, key string, separator string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + separator + key
+}
+
+// escapeSeparators adds backslashes in front of separators.
+func escapeSeparators(key string, separator string) string {
+	return strings.Replace(key, separator, string(_escape)+separator, -1)
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func valuesOf(m map[string]Value) map[string]interface{} {
+	res := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		res[k] = v.Value()
+	}
+
+	return res
+}
+
+func TestGetAll(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+modules:
+  http:
+    port: 8080
+  rpc:
+    port: 9090
+  metrics.v2:
+    port: 7070
+  logging:
+    level: info
+clusters:
+  - name: east
+    hosts: [a, b]
+  - name: west
+    hosts: [c]
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	assert.Equal(t, map[string]interface{}{
+		"modules.http.port":        8080,
+		"modules.rpc.port":         9090,
+		`modules.metrics\.v2.port`: 7070,
+	}, valuesOf(GetAll(p, "modules.*.port")))
+
+	assert.Equal(t, map[string]interface{}{
+		"clusters.0.hosts.0": "a",
+		"clusters.0.hosts.1": "b",
+		"clusters.1.hosts.0": "c",
+	}, valuesOf(GetAll(p, "clusters.*.hosts.*")))
+
+	assert.Equal(t, map[string]interface{}{"clusters.1.name": "west"}, valuesOf(GetAll(p, "clusters.1.name")))
+	assert.Len(t, GetAll(p, "*"), 2)
+	assert.Empty(t, GetAll(p, "modules.*.missing"))
+	assert.Empty(t, GetAll(p, "missing.*"))
+	assert.Empty(t, GetAll(p, "modules.http.port.*"))
+}
+
+func TestGetAllWithCustomSeparator(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromReaderWithSeparator("/", bytes.NewBufferString(`
+hosts:
+  my.host.com: 80
+  a/b: 81
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	assert.Equal(t, map[string]interface{}{
+		"hosts/my.host.com": 80,
+		`hosts/a\/b`:        81,
+	}, valuesOf(GetAll(p, "hosts/*")))
+}
+
+func TestGetAllFromProviderGroup(t *testing.T) {
+	t.Parallel()
+
+	f, err := NewStaticProvider(map[string]interface{}{"modules": map[string]int{"a": 1, "b": 2}})
+	require.NoError(t, err, "Can't create a static provider")
+
+	s, err := NewStaticProvider(map[string]interface{}{"modules": map[string]int{"b": 3, "c": 4}})
+	require.NoError(t, err, "Can't create a static provider")
+
+	pg, err := NewProviderGroup("group", f, s)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"modules.a": 1,
+		"modules.b": 3,
+		"modules.c": 4,
+	}, valuesOf(GetAll(pg, "modules.*")))
+}
+
+func TestSplitKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{""}, splitKey("", "."))
+	assert.Equal(t, []string{"a"}, splitKey("a", "."))
+	assert.Equal(t, []string{"a", "b", "c"}, splitKey("a.b.c", "."))
+	assert.Equal(t, []string{"a", `b\.c`}, splitKey(`a.b\.c`, "."))
+	assert.Equal(t, []string{"a", "b"}, splitKey("a::b", "::"))
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+modules:
+  http:
+    port: 8080
+  rpc:
+    port: 9090
+clusters:
+  - name: east
+    size: 3
+  - name: west
+    size: 5
+1: one
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	tests := []struct {
+		expr     string
+		expected interface{}
+	}{
+		{"modules.http.port", float64(8080)},
+		{"max(modules.*.port)", float64(9090)},
+		{"clusters[?size > `4`].name", []interface{}{"west"}},
+		{"clusters[*].{n: name}", []interface{}{
+			map[string]interface{}{"n": "east"},
+			map[string]interface{}{"n": "west"},
+		}},
+		{`"1"`, "one"},
+		{"missing", nil},
+	}
+
+	for _, tt := range tests {
+		res, err := Query(p, tt.expr)
+		require.NoError(t, err, "Query %q failed", tt.expr)
+		assert.Equal(t, tt.expected, res, "Unexpected result for %q", tt.expr)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`a: b`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	_, err = Query(p, "a[")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `can't compile query "a["`)
+
+	_, err = Query(p, "abs(a)")
+	require.Error(t, err)
+
+	_, err = Query(newValueProvider(make(chan int)), "a")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't convert configuration to JSON")
+}
+
+func TestQueryEmptyProvider(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes(nil)
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	res, err := Query(p, "a")
+	require.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestKeys(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+modules:
+  http:
+    port: 8080
+  metrics.v2: true
+servers:
+  - host: a
+  - b
+empty: {}
+none: ~
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	assert.Equal(t, []string{
+		"empty",
+		"modules.http.port",
+		`modules.metrics\.v2`,
+		"none",
+		"servers.0.host",
+		"servers.1",
+	}, Keys(p))
+
+	s, err := NewYAMLProviderFromReaderWithSeparator("/", bytes.NewBufferString("a:\n  b.c: 1"))
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, []string{"a/b.c"}, Keys(s))
+
+	e, err := NewYAMLProviderFromBytes(nil)
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Empty(t, Keys(e))
+}
+
+func TestGetFirst(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte("db: {host: old, port: 5432}\nstorage: {host: new, user: ~}"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	assert.Equal(t, "new", GetFirst(p, "storage.host", "db.host").Value())
+	assert.Equal(t, 5432, GetFirst(p, "storage.port", "db.port").Value())
+	assert.True(t, GetFirst(p, "storage.user", "db.user").IsNull(), "Null values are present")
+
+	v := GetFirst(p, "storage.name", "db.name")
+	assert.False(t, v.HasValue())
+	assert.Equal(t, "storage.name", v.key, "Expected the first key without values")
+	assert.False(t, GetFirst(p).HasValue())
+}
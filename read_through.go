@@ -0,0 +1,158 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// NewReadThroughProvider creates a provider caching values of an expensive
+// provider, e.g. one backed by Vault or SSM, for the TTL since each key is
+// read. Concurrent Gets of the same uncached key wait for a single Get of
+// the underlying provider. Missing values are cached too, so bursts of Gets
+// for missing keys don't reach the backend either. Expired values are
+// evicted at most once per TTL, so the cache holds keys read within the last
+// two TTLs.
+func NewReadThroughProvider(p Provider, ttl time.Duration) (Provider, error) {
+	if p == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	if ttl <= 0 {
+		return nil, fmt.Errorf("TTL must be positive, got %v", ttl)
+	}
+
+	return &readThroughProvider{
+		Provider: p,
+		ttl:      ttl,
+		now:      time.Now,
+		entries:  make(map[string]*readThroughEntry),
+	}, nil
+}
+
+type readThroughProvider struct {
+	Provider
+
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*readThroughEntry
+	sweep   time.Time // of expired entries
+}
+
+// readThroughEntry is a cached value, or a Get in flight until done is
+// closed.
+type readThroughEntry struct {
+	done    chan struct{}
+	value   Value
+	expires time.Time
+}
+
+// Name returns a name of the underlying provider.
+func (p *readThroughProvider) Name() string {
+	return fmt.Sprintf("read through %q", p.Provider.Name())
+}
+
+func (p *readThroughProvider) Get(key string) Value {
+	p.mu.Lock()
+	e, ok := p.entries[key]
+	if ok && (!e.fetched() || p.now().Before(e.expires)) {
+		p.mu.Unlock()
+
+		// Values are set before done is closed, so they can be read without
+		// the lock after it.
+		<-e.done
+		return e.value
+	}
+
+	p.evict()
+	// Waiters get a missing value if the underlying provider panics.
+	e = &readThroughEntry{done: make(chan struct{}), value: NewValue(p, key, nil, false)}
+	p.entries[key] = e
+	p.mu.Unlock()
+
+	// Waiters are released even if the underlying provider panics.
+	defer close(e.done)
+	v := p.Provider.Get(key)
+
+	// Route lookups of children and fields through the cache too.
+	v.provider = p
+	v.root = nil
+	e.value, e.expires = v, p.now().Add(p.ttl)
+	return v
+}
+
+// evict deletes expired entries, if they weren't deleted within the TTL. It
+// must be called with the lock held.
+func (p *readThroughProvider) evict() {
+	now := p.now()
+	if now.Before(p.sweep) {
+		return
+	}
+
+	for key, e := range p.entries {
+		if e.fetched() && !now.Before(e.expires) {
+			delete(p.entries, key)
+		}
+	}
+
+	p.sweep = now.Add(p.ttl)
+}
+
+// fetched returns true if the Get of the entry is done.
+func (e *readThroughEntry) fetched() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *readThroughProvider) separator() string {
+	return separatorOf(p.Provider)
+}
+
+// Healthy returns health of the underlying provider.
+func (p *readThroughProvider) Healthy() error {
+	return Healthy(p.Provider)
+}
+
+// Metadata returns metadata of the underlying provider.
+func (p *readThroughProvider) Metadata(key string) Metadata {
+	return metadataOf(p.Provider, key)
+}
+
+// Describe returns the description of the underlying provider under the
+// name of the read-through provider.
+func (p *readThroughProvider) Describe() Description {
+	d := Describe(p.Provider)
+	d.Name = p.Name()
+	return d
+}
+
+func (p *readThroughProvider) keyOrder() *keyOrder {
+	return keyOrderOf(p.Provider)
+}
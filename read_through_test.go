@@ -0,0 +1,167 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingProvider counts Gets and blocks them until release is closed.
+type countingProvider struct {
+	Provider
+
+	gets    int32
+	release chan struct{}
+}
+
+func (p *countingProvider) Get(key string) Value {
+	atomic.AddInt32(&p.gets, 1)
+	<-p.release
+	return p.Provider.Get(key)
+}
+
+func TestReadThroughProvider(t *testing.T) {
+	t.Parallel()
+
+	base, err := NewYAMLProviderFromBytes([]byte("db: {password: secret}"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	backend := &countingProvider{Provider: base, release: make(chan struct{})}
+	p, err := NewReadThroughProvider(backend, time.Minute)
+	require.NoError(t, err)
+
+	now := time.Now()
+	rt := p.(*readThroughProvider)
+	rt.now = func() time.Time { return now }
+
+	var wg sync.WaitGroup
+	values := make([]Value, 10)
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			values[i] = p.Get("db.password")
+		}(i)
+	}
+
+	// Let the goroutines pile up on the same key.
+	time.Sleep(10 * time.Millisecond)
+	close(backend.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&backend.gets), "Expected a single backend call")
+	for _, v := range values {
+		assert.Equal(t, "secret", v.Value())
+	}
+
+	assert.False(t, p.Get("missing").HasValue())
+	assert.False(t, p.Get("missing").HasValue())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&backend.gets), "Expected missing values to be cached")
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, "secret", p.Get("db.password").Value())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&backend.gets), "Expected expired values to be read again")
+
+	assert.Equal(t, `read through "cached \"yaml\""`, p.Name())
+	assert.Equal(t, "secret", p.Get("db").Get("password").Value())
+}
+
+func TestReadThroughProviderErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewReadThroughProvider(nil, time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "received a nil provider")
+
+	_, err = NewReadThroughProvider(NopProvider{}, 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TTL must be positive")
+}
+
+func TestReadThroughProviderEviction(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewReadThroughProvider(NopProvider{}, time.Minute)
+	require.NoError(t, err)
+
+	now := time.Now()
+	rt := p.(*readThroughProvider)
+	rt.now = func() time.Time { return now }
+
+	for _, key := range []string{"a", "b", "c"} {
+		p.Get(key)
+	}
+
+	now = now.Add(2 * time.Minute)
+	p.Get("d")
+	assert.Len(t, rt.entries, 1, "Expected expired entries to be evicted")
+}
+
+// panickingProvider panics in Gets after release is closed.
+type panickingProvider struct {
+	NopProvider
+
+	release chan struct{}
+}
+
+func (p panickingProvider) Get(string) Value {
+	<-p.release
+	panic("backend is broken")
+}
+
+func TestReadThroughProviderPanics(t *testing.T) {
+	t.Parallel()
+
+	backend := panickingProvider{release: make(chan struct{})}
+	p, err := NewReadThroughProvider(backend, time.Minute)
+	require.NoError(t, err)
+
+	go func() {
+		defer func() { recover() }()
+		p.Get("a")
+	}()
+
+	// Let the first Get reach the backend.
+	time.Sleep(10 * time.Millisecond)
+	waited := make(chan Value, 1)
+	go func() {
+		// Fail instead of crashing if the Get doesn't wait for the first one.
+		defer func() {
+			if recover() != nil {
+				waited <- Value{}
+			}
+		}()
+
+		waited <- p.Get("a")
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(backend.release)
+
+	v := <-waited
+	assert.False(t, v.HasValue())
+	assert.Equal(t, p.Name(), v.Source(), "Expected waiters to get a value of the provider")
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// SourceAt adds a YAML source of the size, that is read with ReadAt, e.g.
+// a file opened with golang.org/x/exp/mmap:
+//
+// 	m, err := mmap.Open("generated.yaml")
+// 	...
+// 	p, err := config.NewYAML(config.SourceAt(m, int64(m.Len())))
+//
+// The content is copied once, into a buffer allocated for the size. Use
+// SourceBytes to decode a mapped region without copying it.
+func SourceAt(r io.ReaderAt, size int64) YAMLOption {
+	return func(o *yamlOptions) {
+		o.sources = append(o.sources, yamlSource{reader: io.NewSectionReader(r, 0, size)})
+	}
+}
+
+// SourceBytes adds YAML sources, that are decoded in place rather than
+// copied into buffers, e.g. regions mapped with syscall.Mmap:
+//
+// 	b, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+// 	...
+// 	p, err := config.NewYAML(config.SourceBytes(b))
+// 	syscall.Munmap(b)
+//
+// Providers don't keep references to the slices, so they can be unmapped
+// once the provider is created. Sources are still copied if they need to be
+// rewritten, e.g. with WithExpand, or kept, e.g. with WithKeyOrder.
+func SourceBytes(yamls ...[]byte) YAMLOption {
+	return func(o *yamlOptions) {
+		for _, yml := range yamls {
+			o.sources = append(o.sources, yamlSource{reader: newByteSource(yml)})
+		}
+	}
+}
+
+// byteSource is a reader of a slice, that can hand out the slice itself.
+type byteSource struct {
+	*bytes.Reader
+
+	b []byte
+}
+
+func newByteSource(b []byte) *byteSource {
+	return &byteSource{Reader: bytes.NewReader(b), b: b}
+}
+
+// Close does nothing, so sources opened from content read up front can be
+// byte sources.
+func (*byteSource) Close() error {
+	return nil
+}
+
+// readAll reads the reader to the end like ioutil.ReadAll, but returns
+// content of byte sources as is and allocates the buffer once if the size of
+// the content is known.
+func readAll(r io.Reader) ([]byte, error) {
+	if b, ok, err := bytesOf(r); ok {
+		return b, err
+	}
+
+	// Leave room for the read reporting the end of the content.
+	n := int64(bytes.MinRead)
+	if size, ok := sizeOf(r); ok {
+		n += size
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(int(n))
+	_, err := buf.ReadFrom(r)
+	return buf.Bytes(), err
+}
+
+// bytesOf consumes an unread byte source behind the reader and returns its
+// content, counting and limiting it as reads would.
+func bytesOf(r io.Reader) ([]byte, bool, error) {
+	switch v := r.(type) {
+	case *byteSource:
+		if v.Len() != len(v.b) {
+			return nil, false, nil
+		}
+
+		v.Seek(0, io.SeekEnd)
+		return v.b, true, nil
+	case namedReader:
+		return bytesOf(v.Reader)
+	case *countingReader:
+		b, ok, err := bytesOf(v.r)
+		v.n += int64(len(b))
+		return b, ok, err
+	case *limitedReader:
+		b, ok, err := bytesOf(v.r)
+		if ok && err == nil && int64(len(b)) > v.n {
+			v.n = -1
+			return nil, true, v.err
+		}
+
+		v.n -= int64(len(b))
+		return b, ok, err
+	}
+
+	return nil, false, nil
+}
+
+// sizeOf returns the number of bytes left to read from the reader, if it
+// can be known without reading.
+func sizeOf(r io.Reader) (int64, bool) {
+	switch v := r.(type) {
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil || offset > info.Size() {
+			return 0, false
+		}
+
+		return info.Size() - offset, true
+	case *io.SectionReader:
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+
+		return v.Size() - offset, true
+	case interface {
+		Len() int
+	}:
+		// E.g. *bytes.Reader, *strings.Reader and *bytes.Buffer.
+		return int64(v.Len()), true
+	case namedReader:
+		return sizeOf(v.Reader)
+	case *countingReader:
+		return sizeOf(v.r)
+	case *limitedReader:
+		// Don't allocate for more than the limit, reading fails past it.
+		size, ok := sizeOf(v.r)
+		if ok && size > v.n+1 {
+			size = v.n + 1
+		}
+
+		return size, ok
+	}
+
+	return 0, false
+}
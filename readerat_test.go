@@ -0,0 +1,176 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceAt(t *testing.T) {
+	t.Parallel()
+
+	content := "a: 1\nb: two"
+	p, err := NewYAML(
+		SourceAt(strings.NewReader(content), int64(len(content))),
+		Source(strings.NewReader("b: 2")),
+	)
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, 1, p.Get("a").Value())
+	assert.Equal(t, 2, p.Get("b").Value())
+}
+
+func TestSourceAtSection(t *testing.T) {
+	t.Parallel()
+
+	content := "a: 1\nb: 2"
+	p, err := NewYAML(SourceAt(strings.NewReader(content), 4))
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, 1, p.Get("a").Value())
+	assert.False(t, p.Get("b").HasValue(), "Content past the size must not be read")
+}
+
+func TestSourceBytes(t *testing.T) {
+	t.Parallel()
+
+	var report LoadReport
+	p, err := NewYAML(
+		SourceBytes([]byte("a: 1\nb: two"), []byte("b: 2")),
+		WithLoadReport(&report),
+	)
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, 1, p.Get("a").Value())
+	assert.Equal(t, 2, p.Get("b").Value())
+	assert.Equal(t, int64(11), report.Sources[0].Bytes)
+
+	_, err = NewYAML(SourceBytes([]byte("a: 1\nb: two")), WithLimits(Limits{MaxFileSize: 4}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "size of the source exceeds the limit of 4 bytes")
+
+	_, err = NewYAML(SourceBytes([]byte("a: 1")), WithLimits(Limits{MaxFileSize: 4}))
+	assert.NoError(t, err, "Sources of the maximum size should be loaded")
+}
+
+func TestReadAllByteSource(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("a: 1")
+	r := namedReader{Reader: &limitedReader{r: &countingReader{r: newByteSource(content)}, n: 10}, name: "a"}
+
+	b, err := readAll(r)
+	require.NoError(t, err, "Can't read the byte source")
+	assert.True(t, &content[0] == &b[0], "Content of byte sources must not be copied")
+
+	partial := newByteSource(content)
+	_, err = partial.Read(make([]byte, 1))
+	require.NoError(t, err, "Can't read the byte source")
+
+	b, err = readAll(partial)
+	require.NoError(t, err, "Can't read the byte source")
+	assert.Equal(t, ": 1", string(b), "Partially read byte sources should be read as usual")
+}
+
+// Not parallel, so allocations of other tests don't count.
+func TestReadAllWithinLimit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestReadAllWithinLimit")
+	require.NoError(t, err, "Can't create a temporary directory")
+	defer os.RemoveAll(dir)
+
+	// A sparse file, that would take a gigabyte of memory to read.
+	name := filepath.Join(dir, "large.yaml")
+	f, err := os.Create(name)
+	require.NoError(t, err, "Can't create a file")
+	require.NoError(t, f.Truncate(1<<30), "Can't resize the file")
+	require.NoError(t, f.Close(), "Can't close the file")
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err = NewYAML(File(name), WithLimits(Limits{MaxFileSize: 1024}))
+	runtime.ReadMemStats(&after)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds the limit of 1024 bytes")
+	assert.True(t, after.TotalAlloc-before.TotalAlloc < 1<<20,
+		"Buffers must not be allocated past the limit, allocated %d bytes", after.TotalAlloc-before.TotalAlloc)
+}
+
+func TestReadAllAllocatesOnce(t *testing.T) {
+	t.Parallel()
+
+	content := strings.Repeat("key: value\n", 10000)
+	section := io.NewSectionReader(strings.NewReader(content), 0, int64(len(content)))
+
+	b, err := readAll(section)
+	require.NoError(t, err, "Can't read the section")
+	assert.Equal(t, content, string(b))
+	assert.True(t, cap(b) < len(content)*11/10, "Buffer must be allocated for the size, got capacity %d", cap(b))
+}
+
+func TestSizeOf(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/fs/base.yaml")
+	require.NoError(t, err, "Can't open the file")
+	defer f.Close()
+
+	info, err := f.Stat()
+	require.NoError(t, err, "Can't stat the file")
+
+	partial := strings.NewReader("abcdef")
+	_, err = partial.Read(make([]byte, 2))
+	require.NoError(t, err, "Can't read the reader")
+
+	section := io.NewSectionReader(strings.NewReader("abcdef"), 1, 4)
+	_, err = section.Read(make([]byte, 1))
+	require.NoError(t, err, "Can't read the section")
+
+	tests := []struct {
+		msg  string
+		r    io.Reader
+		size int64
+		ok   bool
+	}{
+		{"file", f, info.Size(), true},
+		{"partially read reader", partial, 4, true},
+		{"partially read section", section, 3, true},
+		{"named reader", namedReader{Reader: bytes.NewBufferString("abc"), name: "a"}, 3, true},
+		{"counting reader", &countingReader{r: strings.NewReader("ab")}, 2, true},
+		{"limited reader", &limitedReader{r: strings.NewReader("ab"), n: 5}, 2, true},
+		{"limited reader over the limit", &limitedReader{r: strings.NewReader("abcdef"), n: 2}, 3, true},
+		{"limited unknown reader", &limitedReader{r: io.MultiReader(), n: 2}, 0, false},
+		{"unknown", io.MultiReader(strings.NewReader("ab")), 0, false},
+	}
+
+	for _, tt := range tests {
+		size, ok := sizeOf(tt.r)
+		assert.Equal(t, tt.ok, ok, "Unexpected result for %s", tt.msg)
+		assert.Equal(t, tt.size, size, "Unexpected size for %s", tt.msg)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	_refPrefix = "ref:"
+	_refStart  = "${" + _refPrefix
+	_refEnd    = "}"
+)
+
+// NewProviderWithReferences creates a provider, where ${ref:key} sequences in
+// string values are replaced with values of the referenced keys, e.g.
+//
+// 	db:
+// 	  host: localhost
+// 	  port: 5432
+// 	dsn: postgres://${ref:db.host}:${ref:db.port}/app
+// 	replica:
+// 	  port: ${ref:db.port}
+//
+// References are resolved after composition, so wrapping a provider group
+// lets values in one provider reference values of the other providers,
+// e.g. files referencing values overridden by environment specific files.
+// A string consisting of a single reference gets the referenced value as is,
+// including maps and sequences, other references are replaced with text
+// representations of scalar values. Referenced values can contain references
+// too, but cycles and missing keys are reported as errors. The sequence
+// $${ref: is replaced with a literal ${ref:. Providers expanding variables
+// keep references as they are, but replace $$ with $ too, so the sequence is
+// $$$${ref: in their sources.
+func NewProviderWithReferences(p Provider) (Provider, error) {
+	if p == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	r := referencer{
+		provider:   p,
+		separator:  separatorOf(p),
+		resolved:   make(map[string]interface{}),
+		inProgress: make(map[string]bool),
+	}
+
+	root, err := r.expand(Root, p.Get(Root).Value())
+	if err != nil {
+		return nil, err
+	}
+
+	return newDerivedProvider(p, root)
+}
+
+type referencer struct {
+	provider   Provider
+	separator  string
+	resolved   map[string]interface{}
+	inProgress map[string]bool
+}
+
+// value returns the referenced value with all the references in it resolved.
+func (r *referencer) value(ref string, from string) (interface{}, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("empty reference in %q", from)
+	}
+
+	if v, ok := r.resolved[ref]; ok {
+		return v, nil
+	}
+
+	if r.inProgress[ref] {
+		return nil, fmt.Errorf("reference cycle detected at %q referenced in %q", ref, from)
+	}
+
+	v := r.provider.Get(ref)
+	if !v.HasValue() {
+		return nil, fmt.Errorf("can't find %q referenced in %q", ref, from)
+	}
+
+	r.inProgress[ref] = true
+	defer delete(r.inProgress, ref)
+
+	res, err := r.expand(ref, v.Value())
+	if err != nil {
+		return nil, err
+	}
+
+	r.resolved[ref] = res
+	return res, nil
+}
+
+// expand returns a copy of the node at the key with all the references
+// resolved in it and its children.
+func (r *referencer) expand(key string, node interface{}) (interface{}, error) {
+	switch n := node.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(n))
+		for k, v := range n {
+			child, err := r.expand(joinKey(key, escapeSeparators(fmt.Sprint(k), r.separator), r.separator), v)
+			if err != nil {
+				return nil, err
+			}
+
+			m[k] = child
+		}
+
+		return m, nil
+	case []interface{}:
+		s := make([]interface{}, len(n))
+		for i, v := range n {
+			child, err := r.expand(joinKey(key, strconv.Itoa(i), r.separator), v)
+			if err != nil {
+				return nil, err
+			}
+
+			s[i] = child
+		}
+
+		return s, nil
+	case string:
+		return r.interpolate(key, n)
+	}
+
+	return node, nil
+}
+
+// interpolate replaces references in a string value at the key.
+func (r *referencer) interpolate(key string, s string) (interface{}, error) {
+	if !strings.Contains(s, _refStart) {
+		return s, nil
+	}
+
+	// A single reference keeps the type of the referenced value.
+	if strings.HasPrefix(s, _refStart) && strings.Index(s, _refEnd) == len(s)-len(_refEnd) {
+		return r.value(s[len(_refStart):len(s)-len(_refEnd)], key)
+	}
+
+	var buf bytes.Buffer
+	for {
+		start := strings.Index(s, _refStart)
+		if start == -1 {
+			buf.WriteString(s)
+			break
+		}
+
+		// Escaped reference.
+		if start > 0 && s[start-1] == '$' {
+			buf.WriteString(s[:start-1] + _refStart)
+			s = s[start+len(_refStart):]
+			continue
+		}
+
+		end := strings.Index(s[start:], _refEnd)
+		if end == -1 {
+			return nil, fmt.Errorf("unterminated reference in %q", key)
+		}
+
+		v, err := r.value(s[start+len(_refStart):start+end], key)
+		if err != nil {
+			return nil, err
+		}
+
+		switch v.(type) {
+		case map[interface{}]interface{}, []interface{}:
+			return nil, fmt.Errorf("can't interpolate %T referenced in %q", v, key)
+		}
+
+		buf.WriteString(s[:start])
+		buf.WriteString(fmt.Sprint(v))
+		s = s[start+end+len(_refEnd):]
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProviderWithReferences(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+db:
+  host: localhost
+  port: 5432
+dsn: postgres://${ref:db.host}:${ref:db.port}/app
+replica:
+  port: ${ref:db.port}
+  db: ${ref:db}
+chain: ${ref:dsn}
+servers:
+  - ${ref:db.host}
+escaped: $${ref:db.host} costs $$5
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	r, err := NewProviderWithReferences(p)
+	require.NoError(t, err)
+
+	assert.Equal(t, "postgres://localhost:5432/app", r.Get("dsn").Value())
+	assert.Equal(t, 5432, r.Get("replica.port").Value())
+	assert.Equal(t, "localhost", r.Get("replica.db.host").Value())
+	assert.Equal(t, "postgres://localhost:5432/app", r.Get("chain").Value())
+	assert.Equal(t, "localhost", r.Get("servers.0").Value())
+	assert.Equal(t, "${ref:db.host} costs $$5", r.Get("escaped").Value())
+	assert.Equal(t, "${ref:db.port}", p.Get("replica.port").Value())
+}
+
+func TestNewProviderWithReferencesInGroup(t *testing.T) {
+	t.Parallel()
+
+	base, err := NewYAMLProviderFromBytes([]byte(`
+host: localhost
+url: http://${ref:host}/
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	prod, err := NewStaticProvider(map[string]string{"host": "example.com"})
+	require.NoError(t, err, "Can't create a static provider")
+
+	pg, err := NewProviderGroup("group", base, prod)
+	require.NoError(t, err)
+
+	r, err := NewProviderWithReferences(pg)
+	require.NoError(t, err)
+	assert.Equal(t, "http://example.com/", r.Get("url").Value())
+}
+
+func TestNewProviderWithReferencesAndExpand(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(key string) (string, bool) {
+		if key == "PORT" {
+			return "5432", true
+		}
+
+		return "", false
+	}
+
+	p, err := NewYAMLProviderFromReaderWithExpand(lookup, strings.NewReader(`
+db:
+  host: h
+dsn: pg://${ref:db.host}:${PORT}/x
+literal: $$$${ref:db.host}
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	r, err := NewProviderWithReferences(p)
+	require.NoError(t, err)
+	assert.Equal(t, "pg://h:5432/x", r.Get("dsn").Value())
+	assert.Equal(t, "${ref:db.host}", r.Get("literal").Value())
+}
+
+func TestNewProviderWithReferencesErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewProviderWithReferences(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "received a nil provider")
+
+	tests := map[string]string{
+		"a: ${ref:b}":              `can't find "b" referenced in "a"`,
+		"a: ${ref:b}\nb: ${ref:a}": "reference cycle detected",
+		"a: x${ref:b":              `unterminated reference in "a"`,
+		"a: x${ref:}":              `empty reference in "a"`,
+		"a: x${ref:b}\nb: [1]":     `can't interpolate []interface {} referenced in "a"`,
+		"a:\n  b: ${ref:a}":        "reference cycle detected",
+	}
+
+	for yaml, msg := range tests {
+		p, err := NewYAMLProviderFromBytes([]byte(yaml))
+		require.NoError(t, err, "Can't create a YAML provider")
+
+		_, err = NewProviderWithReferences(p)
+		require.Error(t, err, "Expected an error for %q", yaml)
+		assert.Contains(t, err.Error(), msg, "Unexpected error for %q", yaml)
+	}
+}
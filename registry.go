@@ -0,0 +1,169 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// _typeKey is a key with the name of an interface implementation to populate.
+const _typeKey = "type"
+
+var _types = struct {
+	sync.RWMutex
+	impls map[reflect.Type]map[string]reflect.Type
+}{impls: make(map[reflect.Type]map[string]reflect.Type)}
+
+// RegisterType registers a named implementation of an interface, so
+// Populate can decode configuration into fields of the interface type. The
+// interface is passed as a nil pointer to it, e.g. for
+//
+// 	type Sink interface{ Write([]byte) error }
+//
+// 	config.RegisterType((*Sink)(nil), "s3", S3Sink{})
+//
+// Sink fields are populated with S3Sink values for configuration like
+//
+// 	sink:
+// 	  type: s3
+// 	  bucket: logs
+//
+// If only a pointer to the implementation satisfies the interface, fields are
+// populated with pointers. Registration is usually done in init functions.
+func RegisterType(iface interface{}, name string, impl interface{}) error {
+	t := reflect.TypeOf(iface)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+		return fmt.Errorf("expected a pointer to an interface, got %T", iface)
+	}
+
+	if name == "" {
+		return errors.New("empty type name")
+	}
+
+	if impl == nil {
+		return errors.New("received a nil implementation")
+	}
+
+	t = t.Elem()
+	it := reflect.TypeOf(impl)
+	if !it.Implements(t) && !reflect.PtrTo(it).Implements(t) {
+		return fmt.Errorf("%q doesn't implement %q", it, t)
+	}
+
+	_types.Lock()
+	defer _types.Unlock()
+
+	impls, ok := _types.impls[t]
+	if !ok {
+		impls = make(map[string]reflect.Type)
+		_types.impls[t] = impls
+	}
+
+	if prev, ok := impls[name]; ok {
+		return fmt.Errorf("type %q of %q is already registered for %q", name, t, prev)
+	}
+
+	impls[name] = it
+	return nil
+}
+
+// registeredType returns an implementation of an interface registered with
+// the name and names of all the implementations registered for the interface.
+func registeredType(iface reflect.Type, name string) (reflect.Type, []string) {
+	_types.RLock()
+	defer _types.RUnlock()
+
+	impls := _types.impls[iface]
+	if impl, ok := impls[name]; ok {
+		return impl, nil
+	}
+
+	names := make([]string, 0, len(impls))
+	for n := range impls {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+	return nil, names
+}
+
+// typeName returns the name of an implementation under the type key of a
+// mapping. Keys are case insensitive, like keys of lookups are, but the
+// exact key wins if there are several ones, e.g. type and Type.
+func typeName(m map[interface{}]interface{}) (string, bool) {
+	if v, ok := m[_typeKey]; ok {
+		name, ok := v.(string)
+		return name, ok
+	}
+
+	for k, v := range m {
+		if s, ok := k.(string); ok && strings.EqualFold(s, _typeKey) {
+			name, ok := v.(string)
+			return name, ok
+		}
+	}
+
+	return "", false
+}
+
+// registered sets an interface value to an implementation named by the type
+// key of the configuration, if there are implementations registered for the
+// interface. Returns false if the value wasn't handled.
+func (d *decoder) registered(key string, value reflect.Value, src interface{}) (bool, error) {
+	m, ok := src.(map[interface{}]interface{})
+	if !ok {
+		return false, nil
+	}
+
+	name, ok := typeName(m)
+	if !ok {
+		return false, nil
+	}
+
+	iface := value.Type()
+	impl, names := registeredType(iface, name)
+	if impl == nil {
+		if len(names) == 0 {
+			return false, nil
+		}
+
+		return true, errorWithKey(fmt.Errorf("unknown %q type %q, registered types: %s",
+			iface, name, strings.Join(names, ", ")), key)
+	}
+
+	ptr := reflect.New(impl)
+	if err := d.unmarshal(key, ptr.Elem(), ""); err != nil {
+		return true, err
+	}
+
+	if impl.Implements(iface) {
+		value.Set(ptr.Elem())
+	} else {
+		value.Set(ptr)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sink interface {
+	Kind() string
+}
+
+type s3Sink struct {
+	Bucket string
+}
+
+func (s s3Sink) Kind() string { return "s3:" + s.Bucket }
+
+type fileSink struct {
+	Path string `default:"/tmp/log"`
+}
+
+func (f *fileSink) Kind() string { return "file:" + f.Path }
+
+func init() {
+	if err := RegisterType((*sink)(nil), "s3", s3Sink{}); err != nil {
+		panic(err)
+	}
+
+	if err := RegisterType((*sink)(nil), "file", fileSink{}); err != nil {
+		panic(err)
+	}
+}
+
+func TestPopulateRegisteredTypes(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+primary:
+  type: s3
+  bucket: logs
+sinks:
+  - type: file
+  - type: s3
+    bucket: archive
+raw:
+  type: s3
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var c struct {
+		Primary sink
+		Sinks   []sink
+		Missing sink
+		Raw     interface{}
+	}
+
+	require.NoError(t, p.Get(Root).Populate(&c))
+	assert.Equal(t, s3Sink{Bucket: "logs"}, c.Primary)
+	require.Len(t, c.Sinks, 2)
+	assert.Equal(t, "file:/tmp/log", c.Sinks[0].Kind())
+	assert.IsType(t, &fileSink{}, c.Sinks[0])
+	assert.Equal(t, "s3:archive", c.Sinks[1].Kind())
+	assert.Nil(t, c.Missing)
+	assert.Equal(t, map[interface{}]interface{}{"type": "s3"}, c.Raw)
+}
+
+func TestPopulateRegisteredTypesMixedCase(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+upper:
+  Type: s3
+  bucket: logs
+both:
+  Type: file
+  type: s3
+  bucket: archive
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var c struct {
+		Upper sink
+		Both  sink
+	}
+
+	require.NoError(t, p.Get(Root).Populate(&c))
+	assert.Equal(t, s3Sink{Bucket: "logs"}, c.Upper)
+	assert.Equal(t, s3Sink{Bucket: "archive"}, c.Both, "Expected the exact key to win")
+}
+
+func TestPopulateUnknownRegisteredType(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+sink:
+  type: kafka
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var c struct {
+		Sink sink
+	}
+
+	err = p.Get(Root).Populate(&c)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown "config.sink" type "kafka", registered types: file, s3`)
+}
+
+func TestRegisterTypeErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		iface interface{}
+		name  string
+		impl  interface{}
+		err   string
+	}{
+		{sink(nil), "a", s3Sink{}, "expected a pointer to an interface, got <nil>"},
+		{&s3Sink{}, "a", s3Sink{}, "expected a pointer to an interface, got *config.s3Sink"},
+		{(*sink)(nil), "", s3Sink{}, "empty type name"},
+		{(*sink)(nil), "a", nil, "received a nil implementation"},
+		{(*sink)(nil), "a", 1, `"int" doesn't implement "config.sink"`},
+		{(*sink)(nil), "s3", s3Sink{}, `type "s3" of "config.sink" is already registered for "config.s3Sink"`},
+	}
+
+	for _, tt := range tests {
+		err := RegisterType(tt.iface, tt.name, tt.impl)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), tt.err)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package render renders files, e.g. haproxy.cfg for a sidecar, from
+// configuration with text/template, like a lightweight consul-template:
+//
+// 	r := render.Renderer{
+// 		Template:    "haproxy.cfg.tmpl",
+// 		Destination: "/etc/haproxy/haproxy.cfg",
+// 		OnChange:    reloadHAProxy,
+// 	}
+//
+// 	changed, err := r.Render(p)
+//
+// Templates access the configuration as their data, e.g. {{.backend.port}},
+// or with the config function, which takes keys with custom separators and
+// array indexes, e.g. {{config "backends[0].host"}}. Destinations are
+// rewritten atomically and only if the content changes, so Render can be
+// called after every configuration reload.
+package render // import "go.uber.org/config/render"
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"go.uber.org/config"
+)
+
+// A Renderer renders a template file to a destination file.
+type Renderer struct {
+	// Template is a name of the text/template file.
+	Template string
+
+	// Destination is a name of the file to write.
+	Destination string
+
+	// Perm is a permission of the destination file, 0644 if it is zero.
+	Perm os.FileMode
+
+	// NormalizeUnits renders durations and sizes in canonical forms, see
+	// config.NormalizeUnits.
+	NormalizeUnits bool
+
+	// OnChange is called after the destination file is rewritten, e.g. to
+	// reload a process reading it. It is optional.
+	OnChange func() error
+}
+
+// Render renders the template with the configuration of the provider and
+// rewrites the destination file if the content changes. It reports whether
+// the file was rewritten.
+func (r Renderer) Render(p config.Provider) (bool, error) {
+	if p == nil {
+		return false, errors.New("received a nil provider")
+	}
+
+	value := func(key string) interface{} {
+		if r.NormalizeUnits {
+			return config.NormalizeUnits(p.Get(key).Value())
+		}
+
+		return p.Get(key).Value()
+	}
+
+	tmpl, err := template.New(filepath.Base(r.Template)).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"config": value}).
+		ParseFiles(r.Template)
+	if err != nil {
+		return false, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, value(config.Root)); err != nil {
+		return false, err
+	}
+
+	if old, err := ioutil.ReadFile(r.Destination); err == nil && bytes.Equal(old, buf.Bytes()) {
+		return false, nil
+	}
+
+	if err := r.write(buf.Bytes()); err != nil {
+		return false, err
+	}
+
+	if r.OnChange != nil {
+		if err := r.OnChange(); err != nil {
+			return true, fmt.Errorf("%q is rewritten, but the change hook failed: %v", r.Destination, err)
+		}
+	}
+
+	return true, nil
+}
+
+// write replaces the destination with a temporary file, so readers never
+// see partially written content.
+func (r Renderer) write(content []byte) error {
+	perm := r.Perm
+	if perm == 0 {
+		perm = 0644
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(r.Destination), "."+filepath.Base(r.Destination))
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(content)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Chmod(tmp.Name(), perm)
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), r.Destination)
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+
+	return err
+}
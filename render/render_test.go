@@ -0,0 +1,144 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package render
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestRender")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	tmpl := filepath.Join(dir, "haproxy.cfg.tmpl")
+	require.NoError(t, ioutil.WriteFile(tmpl, []byte(
+		"{{range .backends}}server {{.host}}:{{.port}}\n{{end}}first {{config \"backends[0].host\"}}\n"), 0644))
+
+	dst := filepath.Join(dir, "haproxy.cfg")
+	var reloads int
+	r := Renderer{
+		Template:    tmpl,
+		Destination: dst,
+		Perm:        0600,
+		OnChange:    func() error { reloads++; return nil },
+	}
+
+	p, err := config.NewYAMLProviderFromBytes([]byte(`
+backends:
+  - host: a
+    port: 80
+  - host: b
+    port: 81
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	changed, err := r.Render(p)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, 1, reloads)
+
+	b, err := ioutil.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "server a:80\nserver b:81\nfirst a\n", string(b))
+
+	info, err := os.Stat(dst)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	changed, err = r.Render(p)
+	require.NoError(t, err)
+	assert.False(t, changed, "Unchanged content shouldn't be rewritten")
+	assert.Equal(t, 1, reloads)
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2, "Temporary files should be removed")
+}
+
+func TestRenderNormalizeUnits(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestRenderNormalizeUnits")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	tmpl := filepath.Join(dir, "app.conf.tmpl")
+	require.NoError(t, ioutil.WriteFile(tmpl, []byte(
+		"timeout {{.timeout}}\nbuffer {{config \"buffer\"}}\n"), 0644))
+
+	p, err := config.NewYAMLProviderFromBytes([]byte("timeout: 60s\nbuffer: 2048KiB"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	dst := filepath.Join(dir, "app.conf")
+	_, err = Renderer{Template: tmpl, Destination: dst, NormalizeUnits: true}.Render(p)
+	require.NoError(t, err)
+
+	b, err := ioutil.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "timeout 1m0s\nbuffer 2MiB\n", string(b))
+}
+
+func TestRenderErrors(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestRenderErrors")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	tmpl := filepath.Join(dir, "a.tmpl")
+	require.NoError(t, ioutil.WriteFile(tmpl, []byte("{{.missing}}"), 0644))
+
+	p, err := config.NewYAMLProviderFromBytes([]byte("a: b"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	r := Renderer{Template: tmpl, Destination: filepath.Join(dir, "a")}
+	_, err = r.Render(p)
+	assert.Error(t, err, "Missing keys should fail")
+
+	_, err = r.Render(nil)
+	assert.Error(t, err)
+
+	_, err = Renderer{Template: filepath.Join(dir, "missing.tmpl")}.Render(p)
+	assert.Error(t, err)
+
+	require.NoError(t, ioutil.WriteFile(tmpl, []byte("{{.a}}"), 0644))
+	r.OnChange = func() error { return errors.New("reload failed") }
+	changed, err := r.Render(p)
+	require.Error(t, err)
+	assert.True(t, changed)
+	assert.Contains(t, err.Error(), "change hook failed: reload failed")
+
+	r.Destination = filepath.Join(dir, "missing", "a")
+	_, err = r.Render(p)
+	assert.Error(t, err)
+}
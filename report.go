@@ -0,0 +1,91 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"io"
+	"time"
+)
+
+// A LoadReport describes how a provider was loaded, e.g. to log it as JSON
+// for support.
+type LoadReport struct {
+	// Sources in the order they are merged.
+	Sources []SourceReport `json:"sources"`
+
+	// Duration of loading all the sources.
+	Duration time.Duration `json:"duration"`
+}
+
+// A SourceReport describes a loaded YAML source.
+type SourceReport struct {
+	// File is a name of the file, empty for readers.
+	File string `json:"file,omitempty"`
+
+	// Bytes read from the source before expansion.
+	Bytes int64 `json:"bytes"`
+
+	// Expansions of ${var} and $var sequences performed.
+	Expansions int `json:"expansions"`
+
+	// Keys of scalars, empty collections and nulls the source defines.
+	Keys int `json:"keys"`
+
+	// Duration of reading and parsing the source.
+	Duration time.Duration `json:"duration"`
+}
+
+// WithLoadReport fills in the report while NewYAML loads the sources, or on
+// the first lookup with WithLazyLoad. The report is reset first and is
+// complete only if loading succeeds.
+func WithLoadReport(r *LoadReport) YAMLOption {
+	return func(o *yamlOptions) {
+		o.settings.report = r
+	}
+}
+
+// countingReader counts bytes read from a reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// countReaders wraps the readers with counting readers, keeping names of
+// the files.
+func countReaders(readers []io.Reader) ([]io.Reader, []*countingReader) {
+	res := make([]io.Reader, len(readers))
+	counters := make([]*countingReader, len(readers))
+	for i, r := range readers {
+		counters[i] = &countingReader{r: r}
+		res[i] = counters[i]
+		if name, disk := readerName(r); name != "" {
+			res[i] = namedReader{Reader: res[i], name: name, disk: disk}
+		}
+	}
+
+	return res, counters
+}
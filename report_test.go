@@ -0,0 +1,68 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLoadReport(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(key string) (string, bool) {
+		return "expanded", true
+	}
+
+	var r LoadReport
+	src := "a: ${A}\nx: {c: $C, d: [1, 2]}\ne: $$literal\n"
+	_, err := NewYAML(
+		File("testdata/fs/base.yaml"),
+		Source(strings.NewReader(src)),
+		WithExpand(lookup),
+		WithLoadReport(&r),
+	)
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	require.Len(t, r.Sources, 2)
+	assert.Equal(t, "testdata/fs/base.yaml", r.Sources[0].File)
+	assert.Equal(t, int64(16), r.Sources[0].Bytes)
+	assert.Equal(t, 0, r.Sources[0].Expansions)
+	assert.Equal(t, 2, r.Sources[0].Keys)
+
+	assert.Empty(t, r.Sources[1].File)
+	assert.Equal(t, int64(len(src)), r.Sources[1].Bytes)
+	assert.Equal(t, 2, r.Sources[1].Expansions, "Literal $$ isn't an expansion")
+	assert.Equal(t, 5, r.Sources[1].Keys)
+	assert.True(t, r.Duration >= r.Sources[1].Duration)
+
+	b, err := json.Marshal(r)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `{"file":"testdata/fs/base.yaml","bytes":16,"expansions":0,"keys":2,"duration":`)
+
+	_, err = NewYAML(Source(strings.NewReader("a: 1")), WithLoadReport(&r))
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Len(t, r.Sources, 1, "Report should be reset")
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+)
+
+const (
+	// _rolloutKey is a top level key with a staged rollout description.
+	_rolloutKey = "rollout"
+
+	// _canaryKey is a key in the rollout section with canary overrides.
+	_canaryKey = "canary"
+
+	// _rolloutBuckets is a number of buckets instances are hashed into.
+	_rolloutBuckets = 10000
+)
+
+type rollout struct {
+	Percentage float64 `yaml:"percentage" validate:"min=0,max=100"`
+	Salt       string  `yaml:"salt"`
+}
+
+// NewRolloutProvider creates a provider for a staged rollout, that exposes
+// either canary or baseline configuration for an instance, e.g. a host name.
+// The rollout is described in the top level rollout key:
+//
+// 	timeout: 1s
+// 	rollout:
+// 	  percentage: 10
+// 	  salt: release-42
+// 	  canary:
+// 	    timeout: 2s
+//
+// Instances are assigned to the canary cohort by a stable hash of the salt
+// and the instance, about 10% of instances get the timeout of 2s in the
+// example above and the rest get 1s. Changing the salt reshuffles instances.
+// The rollout key itself is not visible in the new provider.
+func NewRolloutProvider(p Provider, instance string) (Provider, error) {
+	if p == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	if instance == "" {
+		return nil, errors.New("empty instance name")
+	}
+
+	var r rollout
+	if err := p.Get(_rolloutKey).Populate(&r); err != nil {
+		return nil, fmt.Errorf("invalid rollout description: %v", err)
+	}
+
+	base := hiddenKeyProvider{Provider: p, key: _rolloutKey}
+	if !inCanary(r, instance) {
+		return NewProviderGroup(fmt.Sprintf("%s (baseline)", p.Name()), base)
+	}
+
+	canary := NewScopedProvider(_rolloutKey+base.separator()+_canaryKey, p)
+	return NewProviderGroup(fmt.Sprintf("%s (canary)", p.Name()), base, canary)
+}
+
+// inCanary returns whether an instance belongs to the canary cohort.
+func inCanary(r rollout, instance string) bool {
+	h := fnv.New32a()
+	h.Write([]byte(r.Salt))
+	h.Write([]byte{0})
+	h.Write([]byte(instance))
+	return float64(h.Sum32()%_rolloutBuckets) < r.Percentage*_rolloutBuckets/100
+}
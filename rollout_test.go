@@ -0,0 +1,132 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rolloutYAML(percentage int) []byte {
+	return []byte(fmt.Sprintf(`
+timeout: 1s
+retries: 3
+rollout:
+  percentage: %d
+  salt: release-42
+  canary:
+    timeout: 2s
+`, percentage))
+}
+
+func TestNewRolloutProvider(t *testing.T) {
+	t.Parallel()
+
+	y, err := NewYAMLProviderFromBytes(rolloutYAML(10))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	canaries := 0
+	for i := 0; i < 1000; i++ {
+		p, err := NewRolloutProvider(y, fmt.Sprint("host-", i))
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, p.Get("retries").Value())
+		assert.False(t, p.Get(_rolloutKey).HasValue())
+		if p.Get("timeout").Value() == "2s" {
+			canaries++
+			assert.Equal(t, `cached "yaml" (canary)`, p.Name())
+		} else {
+			assert.Equal(t, "1s", p.Get("timeout").Value())
+			assert.Equal(t, `cached "yaml" (baseline)`, p.Name())
+		}
+	}
+
+	assert.InDelta(t, 100, canaries, 30)
+}
+
+func TestNewRolloutProviderIsStable(t *testing.T) {
+	t.Parallel()
+
+	y, err := NewYAMLProviderFromBytes(rolloutYAML(50))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	for i := 0; i < 100; i++ {
+		host := fmt.Sprint("host-", i)
+		p1, err := NewRolloutProvider(y, host)
+		require.NoError(t, err)
+
+		p2, err := NewRolloutProvider(y, host)
+		require.NoError(t, err)
+
+		assert.Equal(t, p1.Name(), p2.Name())
+	}
+}
+
+func TestNewRolloutProviderEdgePercentages(t *testing.T) {
+	t.Parallel()
+
+	none, err := NewYAMLProviderFromBytes(rolloutYAML(0))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	all, err := NewYAMLProviderFromBytes(rolloutYAML(100))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	missing, err := NewYAMLProviderFromBytes([]byte(`timeout: 1s`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	for i := 0; i < 100; i++ {
+		host := fmt.Sprint("host-", i)
+
+		p, err := NewRolloutProvider(none, host)
+		require.NoError(t, err)
+		assert.Equal(t, "1s", p.Get("timeout").Value())
+
+		p, err = NewRolloutProvider(all, host)
+		require.NoError(t, err)
+		assert.Equal(t, "2s", p.Get("timeout").Value())
+
+		p, err = NewRolloutProvider(missing, host)
+		require.NoError(t, err)
+		assert.Equal(t, "1s", p.Get("timeout").Value())
+	}
+}
+
+func TestNewRolloutProviderErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewRolloutProvider(nil, "host")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "received a nil provider")
+
+	_, err = NewRolloutProvider(NopProvider{}, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty instance name")
+
+	y, err := NewYAMLProviderFromBytes(rolloutYAML(200))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	_, err = NewRolloutProvider(y, "host")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid rollout description")
+}
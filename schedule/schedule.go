@@ -0,0 +1,330 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package schedule provides configuration types for cron expressions and
+// daily time windows, e.g. of maintenance:
+//
+//	maintenance:
+//	  cleanup: "30 2 * * 1-5"
+//	  window: "22:00-02:00"
+//
+// The types are validated when they are populated, e.g. with
+// config.Value.Populate, so mistakes are caught at load instead of when the
+// schedule fires.
+package schedule // import "go.uber.org/config/schedule"
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a standard cron expression of five fields: minute, hour, day of
+// month, month and day of week, where Sunday is 0 or 7. Fields are *,
+// numbers, ranges, e.g. 1-5, steps, e.g. */15 or 0-30/10, or lists of them,
+// e.g. 0,30. @yearly, @monthly, @weekly, @daily and @hourly are accepted too.
+// A zero Cron never fires.
+type Cron struct {
+	expr string
+
+	minute, hour, dom, month, dow uint64
+
+	// Days match either the day of month or the day of week, if both are
+	// restricted, like in cron.
+	anyDOM, anyDOW bool
+}
+
+var _descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a cron expression.
+func ParseCron(s string) (Cron, error) {
+	expr := strings.TrimSpace(s)
+	if d, ok := _descriptors[expr]; ok {
+		expr = d
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Cron{}, fmt.Errorf("cron expression %q must have 5 fields, got %d", s, len(fields))
+	}
+
+	c := Cron{expr: strings.TrimSpace(s)}
+	bounds := []struct {
+		name     string
+		min, max uint
+		bits     *uint64
+	}{
+		{"minute", 0, 59, &c.minute},
+		{"hour", 0, 23, &c.hour},
+		{"day of month", 1, 31, &c.dom},
+		{"month", 1, 12, &c.month},
+		{"day of week", 0, 7, &c.dow},
+	}
+
+	for i, b := range bounds {
+		bits, err := parseField(fields[i], b.min, b.max)
+		if err != nil {
+			return Cron{}, fmt.Errorf("invalid %s of cron expression %q: %v", b.name, s, err)
+		}
+
+		*b.bits = bits
+	}
+
+	// Sunday is both 0 and 7.
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+
+	c.anyDOM = fields[2] == "*"
+	c.anyDOW = fields[4] == "*"
+	return c, nil
+}
+
+func parseField(field string, min, max uint) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, uint64(1)
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.ParseUint(part[i+1:], 10, 8)
+			if err != nil || n == 0 {
+				return 0, fmt.Errorf("invalid step %q", part[i+1:])
+			}
+
+			rng, step = part[:i], n
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			n, err := parseNumber(bounds[0], min, max)
+			if err != nil {
+				return 0, err
+			}
+
+			lo, hi = n, n
+			if len(bounds) == 2 {
+				if hi, err = parseNumber(bounds[1], min, max); err != nil {
+					return 0, err
+				}
+
+				if hi < lo {
+					return 0, fmt.Errorf("range %q is reversed", rng)
+				}
+			} else if step > 1 {
+				hi = max
+			}
+		}
+
+		for n := uint64(lo); n <= uint64(hi); n += step {
+			bits |= 1 << n
+		}
+	}
+
+	return bits, nil
+}
+
+func parseNumber(s string, min, max uint) (uint, error) {
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+
+	if uint(n) < min || uint(n) > max {
+		return 0, fmt.Errorf("%d is out of range [%d, %d]", n, min, max)
+	}
+
+	return uint(n), nil
+}
+
+// String returns the expression the cron was parsed from.
+func (c Cron) String() string {
+	return c.expr
+}
+
+// IsZero returns true for the zero Cron.
+func (c Cron) IsZero() bool {
+	return c.minute == 0
+}
+
+// Next returns the first time after t matching the expression in the
+// location of t, or the zero time if there is none in five years, e.g. for
+// February 30.
+func (c Cron) Next(t time.Time) time.Time {
+	if c.IsZero() {
+		return time.Time{}
+	}
+
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (c Cron) matchesDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.anyDOM || c.anyDOW {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// UnmarshalText parses a cron expression.
+func (c *Cron) UnmarshalText(text []byte) error {
+	parsed, err := ParseCron(string(text))
+	if err != nil {
+		return err
+	}
+
+	*c = parsed
+	return nil
+}
+
+// MarshalText returns the expression.
+func (c Cron) MarshalText() ([]byte, error) {
+	return []byte(c.expr), nil
+}
+
+// Window is a daily time window of the form start-end, e.g. 02:00-04:00,
+// in a location the caller chooses. Windows ending before they start cross
+// midnight, e.g. 22:00-02:00.
+type Window struct {
+	// Start and End are offsets from midnight.
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseWindow parses a window of the form HH:MM-HH:MM, where the end can be
+// 24:00.
+func ParseWindow(s string) (Window, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return Window{}, fmt.Errorf("time window %q isn't of the form HH:MM-HH:MM", s)
+	}
+
+	start, err := parseClock(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid start of time window %q: %v", s, err)
+	}
+
+	end, err := parseClock(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid end of time window %q: %v", s, err)
+	}
+
+	w := Window{Start: start, End: end}
+	return w, w.Validate()
+}
+
+func parseClock(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 || len(parts[1]) != 2 {
+		return 0, fmt.Errorf("%q isn't of the form HH:MM", s)
+	}
+
+	h, err := strconv.Atoi(parts[0])
+	if err != nil || h < 0 || h > 24 {
+		return 0, fmt.Errorf("invalid hour of %q", s)
+	}
+
+	m, err := strconv.Atoi(parts[1])
+	if err != nil || m < 0 || m > 59 || h == 24 && m != 0 {
+		return 0, fmt.Errorf("invalid minute of %q", s)
+	}
+
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// Validate checks that the offsets are within a day and the window isn't
+// empty.
+func (w Window) Validate() error {
+	if w.Start < 0 || w.Start >= 24*time.Hour || w.End < 0 || w.End > 24*time.Hour {
+		return errors.New("start and end must be within a day")
+	}
+
+	if w.Start == w.End {
+		return errors.New("time window is empty")
+	}
+
+	return nil
+}
+
+// Contains returns true if the time of day of t is within the window,
+// including the start and excluding the end.
+func (w Window) Contains(t time.Time) bool {
+	y, m, d := t.Date()
+	offset := t.Sub(time.Date(y, m, d, 0, 0, 0, 0, t.Location()))
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+
+	return offset >= w.Start || offset < w.End
+}
+
+// String returns the window in the form HH:MM-HH:MM.
+func (w Window) String() string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+	}
+
+	return clock(w.Start) + "-" + clock(w.End)
+}
+
+// UnmarshalText parses a window of the form HH:MM-HH:MM.
+func (w *Window) UnmarshalText(text []byte) error {
+	parsed, err := ParseWindow(string(text))
+	if err != nil {
+		return err
+	}
+
+	*w = parsed
+	return nil
+}
+
+// MarshalText returns the window in the form HH:MM-HH:MM.
+func (w Window) MarshalText() ([]byte, error) {
+	return []byte(w.String()), nil
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPopulate(t *testing.T) {
+	t.Parallel()
+
+	p, err := config.NewYAMLProviderFromBytes([]byte(`
+maintenance:
+  cleanup: "30 2 * * 1-5"
+  window: "22:00-02:00"
+broken:
+  cleanup: "61 * * * *"
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var m struct {
+		Cleanup Cron   `yaml:"cleanup"`
+		Window  Window `yaml:"window"`
+	}
+
+	require.NoError(t, p.Get("maintenance").Populate(&m))
+	assert.Equal(t, "30 2 * * 1-5", m.Cleanup.String())
+	assert.Equal(t, Window{Start: 22 * time.Hour, End: 2 * time.Hour}, m.Window)
+
+	err = p.Get("broken").Populate(&m)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid minute of cron expression "61 * * * *": 61 is out of range [0, 59]`)
+}
+
+func TestCronNext(t *testing.T) {
+	t.Parallel()
+
+	// Wednesday.
+	now := time.Date(2017, time.June, 14, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		expr string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2017, time.June, 14, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2017, time.June, 14, 10, 15, 0, 0, time.UTC)},
+		{"30 2 * * 1-5", time.Date(2017, time.June, 15, 2, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2017, time.June, 18, 0, 0, 0, 0, time.UTC)},
+		{"0,30 9-17/4 * * *", time.Date(2017, time.June, 14, 13, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 5", time.Date(2017, time.June, 16, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2017, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		require.NoError(t, err, "Can't parse %q", tt.expr)
+		assert.Equal(t, tt.next, c.Next(now), "Wrong next time of %q", tt.expr)
+	}
+
+	assert.True(t, Cron{}.Next(now).IsZero())
+}
+
+func TestParseCronErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"* * * *":      "must have 5 fields, got 4",
+		"* 24 * * *":   "invalid hour",
+		"* * 0 * *":    "invalid day of month",
+		"* * * 1-13 *": "invalid month",
+		"* * * * 8":    "invalid day of week",
+		"*/0 * * * *":  `invalid step "0"`,
+		"5-1 * * * *":  `range "5-1" is reversed`,
+		"a * * * *":    `"a" is not a number`,
+		"@fortnightly": "must have 5 fields",
+	}
+
+	for expr, msg := range tests {
+		_, err := ParseCron(expr)
+		require.Error(t, err, "Expected an error for %q", expr)
+		assert.Contains(t, err.Error(), msg)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	t.Parallel()
+
+	at := func(h, m int) time.Time {
+		return time.Date(2017, time.June, 14, h, m, 0, 0, time.UTC)
+	}
+
+	w, err := ParseWindow("02:00-04:00")
+	require.NoError(t, err)
+	assert.True(t, w.Contains(at(2, 0)))
+	assert.True(t, w.Contains(at(3, 59)))
+	assert.False(t, w.Contains(at(4, 0)))
+	assert.Equal(t, "02:00-04:00", w.String())
+
+	w, err = ParseWindow("22:00 - 24:00")
+	require.NoError(t, err)
+	assert.True(t, w.Contains(at(23, 59)))
+	assert.False(t, w.Contains(at(0, 0)))
+
+	w, err = ParseWindow("22:00-02:00")
+	require.NoError(t, err)
+	assert.True(t, w.Contains(at(23, 0)))
+	assert.True(t, w.Contains(at(1, 0)))
+	assert.False(t, w.Contains(at(12, 0)))
+
+	b, err := w.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "22:00-02:00", string(b))
+
+	for s, msg := range map[string]string{
+		"02:00":       "isn't of the form HH:MM-HH:MM",
+		"2-4":         `invalid start of time window "2-4"`,
+		"02:00-25:00": "invalid hour",
+		"02:00-24:30": "invalid minute",
+		"02:00-02:00": "time window is empty",
+		"24:00-02:00": "start and end must be within a day",
+	} {
+		_, err := ParseWindow(s)
+		require.Error(t, err, "Expected an error for %q", s)
+		assert.Contains(t, err.Error(), msg)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	_typeOfDuration        = reflect.TypeOf(time.Duration(0))
+	_typeOfTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	_typeOfYAMLUnmarshaler = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+)
+
+// NewProviderWithSchema creates a provider, where strings at the key are
+// converted to the types of the matching fields of the schema struct, e.g.
+// a "8080" port from an environment variable becomes 8080 for
+//
+// 	type Server struct {
+// 		Port    int
+// 		Debug   bool
+// 		Timeout time.Duration
+// 	}
+//
+// 	p, err := config.NewProviderWithSchema(group, "server", Server{})
+//
+// so values are typed for Value.Value, queries and diffs too, not only for
+// Populate. Integers, floats, booleans and durations are converted, fields
+// of types implementing encoding.TextUnmarshaler or yaml.Unmarshaler are
+// left as is. Strings that can't be converted are reported as errors.
+func NewProviderWithSchema(p Provider, key string, schema interface{}) (Provider, error) {
+	if p == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	if schema == nil {
+		return nil, errors.New("received a nil schema")
+	}
+
+	separator := separatorOf(p)
+	segments := splitKey(key, separator)
+	if key == Root {
+		segments = nil
+	}
+
+	root, err := coerceAt(key, segments, reflect.TypeOf(schema), deepCopy(p.Get(Root).Value()), separator)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDerivedProvider(p, root)
+}
+
+// coerceAt coerces the value at the key segments of the node.
+func coerceAt(key string, segments []string, t reflect.Type, node interface{}, separator string) (interface{}, error) {
+	if len(segments) == 0 {
+		return coerce(key, t, node, separator)
+	}
+
+	segment := unescapeSeparators(segments[0], separator)
+	switch n := node.(type) {
+	case map[interface{}]interface{}:
+		for k, child := range n {
+			if strings.EqualFold(fmt.Sprint(k), segment) {
+				c, err := coerceAt(key, segments[1:], t, child, separator)
+				if err != nil {
+					return nil, err
+				}
+
+				n[k] = c
+			}
+		}
+	case []interface{}:
+		if i, err := strconv.Atoi(segment); err == nil && i >= 0 && i < len(n) {
+			c, err := coerceAt(key, segments[1:], t, n[i], separator)
+			if err != nil {
+				return nil, err
+			}
+
+			n[i] = c
+		}
+	}
+
+	return node, nil
+}
+
+// coerce converts strings in the value to the types of the matching fields.
+// Collections are modified in place.
+func coerce(key string, t reflect.Type, value interface{}, separator string) (interface{}, error) {
+	t = derefType(t)
+	if reflect.PtrTo(t).Implements(_typeOfTextUnmarshaler) || reflect.PtrTo(t).Implements(_typeOfYAMLUnmarshaler) {
+		return value, nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		return coerceString(key, t, v)
+	case map[interface{}]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			for i := 0; i < t.NumField(); i++ {
+				field := t.Field(i)
+				if field.PkgPath != "" || field.Anonymous {
+					continue
+				}
+
+				name := field.Name
+				if info := getFieldInfo(field); info.FieldName != "" {
+					name = info.FieldName
+				}
+
+				for k, child := range v {
+					if !strings.EqualFold(fmt.Sprint(k), name) {
+						continue
+					}
+
+					c, err := coerce(joinKey(key, name, separator), field.Type, child, separator)
+					if err != nil {
+						return nil, err
+					}
+
+					v[k] = c
+				}
+			}
+		case reflect.Map:
+			for k, child := range v {
+				c, err := coerce(joinKey(key, fmt.Sprint(k), separator), t.Elem(), child, separator)
+				if err != nil {
+					return nil, err
+				}
+
+				v[k] = c
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, child := range v {
+				c, err := coerce(joinKey(key, strconv.Itoa(i), separator), t.Elem(), child, separator)
+				if err != nil {
+					return nil, err
+				}
+
+				v[i] = c
+			}
+		}
+	}
+
+	return value, nil
+}
+
+func coerceString(key string, t reflect.Type, s string) (interface{}, error) {
+	var res interface{}
+	var err error
+	switch {
+	case t == _typeOfDuration:
+		res, err = time.ParseDuration(s)
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, 64); err == nil {
+			res = int(i)
+			if int64(int(i)) != i {
+				res = i
+			}
+		}
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uintptr:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 10, 64); err == nil {
+			res = u
+			if u <= uint64(^uint(0)>>1) {
+				res = int(u)
+			}
+		}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		res, err = strconv.ParseFloat(s, 64)
+	case t.Kind() == reflect.Bool:
+		res, err = strconv.ParseBool(s)
+	default:
+		return s, nil
+	}
+
+	if err != nil {
+		return nil, errorWithKey(fmt.Errorf("can't convert %q to %s", s, t), key)
+	}
+
+	return res, nil
+}
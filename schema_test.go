@@ -0,0 +1,136 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaServer struct {
+	Port     int
+	Debug    bool `yaml:"debug"`
+	Timeout  time.Duration
+	Ratio    float64
+	Workers  *uint
+	Name     string
+	Level    textUnmarshalerLevel
+	Backends []struct {
+		Weight int
+	}
+	Limits map[string]int
+}
+
+type textUnmarshalerLevel int
+
+func (l *textUnmarshalerLevel) UnmarshalText(text []byte) error {
+	*l = textUnmarshalerLevel(len(text))
+	return nil
+}
+
+func TestNewProviderWithSchema(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewStaticProvider(map[string]interface{}{
+		"server": map[string]interface{}{
+			"PORT":     "8080",
+			"debug":    "true",
+			"timeout":  "1s",
+			"ratio":    "0.5",
+			"workers":  "4",
+			"name":     "123",
+			"level":    "42",
+			"backends": []interface{}{map[string]string{"weight": "3"}},
+			"limits":   map[string]string{"a": "10"},
+			"unknown":  "1",
+		},
+		"other": "1",
+	})
+	require.NoError(t, err, "Can't create a static provider")
+
+	c, err := NewProviderWithSchema(p, "server", schemaServer{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 8080, c.Get("server.port").Value())
+	assert.Equal(t, true, c.Get("server.debug").Value())
+	assert.Equal(t, time.Second, c.Get("server.timeout").Value())
+	assert.Equal(t, 0.5, c.Get("server.ratio").Value())
+	assert.Equal(t, 4, c.Get("server.workers").Value())
+	assert.Equal(t, "123", c.Get("server.name").Value())
+	assert.Equal(t, "42", c.Get("server.level").Value(), "Text unmarshalers should be left as is")
+	assert.Equal(t, 3, c.Get("server.backends.0.weight").Value())
+	assert.Equal(t, 10, c.Get("server.limits.a").Value())
+	assert.Equal(t, "1", c.Get("server.unknown").Value())
+	assert.Equal(t, "1", c.Get("other").Value())
+	assert.Equal(t, "8080", p.Get("server.port").Value(), "The original provider shouldn't change")
+
+	var s schemaServer
+	require.NoError(t, c.Get("server").Populate(&s))
+	assert.Equal(t, time.Second, s.Timeout)
+	assert.Equal(t, uint(4), *s.Workers)
+}
+
+func TestNewProviderWithSchemaKeys(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+servers:
+  - port: "1"
+  - port: "2"
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	c, err := NewProviderWithSchema(p, "servers.1", struct{ Port int }{})
+	require.NoError(t, err)
+	assert.Equal(t, "1", c.Get("servers.0.port").Value())
+	assert.Equal(t, 2, c.Get("servers.1.port").Value())
+
+	c, err = NewProviderWithSchema(p, Root, struct{ Servers []struct{ Port int } }{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.Get("servers.0.port").Value())
+
+	c, err = NewProviderWithSchema(p, "missing", struct{ Port int }{})
+	require.NoError(t, err)
+	assert.Equal(t, p.Get(Root).Value(), c.Get(Root).Value())
+}
+
+func TestNewProviderWithSchemaErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewProviderWithSchema(nil, Root, struct{}{})
+	assert.Error(t, err)
+
+	p, err := NewYAMLProviderFromBytes([]byte(`server: {port: "http", big: "300"}`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	_, err = NewProviderWithSchema(p, Root, nil)
+	assert.Error(t, err)
+
+	_, err = NewProviderWithSchema(p, "server", struct{ Port int }{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `for key "server.Port": can't convert "http" to int`)
+
+	_, err = NewProviderWithSchema(p, "server", struct{ Big uint8 }{})
+	assert.NoError(t, err, "Range checks are left to Populate")
+}
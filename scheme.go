@@ -0,0 +1,144 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SchemeFactory creates a provider for a source URL.
+type SchemeFactory func(u *url.URL) (Provider, error)
+
+var _schemes = struct {
+	sync.RWMutex
+	factories map[string]SchemeFactory
+}{factories: map[string]SchemeFactory{"file": fileScheme}}
+
+// RegisterScheme registers a factory of providers for source URLs with the
+// scheme, e.g. a package implementing an S3 provider can register
+//
+// 	config.RegisterScheme("s3", func(u *url.URL) (config.Provider, error) {
+// 		return NewS3Provider(u.Host, u.Path)
+// 	})
+//
+// so applications can list "s3://bucket/service.yaml" among their sources.
+// The "file" scheme is registered by default. Registration is usually done
+// in init functions.
+func RegisterScheme(scheme string, f SchemeFactory) error {
+	if scheme == "" {
+		return errors.New("empty scheme")
+	}
+
+	if f == nil {
+		return errors.New("received a nil factory")
+	}
+
+	scheme = strings.ToLower(scheme)
+
+	_schemes.Lock()
+	defer _schemes.Unlock()
+
+	if _, ok := _schemes.factories[scheme]; ok {
+		return fmt.Errorf("scheme %q is already registered", scheme)
+	}
+
+	_schemes.factories[scheme] = f
+	return nil
+}
+
+// NewProviderFromURLs creates a configuration provider from source URLs,
+// which are merged in order like providers in a group, e.g.
+//
+// 	p, err := config.NewProviderFromURLs(
+// 		"file:///etc/service/base.yaml",
+// 		"consul://localhost:8500/service",
+// 	)
+//
+// URLs without a scheme are names of YAML files.
+func NewProviderFromURLs(urls ...string) (Provider, error) {
+	providers := make([]Provider, 0, len(urls))
+	for _, s := range urls {
+		p, err := providerFromURL(s)
+		if err != nil {
+			return nil, err
+		}
+
+		providers = append(providers, p)
+	}
+
+	if len(providers) == 1 {
+		return providers[0], nil
+	}
+
+	return NewProviderGroup("urls", providers...)
+}
+
+func providerFromURL(s string) (Provider, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "" {
+		return NewYAMLProviderFromFiles(s)
+	}
+
+	_schemes.RLock()
+	f, ok := _schemes.factories[u.Scheme]
+	names := make([]string, 0, len(_schemes.factories))
+	for name := range _schemes.factories {
+		names = append(names, name)
+	}
+	_schemes.RUnlock()
+
+	if !ok {
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown scheme %q in %q, registered schemes: %s",
+			u.Scheme, s, strings.Join(names, ", "))
+	}
+
+	p, err := f(u)
+	if err != nil {
+		return nil, fmt.Errorf("can't load %q: %v", s, err)
+	}
+
+	return p, nil
+}
+
+// fileScheme loads YAML files from URLs like file:///etc/base.yaml, or
+// file:config/base.yaml for relative names.
+func fileScheme(u *url.URL) (Provider, error) {
+	name := u.Opaque
+	if name == "" {
+		name = u.Host + u.Path
+	}
+
+	if name == "" {
+		return nil, errors.New("empty file name")
+	}
+
+	return NewYAMLProviderFromFiles(name)
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProviderFromURLs(t *testing.T) {
+	t.Parallel()
+
+	abs, err := filepath.Abs("testdata/fs/base.yaml")
+	require.NoError(t, err)
+
+	p, err := NewProviderFromURLs("file://"+filepath.ToSlash(abs), "testdata/fs/dev.yaml")
+	require.NoError(t, err, "Can't create a provider")
+	assert.Equal(t, "base", p.Get("a").Value())
+	assert.Equal(t, "dev", p.Get("b").Value())
+
+	p, err = NewProviderFromURLs("file:testdata/fs/dev.yaml")
+	require.NoError(t, err, "Can't create a provider")
+	assert.Equal(t, "dev", p.Get("b").Value())
+	assert.Equal(t, `cached "yaml"`, p.Name(), "Expected a single provider without a group")
+}
+
+func TestRegisterScheme(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, RegisterScheme("TestRegisterScheme", func(u *url.URL) (Provider, error) {
+		if u.Host == "fail" {
+			return nil, errors.New("backend is down")
+		}
+
+		return NewStaticProvider(map[string]string{"host": u.Host, "path": u.Path})
+	}))
+
+	p, err := NewProviderFromURLs("testregisterscheme://localhost/service")
+	require.NoError(t, err, "Can't create a provider")
+	assert.Equal(t, "localhost", p.Get("host").Value())
+	assert.Equal(t, "/service", p.Get("path").Value())
+
+	_, err = NewProviderFromURLs("testregisterscheme://fail")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `can't load "testregisterscheme://fail": backend is down`)
+
+	assert.Error(t, RegisterScheme("testregisterscheme", func(*url.URL) (Provider, error) { return nil, nil }))
+	assert.Error(t, RegisterScheme("", func(*url.URL) (Provider, error) { return nil, nil }))
+	assert.Error(t, RegisterScheme("nil", nil))
+}
+
+func TestNewProviderFromURLsErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewProviderFromURLs("unknown://host")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown scheme "unknown" in "unknown://host"`)
+	assert.Contains(t, err.Error(), "file")
+
+	_, err = NewProviderFromURLs("file://")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty file name")
+
+	_, err = NewProviderFromURLs("%zz")
+	assert.Error(t, err)
+
+	_, err = NewProviderFromURLs("testdata/fs/missing.yaml")
+	assert.Error(t, err)
+}
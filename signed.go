@@ -0,0 +1,175 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ed25519"
+)
+
+// SignatureExtension is appended to a configuration file name to get the name
+// of the file with its detached signature, e.g. base.yaml.sig for base.yaml.
+const SignatureExtension = ".sig"
+
+// _maxSignatureSize bounds signature files, it fits base64 encoded ed25519
+// signatures with a lot of slack, and signatures of other verifiers, e.g.
+// armored PGP ones.
+const _maxSignatureSize = 4 << 10
+
+// A Verifier checks a detached signature over configuration content.
+type Verifier interface {
+	// Verify returns an error if signature is not a valid signature of content.
+	Verify(content, signature []byte) error
+}
+
+type ed25519Verifier struct {
+	keys []ed25519.PublicKey
+}
+
+// NewEd25519Verifier returns a Verifier accepting content signed by any of
+// the private keys matching the provided public keys. Signatures can be either
+// raw 64 byte blobs or base64 encoded text.
+func NewEd25519Verifier(keys ...ed25519.PublicKey) (Verifier, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no public keys provided")
+	}
+
+	for i, key := range keys {
+		if len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("public key %d has size %d, expected %d", i, len(key), ed25519.PublicKeySize)
+		}
+	}
+
+	return ed25519Verifier{keys: keys}, nil
+}
+
+// Verify checks the signature against all the public keys.
+func (v ed25519Verifier) Verify(content, signature []byte) error {
+	sig, err := decodeSignature(signature)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range v.keys {
+		if ed25519.Verify(key, content, sig) {
+			return nil
+		}
+	}
+
+	return errors.New("signature verification failed")
+}
+
+// decodeSignature returns raw signatures as is and decodes base64 ones.
+func decodeSignature(signature []byte) ([]byte, error) {
+	if len(signature) == ed25519.SignatureSize {
+		return signature, nil
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode the signature")
+	}
+
+	if len(sig) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("signature has size %d, expected %d", len(sig), ed25519.SignatureSize)
+	}
+
+	return sig, nil
+}
+
+// NewYAMLProviderFromSignedFiles creates a configuration provider from a set
+// of YAML file names, like NewYAMLProviderFromFiles does, but only after
+// the verifier accepts the detached signature of each file. Signatures are
+// read from files with the SignatureExtension appended to the file name.
+func NewYAMLProviderFromSignedFiles(verifier Verifier, files ...string) (Provider, error) {
+	return NewYAML(Signed(verifier, files...))
+}
+
+// Signed adds YAML files to read configuration from, that are loaded only
+// after the verifier accepts their detached signatures, see
+// NewYAMLProviderFromSignedFiles.
+func Signed(verifier Verifier, files ...string) YAMLOption {
+	return func(o *yamlOptions) {
+		if verifier == nil {
+			if o.err == nil {
+				o.err = errors.New("received a nil verifier")
+			}
+
+			return
+		}
+
+		for _, file := range files {
+			file := file
+			o.sources = append(o.sources, yamlSource{
+				file: file,
+				open: func(limit int64) (io.ReadCloser, error) { return openSignedFile(verifier, file, limit) },
+				disk: true,
+			})
+		}
+	}
+}
+
+// readSignature reads a signature file of at most _maxSignatureSize bytes.
+func readSignature(file string) ([]byte, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+	signature, err := readLimited(f, _maxSignatureSize)
+	return signature, errors.Wrapf(err, "in signature file: %q", file)
+}
+
+// openSignedFile reads a file up to the limit of bytes and returns its
+// content if the verifier accepts its signature.
+func openSignedFile(verifier Verifier, file string, limit int64) (io.ReadCloser, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := readLimited(f, limit)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "in file: %q", file)
+	}
+
+	signature, err := readSignature(file + SignatureExtension)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifier.Verify(content, signature); err != nil {
+		return nil, errors.Wrapf(err, "in file: %q", file)
+	}
+
+	return newByteSource(content), nil
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+)
+
+func testKeys(t *testing.T, seed byte) (ed25519.PublicKey, ed25519.PrivateKey) {
+	pub, priv, err := ed25519.GenerateKey(bytes.NewReader(bytes.Repeat([]byte{seed}, 32)))
+	require.NoError(t, err, "Can't generate keys")
+	return pub, priv
+}
+
+func writeSignedFile(t *testing.T, dir, name string, content, signature []byte) string {
+	file := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(file, content, 0600))
+	require.NoError(t, ioutil.WriteFile(file+SignatureExtension, signature, 0600))
+	return file
+}
+
+func TestNewEd25519VerifierErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewEd25519Verifier()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no public keys provided")
+
+	_, err = NewEd25519Verifier(ed25519.PublicKey("short"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "public key 0 has size 5, expected 32")
+}
+
+func TestEd25519VerifierSignatureFormats(t *testing.T) {
+	t.Parallel()
+
+	pub, priv := testKeys(t, 1)
+	v, err := NewEd25519Verifier(pub)
+	require.NoError(t, err)
+
+	content := []byte("a: b")
+	sig := ed25519.Sign(priv, content)
+
+	assert.NoError(t, v.Verify(content, sig))
+	assert.NoError(t, v.Verify(content, []byte(base64.StdEncoding.EncodeToString(sig)+"\n")))
+
+	err = v.Verify(content, []byte("not base64!"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode the signature")
+
+	err = v.Verify(content, []byte(base64.StdEncoding.EncodeToString([]byte("short"))))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "signature has size 5, expected 64")
+
+	err = v.Verify([]byte("a: c"), sig)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "signature verification failed")
+}
+
+func TestEd25519VerifierWithMultipleKeys(t *testing.T) {
+	t.Parallel()
+
+	old, _ := testKeys(t, 1)
+	pub, priv := testKeys(t, 2)
+	v, err := NewEd25519Verifier(old, pub)
+	require.NoError(t, err)
+
+	content := []byte("a: b")
+	assert.NoError(t, v.Verify(content, ed25519.Sign(priv, content)))
+}
+
+func TestNewYAMLProviderFromSignedFiles(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestNewYAMLProviderFromSignedFiles")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	pub, priv := testKeys(t, 1)
+	v, err := NewEd25519Verifier(pub)
+	require.NoError(t, err)
+
+	base := []byte("a: base\nb: base")
+	dev := []byte("b: dev")
+	files := []string{
+		writeSignedFile(t, dir, "base.yaml", base, ed25519.Sign(priv, base)),
+		writeSignedFile(t, dir, "dev.yaml", dev, ed25519.Sign(priv, dev)),
+	}
+
+	p, err := NewYAMLProviderFromSignedFiles(v, files...)
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "base", p.Get("a").String())
+	assert.Equal(t, "dev", p.Get("b").String())
+
+	_, other := testKeys(t, 2)
+	bad := writeSignedFile(t, dir, "bad.yaml", dev, ed25519.Sign(other, dev))
+	_, err = NewYAMLProviderFromSignedFiles(v, files[0], bad)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad.yaml")
+	assert.Contains(t, err.Error(), "signature verification failed")
+}
+
+func TestNewYAMLProviderFromSignedFilesErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewYAMLProviderFromSignedFiles(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "received a nil verifier")
+
+	pub, _ := testKeys(t, 1)
+	v, err := NewEd25519Verifier(pub)
+	require.NoError(t, err)
+
+	_, err = NewYAMLProviderFromSignedFiles(v, "./testdata/missing.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing.yaml")
+
+	// Signature file is missing.
+	_, err = NewYAMLProviderFromSignedFiles(v, "./testdata/base.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "base.yaml.sig")
+
+	dir, err := ioutil.TempDir("", "TestNewYAMLProviderFromSignedFilesErrors")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := writeSignedFile(t, dir, "config.yaml", []byte("a: 1"), bytes.Repeat([]byte{'A'}, _maxSignatureSize+1))
+	_, err = NewYAMLProviderFromSignedFiles(v, file)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds the limit of 4096 bytes")
+}
+
+func TestSignedWithOptions(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestSignedWithOptions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	pub, priv := testKeys(t, 1)
+	v, err := NewEd25519Verifier(pub)
+	require.NoError(t, err)
+
+	content := []byte("a: ${A}")
+	file := writeSignedFile(t, dir, "a.yaml", content, ed25519.Sign(priv, content))
+
+	p, err := NewYAML(Signed(v, file), WithExpand(func(string) (string, bool) { return "expanded", true }))
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "expanded", p.Get("a").Value())
+	assert.Equal(t, file, p.Get("a").Metadata().File)
+
+	_, err = NewYAML(Signed(v, file), WithLimits(Limits{MaxFileSize: 4}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "size of the source exceeds the limit of 4 bytes")
+
+	_, err = NewYAML(Signed(nil, file))
+	assert.EqualError(t, err, "received a nil verifier")
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.21
+// +build go1.21
+
+package config
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSlog creates a logger writing text without times.
+func newTestSlog(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return a
+		},
+	}))
+}
+
+func TestSlogLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	l := SlogLogger(newTestSlog(&buf))
+	l.Debugf("loaded %q", "a.yaml")
+	l.Warnf("key %q is deprecated", "a")
+	assert.Equal(t, `level=DEBUG msg="loaded \"a.yaml\""`+"\n"+
+		`level=WARN msg="key \"a\" is deprecated"`+"\n", buf.String())
+}
+
+func TestNewYAMLWithSlog(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	p, err := NewYAML(
+		File("testdata/fs/base.yaml", "testdata/fs/dev.yaml"),
+		Source(bytes.NewBufferString("b: dev")),
+		WithSlog(newTestSlog(&buf)),
+	)
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "dev", p.Get("b").Value())
+	assert.Equal(t, `level=INFO msg="config source loaded" source=0 file=testdata/fs/base.yaml keys=2`+"\n"+
+		`level=DEBUG msg="config value overridden" key=b source=1 file=testdata/fs/dev.yaml`+"\n"+
+		`level=INFO msg="config source loaded" source=1 file=testdata/fs/dev.yaml keys=1`+"\n"+
+		`level=INFO msg="config source loaded" source=2 file="" keys=1`+"\n", buf.String())
+}
+
+func TestBindLevelSlog(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte("logging: {level: warn}"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var level slog.LevelVar
+	b, err := BindLevel(p, "logging.level", &level)
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level.Level())
+
+	reloaded, err := NewYAMLProviderFromBytes([]byte("logging: {level: DEBUG}"))
+	require.NoError(t, err, "Can't create a YAML provider")
+	require.NoError(t, b.Update(reloaded))
+	assert.Equal(t, slog.LevelDebug, level.Level())
+}
@@ -0,0 +1,323 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// snapshot is a serialized form of a merged configuration tree.
+type snapshot struct {
+	Version   string      `yaml:"version,omitempty"`
+	Created   string      `yaml:"created"`
+	Checksum  string      `yaml:"checksum"`
+	Separator string      `yaml:"separator,omitempty"`
+	Config    interface{} `yaml:"config,omitempty"`
+
+	// Files values are defined in, see originsOf.
+	Files interface{} `yaml:"files,omitempty"`
+}
+
+// WriteSnapshot writes the whole merged configuration of a provider with
+// a version, e.g. a build or a deployment identifier, and a checksum, so the
+// exact configuration a service started with can be reproduced later with
+// NewProviderFromSnapshot.
+func WriteSnapshot(w io.Writer, p Provider, version string) error {
+	config := p.Get(Root).Value()
+	checksum, err := snapshotChecksum(config)
+	if err != nil {
+		return err
+	}
+
+	s := snapshot{
+		Version:  version,
+		Created:  time.Now().UTC().Format(time.RFC3339),
+		Checksum: checksum,
+	}
+
+	sep := separatorOf(p)
+	if sep != _separator {
+		s.Separator = sep
+	}
+
+	header, err := yaml.Marshal(s)
+	if err != nil {
+		return errors.Wrap(err, "can't marshal snapshot")
+	}
+
+	files, err := yaml.Marshal(struct {
+		Files interface{} `yaml:"files,omitempty"`
+	}{originsFrom(p, Root, config, sep)})
+	if err != nil {
+		return errors.Wrap(err, "can't marshal snapshot")
+	}
+
+	// The configuration is written with explicit scalars, so strings like
+	// "1" or "true" and floats like 1.0 keep their types when read back.
+	buf := bytes.NewBuffer(header)
+	buf.WriteString("config:")
+	if err := writeSnapshotValue(buf, reflect.ValueOf(config), 2); err != nil {
+		return err
+	}
+
+	buf.Write(bytes.TrimPrefix(files, []byte("{}\n")))
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// writeSnapshotValue writes a value after a mapping key or a sequence dash,
+// collections are written as blocks indented by indent spaces.
+func writeSnapshotValue(buf *bytes.Buffer, v reflect.Value, indent int) error {
+	for v.IsValid() && (v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr) {
+		v = v.Elem()
+	}
+
+	pad := strings.Repeat(" ", indent)
+	switch {
+	case !v.IsValid():
+		buf.WriteString(" null\n")
+
+	case v.Kind() == reflect.Map:
+		if v.Len() == 0 {
+			buf.WriteString(" {}\n")
+			return nil
+		}
+
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+		for _, k := range v.MapKeys() {
+			key, err := snapshotScalar(k)
+			if err != nil {
+				return err
+			}
+
+			keys = append(keys, key)
+			values[key] = v.MapIndex(k)
+		}
+
+		sort.Strings(keys)
+		buf.WriteString("\n")
+		for _, k := range keys {
+			buf.WriteString(pad + k + ":")
+			if err := writeSnapshotValue(buf, values[k], indent+2); err != nil {
+				return err
+			}
+		}
+
+	case v.Kind() == reflect.Struct:
+		// Structs are written the way YAML marshals them.
+		b, err := yaml.Marshal(v.Interface())
+		if err != nil {
+			return errors.Wrap(err, "can't marshal configuration")
+		}
+
+		var m interface{}
+		if err := yaml.Unmarshal(b, &m); err != nil {
+			return errors.Wrap(err, "can't marshal configuration")
+		}
+
+		return writeSnapshotValue(buf, reflect.ValueOf(m), indent)
+
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		if v.Len() == 0 {
+			buf.WriteString(" []\n")
+			return nil
+		}
+
+		buf.WriteString("\n")
+		for i := 0; i < v.Len(); i++ {
+			buf.WriteString(pad + "-")
+			if err := writeSnapshotValue(buf, v.Index(i), indent+2); err != nil {
+				return err
+			}
+		}
+
+	default:
+		s, err := snapshotScalar(v)
+		if err != nil {
+			return err
+		}
+
+		buf.WriteString(" " + s + "\n")
+	}
+
+	return nil
+}
+
+// snapshotScalar formats a scalar so it is decoded back to the same type:
+// strings are always quoted and floats always have a fraction or an exponent.
+func snapshotScalar(v reflect.Value) (string, error) {
+	for v.IsValid() && (v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr) {
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		return "null", nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			return ".nan", nil
+		case math.IsInf(f, 1):
+			return ".inf", nil
+		case math.IsInf(f, -1):
+			return "-.inf", nil
+		}
+
+		s := strconv.FormatFloat(f, 'g', -1, v.Type().Bits())
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+
+		return s, nil
+	}
+
+	return "", fmt.Errorf("can't write a value of type %v in a snapshot", v.Type())
+}
+
+// NewProviderFromSnapshot creates a provider pinned to a snapshot written by
+// WriteSnapshot, e.g. to boot a service with the configuration of
+// an incident:
+//
+// 	if *pinned != "" {
+// 		f, err := os.Open(*pinned)
+// 		...
+// 		p, err = config.NewProviderFromSnapshot(f)
+// 	}
+//
+// It fails if the configuration doesn't match the checksum. Values report
+// the version and the creation time of the snapshot in their metadata, and
+// the files they were defined in when the snapshot was written.
+func NewProviderFromSnapshot(r io.Reader) (Provider, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't read snapshot")
+	}
+
+	var s snapshot
+	if err := yaml.Unmarshal(b, &s); err != nil {
+		return nil, errors.Wrap(err, "can't parse snapshot")
+	}
+
+	checksum, err := snapshotChecksum(s.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if checksum != s.Checksum {
+		return nil, fmt.Errorf("snapshot checksum mismatch: expected %q, got %q", s.Checksum, checksum)
+	}
+
+	created, err := time.Parse(time.RFC3339, s.Created)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't parse snapshot creation time")
+	}
+
+	y := newYAMLProviderFromValue(s.Config)
+	y.setOrigins(s.Files)
+	if s.Separator != "" {
+		y.keySeparator = s.Separator
+	}
+
+	c, err := newCachedProvider(y)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshotProvider{
+		Provider: c,
+		meta:     Metadata{Source: "snapshot", LoadedAt: created, Version: s.Version},
+	}, nil
+}
+
+func snapshotChecksum(config interface{}) (string, error) {
+	b, err := yaml.Marshal(config)
+	if err != nil {
+		return "", errors.Wrap(err, "can't marshal configuration")
+	}
+
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// snapshotProvider reports metadata of a snapshot for all the values.
+type snapshotProvider struct {
+	Provider
+
+	meta Metadata
+}
+
+func (s snapshotProvider) Name() string {
+	return s.meta.Source
+}
+
+func (s snapshotProvider) Get(key string) Value {
+	v := s.Provider.Get(key)
+	v.provider = s
+	return v
+}
+
+func (s snapshotProvider) Metadata(key string) Metadata {
+	m := s.meta
+	m.File = metadataOf(s.Provider, key).File
+	return m
+}
+
+func (s snapshotProvider) separator() string {
+	return separatorOf(s.Provider)
+}
+
+// Describe describes the snapshot by the name of its source.
+func (s snapshotProvider) Describe() Description {
+	d := Describe(s.Provider)
+	d.Name = s.Name()
+	return d
+}
+
+func (s snapshotProvider) keyOrder() *keyOrder {
+	return keyOrderOf(s.Provider)
+}
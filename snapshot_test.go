@@ -0,0 +1,130 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	base, err := NewYAMLProviderFromBytes([]byte("a: 1\nb:\n  c: [x, z]"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	override, err := NewStaticProvider(map[string]interface{}{"a": 2, "d": 1.5})
+	require.NoError(t, err, "Can't create a static provider")
+
+	pg, err := NewProviderGroup("group", base, override)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	before := time.Now().Add(-time.Second)
+	require.NoError(t, WriteSnapshot(&buf, pg, "v42"))
+	assert.Contains(t, buf.String(), "checksum: sha256:")
+
+	p, err := NewProviderFromSnapshot(&buf)
+	require.NoError(t, err, "Can't read the snapshot")
+
+	assert.Equal(t, 2, p.Get("a").Value())
+	assert.Equal(t, "z", p.Get("b").Get("c.1").Value())
+	assert.Equal(t, 1.5, p.Get("d").Value())
+	assert.Equal(t, pg.Get(Root).Value(), p.Get(Root).Value())
+	assert.Equal(t, "snapshot", p.Name())
+
+	m := p.Get("b.c").Metadata()
+	assert.Equal(t, "snapshot", m.Source)
+	assert.Equal(t, "v42", m.Version)
+	assert.True(t, m.LoadedAt.After(before))
+}
+
+func TestSnapshotWithSeparator(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromReaderWithSeparator("/", bytes.NewBufferString("hosts:\n  my.host: 80"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSnapshot(&buf, p, ""))
+	assert.NotContains(t, buf.String(), "version:")
+
+	s, err := NewProviderFromSnapshot(&buf)
+	require.NoError(t, err, "Can't read the snapshot")
+	assert.Equal(t, 80, s.Get("hosts/my.host").Value())
+	assert.Equal(t, "/", separatorOf(s))
+}
+
+func TestSnapshotScalarTypes(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+int: 1
+float: 1.0
+bool: true
+string: {int: "1", float: "1.0", bool: "true", "null": "null"}
+list: ["1", 1, 2.0, "", ~]
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSnapshot(&buf, p, ""))
+
+	s, err := NewProviderFromSnapshot(&buf)
+	require.NoError(t, err, "Can't read the snapshot")
+
+	assert.Equal(t, 1, s.Get("int").Value())
+	assert.Equal(t, 1.0, s.Get("float").Value())
+	assert.Equal(t, true, s.Get("bool").Value())
+	assert.Equal(t, "1", s.Get("string.int").Value())
+	assert.Equal(t, "1.0", s.Get("string.float").Value())
+	assert.Equal(t, "true", s.Get("string.bool").Value())
+	assert.Equal(t, "null", s.Get("string.null").Value())
+	assert.Equal(t, []interface{}{"1", 1, 2.0, "", nil}, s.Get("list").Value())
+	assert.Equal(t, p.Get(Root).Value(), s.Get(Root).Value())
+}
+
+func TestSnapshotErrors(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte("a: 1"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSnapshot(&buf, p, "v1"))
+
+	_, err = NewProviderFromSnapshot(strings.NewReader(strings.Replace(buf.String(), `"a": 1`, `"a": 2`, 1)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "snapshot checksum mismatch")
+
+	_, err = NewProviderFromSnapshot(strings.NewReader(strings.Replace(buf.String(), "created: ", "created: x", 1)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't parse snapshot creation time")
+
+	_, err = NewProviderFromSnapshot(strings.NewReader("config: ["))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't parse snapshot")
+}
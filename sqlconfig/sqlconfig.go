@@ -0,0 +1,117 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package sqlconfig opens database/sql handles from configuration subtrees
+// of the following shape:
+//
+// 	db:
+// 	  driver: postgres
+// 	  dsn: postgres://localhost/users
+// 	  pool:
+// 	    maxOpenConns: 10
+// 	    maxIdleConns: 2
+// 	    connMaxLifetime: 1h
+//
+// Pool settings can be changed on a live handle, so call Reload after
+// configuration is reloaded to apply new pool sizes and lifetimes.
+package sqlconfig // import "go.uber.org/config/sqlconfig"
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"go.uber.org/config"
+)
+
+// Pool configures connections of a handle.
+type Pool struct {
+	// MaxOpenConns limits open connections, zero means no limit.
+	MaxOpenConns int `yaml:"maxOpenConns"`
+
+	// MaxIdleConns limits idle connections, zero means no idle connections
+	// are kept.
+	MaxIdleConns int `yaml:"maxIdleConns" default:"2"`
+
+	// ConnMaxLifetime limits how long connections are reused, zero means
+	// forever.
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime"`
+}
+
+// Apply applies the settings to a handle, it is safe to call while the
+// handle is in use.
+func (p Pool) Apply(db *sql.DB) {
+	db.SetMaxOpenConns(p.MaxOpenConns)
+	db.SetMaxIdleConns(p.MaxIdleConns)
+	db.SetConnMaxLifetime(p.ConnMaxLifetime)
+}
+
+// Config of a database handle.
+type Config struct {
+	Driver string `yaml:"driver"`
+	DSN    string `yaml:"dsn"`
+	Pool   Pool   `yaml:"pool"`
+}
+
+// Load populates configuration of a handle from the key.
+func Load(p config.Provider, key string) (Config, error) {
+	var cfg Config
+	if err := p.Get(key).Populate(&cfg); err != nil {
+		return cfg, err
+	}
+
+	if cfg.Driver == "" {
+		return cfg, errors.New("database driver is missing")
+	}
+
+	if cfg.DSN == "" {
+		return cfg, errors.New("database dsn is missing")
+	}
+
+	return cfg, nil
+}
+
+// Open opens a handle with the configuration from the key.
+func Open(p config.Provider, key string) (*sql.DB, error) {
+	cfg, err := Load(p, key)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open(cfg.Driver, cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Pool.Apply(db)
+	return db, nil
+}
+
+// Reload applies pool settings from the key to an open handle. Changes of
+// the driver and the dsn require a new handle and are ignored.
+func Reload(db *sql.DB, p config.Provider, key string) error {
+	var pool Pool
+	if err := p.Get(key).Get("pool").Populate(&pool); err != nil {
+		return err
+	}
+
+	pool.Apply(db)
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sqlconfig
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("not implemented")
+}
+
+func init() {
+	sql.Register("sqlconfig-fake", fakeDriver{})
+}
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	p, err := config.NewYAMLProviderFromBytes([]byte(`
+db:
+  driver: sqlconfig-fake
+  dsn: fake://users
+  pool:
+    maxOpenConns: 10
+    connMaxLifetime: 1h
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	cfg, err := Load(p, "db")
+	require.NoError(t, err)
+	assert.Equal(t, Config{
+		Driver: "sqlconfig-fake",
+		DSN:    "fake://users",
+		Pool:   Pool{MaxOpenConns: 10, MaxIdleConns: 2, ConnMaxLifetime: time.Hour},
+	}, cfg)
+
+	db, err := Open(p, "db")
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.NoError(t, Reload(db, p, "db"))
+}
+
+func TestOpenErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"db: {dsn: x}":                  "database driver is missing",
+		"db: {driver: x}":               "database dsn is missing",
+		"db: {driver: missing, dsn: x}": `unknown driver "missing"`,
+		"db: {driver: x, dsn: x, pool: {maxOpenConns: many}}": `for key "db.pool.maxOpenConns"`,
+	}
+
+	for src, msg := range tests {
+		p, err := config.NewYAMLProviderFromBytes([]byte(src))
+		require.NoError(t, err, "Can't create a YAML provider")
+
+		_, err = Open(p, "db")
+		require.Error(t, err, "Expected an error for %q", src)
+		assert.Contains(t, err.Error(), msg)
+	}
+}
@@ -0,0 +1,353 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package structgen infers Go struct definitions from sample YAML
+// configuration, to bootstrap typed configuration for code, that reads values
+// key by key. Generated code is a starting point, that is meant to be
+// reviewed and edited, e.g. for
+//
+// 	http:
+// 	  port: 8080
+// 	  read-timeout: 5s
+//
+// it generates
+//
+// 	type Config struct {
+// 		HTTP ConfigHTTP `yaml:"http"`
+// 	}
+//
+// 	type ConfigHTTP struct {
+// 		Port        int           `yaml:"port"`
+// 		ReadTimeout time.Duration `yaml:"read-timeout"`
+// 	}
+package structgen // import "go.uber.org/config/structgen"
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.uber.org/config"
+)
+
+// Options of generated code.
+type Options struct {
+	// Package name, "config" by default.
+	Package string
+
+	// Name of the root struct, "Config" by default. Nested structs are named
+	// after their parents and fields, e.g. ConfigHTTP.
+	Name string
+}
+
+// Generate returns formatted Go source with struct definitions inferred from
+// a sample YAML mapping. Scalars get the types YAML decodes them into,
+// strings that look like durations become time.Duration, and elements of
+// sequences are merged. Mappings with keys, that can't be field names, become
+// maps.
+func Generate(sample []byte, opts Options) ([]byte, error) {
+	if opts.Package == "" {
+		opts.Package = "config"
+	}
+
+	if opts.Name == "" {
+		opts.Name = "Config"
+	}
+
+	p, err := config.NewYAMLProviderFromBytes(sample)
+	if err != nil {
+		return nil, err
+	}
+
+	root := infer(p.Get(config.Root).Value())
+	if root.kind != structKind {
+		return nil, errors.New("sample must be a YAML mapping with string keys")
+	}
+
+	g := generator{names: map[string]bool{opts.Name: true}}
+	g.define(opts.Name, root)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n\n", opts.Package)
+	if g.usesTime {
+		buf.WriteString("import \"time\"\n\n")
+	}
+
+	buf.Write(g.buf.Bytes())
+	return format.Source(buf.Bytes())
+}
+
+type kind int
+
+const (
+	unknownKind kind = iota
+	boolKind
+	intKind
+	floatKind
+	durationKind
+	stringKind
+	sliceKind
+	mapKind
+	structKind
+	anyKind
+)
+
+// typ is an inferred type of a value.
+type typ struct {
+	kind kind
+
+	// Element type of slices and maps.
+	elem *typ
+
+	// Field types of structs by YAML keys.
+	fields map[string]*typ
+}
+
+func infer(value interface{}) *typ {
+	switch v := value.(type) {
+	case nil:
+		return &typ{kind: unknownKind}
+	case bool:
+		return &typ{kind: boolKind}
+	case int, int64, uint64:
+		return &typ{kind: intKind}
+	case float64:
+		return &typ{kind: floatKind}
+	case string:
+		if isDuration(v) {
+			return &typ{kind: durationKind}
+		}
+
+		return &typ{kind: stringKind}
+	case []interface{}:
+		elem := &typ{kind: unknownKind}
+		for _, e := range v {
+			elem = merge(elem, infer(e))
+		}
+
+		return &typ{kind: sliceKind, elem: elem}
+	case map[interface{}]interface{}:
+		return inferMapping(v)
+	}
+
+	return &typ{kind: anyKind}
+}
+
+// inferMapping returns a struct type if all the keys can be field names, or
+// a map type otherwise.
+func inferMapping(m map[interface{}]interface{}) *typ {
+	fields := make(map[string]*typ, len(m))
+	names := make(map[string]bool, len(m))
+	isStruct := true
+	elem := &typ{kind: unknownKind}
+	for k, v := range m {
+		t := infer(v)
+		elem = merge(elem, t)
+
+		key, ok := k.(string)
+		name := fieldName(key)
+		if !ok || name == "" || names[name] {
+			isStruct = false
+			continue
+		}
+
+		names[name] = true
+		fields[key] = t
+	}
+
+	if !isStruct {
+		return &typ{kind: mapKind, elem: elem}
+	}
+
+	return &typ{kind: structKind, fields: fields}
+}
+
+// isDuration returns true for strings like 5s or 1h30m.
+func isDuration(s string) bool {
+	if s == "" || !unicode.IsLetter(rune(s[len(s)-1])) {
+		return false
+	}
+
+	_, err := time.ParseDuration(s)
+	return err == nil
+}
+
+// merge returns a type, that can hold values of both types.
+func merge(a, b *typ) *typ {
+	switch {
+	case a.kind == unknownKind:
+		return b
+	case b.kind == unknownKind:
+		return a
+	case a.kind == b.kind:
+		switch a.kind {
+		case sliceKind, mapKind:
+			return &typ{kind: a.kind, elem: merge(a.elem, b.elem)}
+		case structKind:
+			fields := make(map[string]*typ, len(a.fields))
+			for k, v := range a.fields {
+				fields[k] = v
+			}
+
+			for k, v := range b.fields {
+				if f, ok := fields[k]; ok {
+					v = merge(f, v)
+				}
+
+				fields[k] = v
+			}
+
+			return &typ{kind: structKind, fields: fields}
+		}
+
+		return a
+	case a.kind == intKind && b.kind == floatKind, a.kind == floatKind && b.kind == intKind:
+		return &typ{kind: floatKind}
+	case a.kind == durationKind && b.kind == stringKind, a.kind == stringKind && b.kind == durationKind:
+		return &typ{kind: stringKind}
+	}
+
+	return &typ{kind: anyKind}
+}
+
+type generator struct {
+	buf      bytes.Buffer
+	usesTime bool
+	names    map[string]bool
+}
+
+// namedStruct is a struct type, that needs to be defined with the name.
+type namedStruct struct {
+	name string
+	typ  *typ
+}
+
+// define writes a struct definition and definitions of its nested structs.
+func (g *generator) define(name string, t *typ) {
+	keys := make([]string, 0, len(t.fields))
+	for k := range t.fields {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	var structs []*namedStruct
+	fmt.Fprintf(&g.buf, "type %s struct {\n", name)
+	for _, k := range keys {
+		field := fieldName(k)
+		typeName, s := g.typeName(name+field, t.fields[k])
+		if s != nil {
+			structs = append(structs, s)
+		}
+
+		fmt.Fprintf(&g.buf, "\t%s %s `yaml:%q`\n", field, typeName, k)
+	}
+
+	g.buf.WriteString("}\n\n")
+	for _, s := range structs {
+		g.define(s.name, s.typ)
+	}
+}
+
+// typeName returns a Go type name for the type and a struct, that needs to be
+// defined for it, if any.
+func (g *generator) typeName(name string, t *typ) (string, *namedStruct) {
+	switch t.kind {
+	case boolKind:
+		return "bool", nil
+	case intKind:
+		return "int", nil
+	case floatKind:
+		return "float64", nil
+	case durationKind:
+		g.usesTime = true
+		return "time.Duration", nil
+	case stringKind:
+		return "string", nil
+	case sliceKind:
+		elem, s := g.typeName(name, t.elem)
+		return "[]" + elem, s
+	case mapKind:
+		elem, s := g.typeName(name, t.elem)
+		return "map[string]" + elem, s
+	case structKind:
+		name = g.unique(name)
+		return name, &namedStruct{name: name, typ: t}
+	}
+
+	return "interface{}", nil
+}
+
+// unique returns the name or the name with a numeric suffix, if the name
+// is already taken by another struct, e.g. ConfigAB for both a-b and a.b keys.
+func (g *generator) unique(name string) string {
+	res := name
+	for i := 2; g.names[res]; i++ {
+		res = fmt.Sprintf("%s%d", name, i)
+	}
+
+	g.names[res] = true
+	return res
+}
+
+// Common initialisms, that are capitalized in Go names.
+var _initialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true,
+	"DB": true, "DNS": true, "EOF": true, "GUID": true, "HTML": true,
+	"HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"QPS": true, "RAM": true, "RPC": true, "SLA": true, "SMTP": true,
+	"SQL": true, "SSH": true, "TCP": true, "TLS": true, "TTL": true,
+	"UDP": true, "UI": true, "UID": true, "UUID": true, "URI": true,
+	"URL": true, "UTF8": true, "VM": true, "XML": true, "XSRF": true,
+	"XSS": true, "YAML": true,
+}
+
+// fieldName converts a YAML key to an exported Go field name, e.g.
+// read-timeout to ReadTimeout. It returns an empty string for keys, that
+// can't be converted.
+func fieldName(key string) string {
+	parts := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var buf bytes.Buffer
+	for _, part := range parts {
+		if upper := strings.ToUpper(part); _initialisms[upper] {
+			buf.WriteString(upper)
+			continue
+		}
+
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		buf.WriteString(string(runes))
+	}
+
+	name := buf.String()
+	if name == "" || !unicode.IsUpper([]rune(name)[0]) {
+		return ""
+	}
+
+	return name
+}
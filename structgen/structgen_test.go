@@ -0,0 +1,133 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package structgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	src, err := Generate([]byte(`
+name: api
+http:
+  port: 8080
+  read-timeout: 5s
+  tls: true
+ratio: 0.5
+servers:
+  - host: a
+    weight: 1
+  - host: b
+    weight: 0.5
+    zone: east
+tags: [a, b]
+empty: []
+nothing:
+labels:
+  1: one
+  2: two
+`), Options{Package: "service"})
+	require.NoError(t, err)
+
+	assert.Equal(t, `package service
+
+import "time"
+
+type Config struct {
+	Empty   []interface{}     `+"`yaml:\"empty\"`"+`
+	HTTP    ConfigHTTP        `+"`yaml:\"http\"`"+`
+	Labels  map[string]string `+"`yaml:\"labels\"`"+`
+	Name    string            `+"`yaml:\"name\"`"+`
+	Nothing interface{}       `+"`yaml:\"nothing\"`"+`
+	Ratio   float64           `+"`yaml:\"ratio\"`"+`
+	Servers []ConfigServers   `+"`yaml:\"servers\"`"+`
+	Tags    []string          `+"`yaml:\"tags\"`"+`
+}
+
+type ConfigHTTP struct {
+	Port        int           `+"`yaml:\"port\"`"+`
+	ReadTimeout time.Duration `+"`yaml:\"read-timeout\"`"+`
+	TLS         bool          `+"`yaml:\"tls\"`"+`
+}
+
+type ConfigServers struct {
+	Host   string  `+"`yaml:\"host\"`"+`
+	Weight float64 `+"`yaml:\"weight\"`"+`
+	Zone   string  `+"`yaml:\"zone\"`"+`
+}
+`, string(src))
+}
+
+func TestGenerateUniqueNames(t *testing.T) {
+	t.Parallel()
+
+	src, err := Generate([]byte(`
+a-b:
+  c: 1
+a:
+  b:
+    d: x
+mixed: [1, a]
+`), Options{Name: "Root"})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(src), "package config\n")
+	assert.NotContains(t, string(src), "import")
+	assert.Contains(t, string(src), "type RootA struct")
+	assert.Contains(t, string(src), "type RootAB struct")
+	assert.Contains(t, string(src), "type RootAB2 struct")
+	assert.Contains(t, string(src), "Mixed []interface{}")
+}
+
+func TestGenerateErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Generate([]byte("[1, 2]"), Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sample must be a YAML mapping")
+
+	_, err = Generate([]byte("a: ["), Options{})
+	require.Error(t, err)
+}
+
+func TestFieldName(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"port":         "Port",
+		"read-timeout": "ReadTimeout",
+		"max_conns":    "MaxConns",
+		"db.url":       "DBURL",
+		"userId":       "UserId",
+		"1st":          "",
+		"":             "",
+		"-":            "",
+	}
+
+	for key, expected := range tests {
+		assert.Equal(t, expected, fieldName(key), "Unexpected field name for %q", key)
+	}
+}
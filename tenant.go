@@ -0,0 +1,54 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// _tenantsKey is a top level key with per tenant overrides.
+const _tenantsKey = "tenants"
+
+// NewTenantProvider creates a provider with configuration of a tenant:
+// values under the tenants.<id> key override global values, e.g. for
+//
+// 	quota: 100
+// 	tenants:
+// 	  acme:
+// 	    quota: 500
+//
+// the quota of the acme tenant is 500 and other tenants get 100.
+// The tenants key itself is not visible in the new provider, so tenants
+// can't see overrides of each other.
+func NewTenantProvider(p Provider, id string) (Provider, error) {
+	if p == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	if id == "" {
+		return nil, errors.New("empty tenant id")
+	}
+
+	base := hiddenKeyProvider{Provider: p, key: _tenantsKey}
+	tenant := NewScopedProvider(_tenantsKey+base.separator()+escapeSeparators(id, base.separator()), p)
+	return NewProviderGroup(fmt.Sprintf("%s for tenant %q", p.Name(), id), base, tenant)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTenantProvider(t *testing.T) {
+	t.Parallel()
+
+	y, err := NewYAMLProviderFromBytes([]byte(`
+limits:
+  quota: 100
+  burst: 10
+tenants:
+  acme:
+    limits:
+      quota: 500
+  acme.eu:
+    limits:
+      burst: 20
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	type limits struct {
+		Quota int
+		Burst int
+	}
+
+	tests := map[string]limits{
+		"acme":    {Quota: 500, Burst: 10},
+		"acme.eu": {Quota: 100, Burst: 20},
+		"other":   {Quota: 100, Burst: 10},
+	}
+
+	for id, expected := range tests {
+		p, err := NewTenantProvider(y, id)
+		require.NoError(t, err)
+
+		var l limits
+		require.NoError(t, p.Get("limits").Populate(&l))
+		assert.Equal(t, expected, l, "Unexpected limits for %q", id)
+		assert.False(t, p.Get(_tenantsKey).HasValue())
+	}
+
+	p, err := NewTenantProvider(y, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, `cached "yaml" for tenant "acme"`, p.Name())
+}
+
+func TestNewTenantProviderErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewTenantProvider(nil, "acme")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "received a nil provider")
+
+	_, err = NewTenantProvider(NopProvider{}, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty tenant id")
+}
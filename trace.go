@@ -0,0 +1,227 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MergeStrategy tells how a source changes a value of earlier sources.
+type MergeStrategy int
+
+const (
+	// MergeOverride is a scalar replacing a scalar.
+	MergeOverride MergeStrategy = iota + 1
+	// MergeReplace is a sequence or a scalar replacing a mapping or
+	// a sequence, or a sequence replacing a scalar. Everything earlier
+	// sources have under the key is lost.
+	MergeReplace
+	// MergeNullIgnored is a null, that doesn't replace a value.
+	MergeNullIgnored
+)
+
+func (s MergeStrategy) String() string {
+	switch s {
+	case MergeOverride:
+		return "override"
+	case MergeReplace:
+		return "replace"
+	case MergeNullIgnored:
+		return "null ignored"
+	}
+
+	return "MergeStrategy(" + strconv.Itoa(int(s)) + ")"
+}
+
+// A MergeDecision describes a value defined by several sources. Sources are
+// file names, or "source N" for readers, where N is the index of a source.
+type MergeDecision struct {
+	Key      string
+	Strategy MergeStrategy
+
+	// Winner is the source, that defines the value.
+	Winner string
+
+	// Losers are sources, that define values replaced by the winner.
+	Losers []string
+}
+
+// MergeTrace is a list of merge decisions in the order they are made.
+type MergeTrace struct {
+	Decisions []MergeDecision
+}
+
+// WithMergeTrace records decisions merging sources makes into the trace,
+// e.g. to debug surprising results of layering many files. The trace is
+// reset first.
+func WithMergeTrace(t *MergeTrace) YAMLOption {
+	return func(o *yamlOptions) {
+		o.settings.trace = t
+	}
+}
+
+// For returns decisions about the key and its parents, which can replace
+// its value too.
+func (t MergeTrace) For(key string, separator string) []MergeDecision {
+	var res []MergeDecision
+	for _, d := range t.Decisions {
+		if d.Key == Root || d.Key == key || strings.HasPrefix(key, d.Key+separator) {
+			res = append(res, d)
+		}
+	}
+
+	return res
+}
+
+// mergeTracer records merge decisions, it tracks sources of values, which
+// are scalars, sequences and empty mappings.
+type mergeTracer struct {
+	trace     *MergeTrace
+	separator string
+
+	// Indexes of sources of values by keys and names of the sources.
+	sources map[string]int
+	names   []string
+}
+
+func newMergeTracer(t *MergeTrace, separator string) *mergeTracer {
+	*t = MergeTrace{}
+	return &mergeTracer{trace: t, separator: separator, sources: make(map[string]int)}
+}
+
+// add records decisions merging the next source into the destination makes.
+// It must be called before the source is merged.
+func (m *mergeTracer) add(dst, src interface{}, name string) {
+	if name == "" {
+		name = fmt.Sprintf("source %d", len(m.names))
+	}
+
+	m.names = append(m.names, name)
+	m.merge(Root, dst, src, len(m.names)-1)
+}
+
+// merge records decisions merging a value of the source into the
+// destination makes, like mergeMaps does.
+func (m *mergeTracer) merge(key string, dst, src interface{}, source int) {
+	if dst == nil {
+		m.claim(key, src, source)
+		return
+	}
+
+	if src == nil {
+		losers := m.owners(key)
+		if len(losers) > 0 {
+			m.record(key, MergeNullIgnored, losers[len(losers)-1], []int{source})
+		}
+
+		return
+	}
+
+	srcMap, srcOK := src.(map[interface{}]interface{})
+	dstMap, dstOK := dst.(map[interface{}]interface{})
+	if srcOK && dstOK {
+		for _, k := range sortedKeys(srcMap) {
+			child := joinKey(key, escapeSeparators(fmt.Sprint(k), m.separator), m.separator)
+			m.merge(child, dstMap[k], srcMap[k], source)
+		}
+
+		return
+	}
+
+	if srcOK {
+		// Merging fails then.
+		return
+	}
+
+	strategy := MergeReplace
+	if !dstOK && !isSequence(dst) && !isSequence(src) {
+		strategy = MergeOverride
+	}
+
+	losers := m.owners(key)
+	m.forget(key)
+	m.claim(key, src, source)
+	m.record(key, strategy, source, losers)
+}
+
+func (m *mergeTracer) record(key string, s MergeStrategy, winner int, losers []int) {
+	d := MergeDecision{Key: key, Strategy: s, Winner: m.names[winner]}
+	for _, i := range losers {
+		d.Losers = append(d.Losers, m.names[i])
+	}
+
+	m.trace.Decisions = append(m.trace.Decisions, d)
+}
+
+// claim records the source of all the values of a tree.
+func (m *mergeTracer) claim(key string, value interface{}, source int) {
+	if v, ok := value.(map[interface{}]interface{}); ok && len(v) > 0 {
+		for k, child := range v {
+			m.claim(joinKey(key, escapeSeparators(fmt.Sprint(k), m.separator), m.separator), child, source)
+		}
+
+		return
+	}
+
+	if value != nil {
+		m.sources[key] = source
+	}
+}
+
+// owners returns sources of values of a tree in their order.
+func (m *mergeTracer) owners(key string) []int {
+	seen := make(map[int]bool)
+	var res []int
+	for k, source := range m.sources {
+		if m.under(k, key) && !seen[source] {
+			seen[source] = true
+			res = append(res, source)
+		}
+	}
+
+	sort.Ints(res)
+	return res
+}
+
+// forget removes sources of values of a tree.
+func (m *mergeTracer) forget(key string) {
+	for k := range m.sources {
+		if m.under(k, key) {
+			delete(m.sources, k)
+		}
+	}
+}
+
+func (m *mergeTracer) under(k, key string) bool {
+	return key == Root || k == key || strings.HasPrefix(k, key+m.separator)
+}
+
+func isSequence(v interface{}) bool {
+	_, ok := v.([]interface{})
+	return ok
+}
+
+func sortedKeys(m map[interface{}]interface{}) []interface{} {
+	return (*keyOrder)(nil).orderedKeys(m)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMergeTrace(t *testing.T) {
+	t.Parallel()
+
+	var trace MergeTrace
+	p, err := NewYAML(
+		File("testdata/fs/base.yaml"),
+		Source(strings.NewReader("b: reader\nhosts: {a: 1, b: 2}\nports: [1]\nname: x")),
+		Source(strings.NewReader("a: ~\nhosts: [c]\nports: [2]\nname: y\nnew: 1")),
+		WithMergeTrace(&trace),
+	)
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, []string{"c"}, []string{p.Get("hosts.0").String()})
+
+	assert.Equal(t, []MergeDecision{
+		{Key: "b", Strategy: MergeOverride, Winner: "source 1", Losers: []string{"testdata/fs/base.yaml"}},
+		{Key: "a", Strategy: MergeNullIgnored, Winner: "testdata/fs/base.yaml", Losers: []string{"source 2"}},
+		{Key: "hosts", Strategy: MergeReplace, Winner: "source 2", Losers: []string{"source 1"}},
+		{Key: "name", Strategy: MergeOverride, Winner: "source 2", Losers: []string{"source 1"}},
+		{Key: "ports", Strategy: MergeReplace, Winner: "source 2", Losers: []string{"source 1"}},
+	}, trace.Decisions)
+
+	assert.Equal(t, []MergeDecision{trace.Decisions[2]}, trace.For("hosts.0", "."))
+	assert.Empty(t, trace.For("new", "."))
+	assert.Equal(t, "null ignored", MergeNullIgnored.String())
+	assert.Equal(t, "MergeStrategy(0)", MergeStrategy(0).String())
+}
+
+func TestWithMergeTraceRoot(t *testing.T) {
+	t.Parallel()
+
+	var trace MergeTrace
+	_, err := NewYAML(
+		Source(strings.NewReader("a: 1")),
+		Source(strings.NewReader("- 1")),
+		WithMergeTrace(&trace),
+	)
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	assert.Equal(t, []MergeDecision{
+		{Key: Root, Strategy: MergeReplace, Winner: "source 1", Losers: []string{"source 0"}},
+	}, trace.Decisions)
+	assert.Len(t, trace.For("a", "."), 1, "Decisions about the root apply to all keys")
+}
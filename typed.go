@@ -0,0 +1,106 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.18
+// +build go1.18
+
+package config
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// Typed is a handle to a struct populated from a configuration key. It can be
+// safely loaded and reloaded from different goroutines.
+type Typed[T any] struct {
+	provider Provider
+	key      string
+	current  atomic.Value // *T
+	mu       sync.Mutex   // serializes reloads
+}
+
+// Bind populates a value of type T from the key of the provider and returns
+// a handle to it, e.g.
+//
+// 	h, err := config.Bind[ServerConfig](p, "server")
+// 	...
+// 	cfg := h.Load()
+func Bind[T any](p Provider, key string) (*Typed[T], error) {
+	if p == nil {
+		return nil, errors.New("received a nil provider")
+	}
+
+	h := &Typed[T]{provider: p, key: key}
+	if err := h.Reload(); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// Load returns the latest populated value.
+func (h *Typed[T]) Load() T {
+	return *h.current.Load().(*T)
+}
+
+// Reload populates a new value from the provider and atomically replaces
+// the current one with it. The current value is kept on errors.
+func (h *Typed[T]) Reload() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	v := new(T)
+	if err := h.provider.Get(h.key).Populate(v); err != nil {
+		return err
+	}
+
+	h.current.Store(v)
+	return nil
+}
+
+// GetAs populates a value of type T from the key of the provider, e.g.
+//
+// 	timeout, err := config.GetAs[time.Duration](p, "timeouts.read")
+//
+// Unlike Populate, it returns an error if the value is missing.
+func GetAs[T any](p Provider, key string) (T, error) {
+	var res T
+	if p == nil {
+		return res, errors.New("received a nil provider")
+	}
+
+	v := p.Get(key)
+	if v.err != nil {
+		return res, v.err
+	}
+
+	if !v.HasValue() {
+		if v.missing != nil {
+			return res, v.missing
+		}
+
+		return res, errorWithKey(errors.New("value is missing"), key)
+	}
+
+	err := v.Populate(&res)
+	return res, err
+}
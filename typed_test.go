@@ -0,0 +1,141 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.18
+// +build go1.18
+
+package config
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mutableProvider serves values that can be replaced during tests.
+type mutableProvider struct {
+	sync.RWMutex
+	Provider
+}
+
+func (m *mutableProvider) set(p Provider) {
+	m.Lock()
+	m.Provider = p
+	m.Unlock()
+}
+
+func (m *mutableProvider) Get(key string) Value {
+	m.RLock()
+	defer m.RUnlock()
+	return m.Provider.Get(key)
+}
+
+func TestBind(t *testing.T) {
+	t.Parallel()
+
+	type server struct {
+		Port int `min:"1"`
+	}
+
+	first, err := NewStaticProvider(map[string]interface{}{"server": map[string]int{"port": 80}})
+	require.NoError(t, err, "Can't create a static provider")
+
+	p := &mutableProvider{Provider: first}
+	h, err := Bind[server](p, "server")
+	require.NoError(t, err)
+	assert.Equal(t, server{Port: 80}, h.Load())
+
+	second, err := NewStaticProvider(map[string]interface{}{"server": map[string]int{"port": 8080}})
+	require.NoError(t, err, "Can't create a static provider")
+
+	p.set(second)
+	assert.Equal(t, server{Port: 80}, h.Load(), "Values should change only on reloads")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, h.Reload())
+		}()
+		go func() {
+			defer wg.Done()
+			h.Load()
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, server{Port: 8080}, h.Load())
+
+	invalid, err := NewStaticProvider(map[string]interface{}{"server": map[string]int{"port": 0}})
+	require.NoError(t, err, "Can't create a static provider")
+
+	p.set(invalid)
+	require.Error(t, h.Reload())
+	assert.Equal(t, server{Port: 8080}, h.Load(), "Value shouldn't change on errors")
+}
+
+func TestBindErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Bind[int](nil, "a")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "received a nil provider")
+
+	p, err := NewStaticProvider(map[string]string{"a": "b"})
+	require.NoError(t, err, "Can't create a static provider")
+
+	_, err = Bind[int](p, "a")
+	require.Error(t, err)
+}
+
+func TestGetAs(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+timeouts:
+  read: 5s
+hosts: [a, b]
+port: http
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	timeout, err := GetAs[time.Duration](p, "timeouts.read")
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, timeout)
+
+	hosts, err := GetAs[[]string](p, "hosts")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, hosts)
+
+	_, err = GetAs[int](p, "port")
+	require.Error(t, err)
+
+	_, err = GetAs[int](p, "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `for key "missing": value is missing`)
+
+	_, err = GetAs[int](nil, "port")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "received a nil provider")
+}
@@ -21,13 +21,24 @@
 package config
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"math"
+	"path/filepath"
 	"reflect"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
 )
 
 const _separator = "."
 
+// _escape is used to escape separators in keys.
+const _escape = '\\'
+
 var _typeOfString = reflect.TypeOf("string")
 
 // A Value holds the value of a configuration
@@ -37,6 +48,12 @@ type Value struct {
 	key      string
 	value    interface{}
 	found    bool
+
+	// Error of loading the provider, see WithLazyLoad.
+	err error
+
+	// Reason of a missing value, e.g. an out of range array index.
+	missing error
 }
 
 // NewValue creates a configuration value from a provider and a set
@@ -66,7 +83,13 @@ func (cv Value) Source() string {
 // WithDefault sets the default value that can be overridden
 // by providers with a highger priority.
 func (cv Value) WithDefault(value interface{}) (Value, error) {
-	p, err := NewStaticProvider(map[string]interface{}{cv.key: value})
+	reader, err := toReader(map[string]interface{}{cv.key: value})
+	if err != nil {
+		return cv, err
+	}
+
+	// Defaults should be accessed with the same key separator as the value.
+	p, err := NewYAMLProviderFromReaderWithSeparator(separatorOf(cv.provider), reader)
 	if err != nil {
 		return cv, err
 	}
@@ -89,12 +112,270 @@ func (cv Value) HasValue() bool {
 	return cv.found
 }
 
+// IsNull returns whether the key is explicitly set to null, e.g. with
+// "key: ~". HasValue returns true for such keys and false for missing ones,
+// so together they tell "explicitly disabled" from "use the default".
+func (cv Value) IsNull() bool {
+	return cv.found && cv.value == nil
+}
+
 // Value returns the underlying configuration's value.
 func (cv Value) Value() interface{} {
 	return cv.value
 }
 
-// Get returns a value scoped in the current value.
+// AsBytesBase64 decodes a base64 encoded string value, e.g. a certificate
+// or a key.
+func (cv Value) AsBytesBase64() ([]byte, error) {
+	if !cv.HasValue() {
+		return nil, errorWithKey(errors.New("value is missing"), cv.key)
+	}
+
+	s, ok := cv.Value().(string)
+	if !ok {
+		return nil, errorWithKey(fmt.Errorf("can't decode %T as base64", cv.Value()), cv.key)
+	}
+
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errorWithKey(err, cv.key)
+	}
+
+	return b, nil
+}
+
+// AsLocation returns a time zone for an IANA time zone name value, e.g.
+// America/New_York. See time.LoadLocation for the names it accepts.
+func (cv Value) AsLocation() (*time.Location, error) {
+	if !cv.HasValue() {
+		return nil, errorWithKey(errors.New("value is missing"), cv.key)
+	}
+
+	name, ok := cv.Value().(string)
+	if !ok {
+		return nil, errorWithKey(fmt.Errorf("can't convert %T to a time zone", cv.Value()), cv.key)
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errorWithKey(err, cv.key)
+	}
+
+	return loc, nil
+}
+
+// AsPath returns a path value with a leading ~ expanded by ExpandPath. Relative
+// paths are resolved relative to the directory of the file the value is
+// defined in, if it is known, so they don't depend on the working
+// directory, e.g. for
+//
+// 	# /etc/service/base.yaml
+// 	tls:
+// 	  cert: certs/service.pem
+//
+// Get("tls.cert").AsPath() returns "/etc/service/certs/service.pem".
+func (cv Value) AsPath() (string, error) {
+	if !cv.HasValue() {
+		return "", errorWithKey(errors.New("value is missing"), cv.key)
+	}
+
+	path, ok := cv.Value().(string)
+	if !ok {
+		return "", errorWithKey(fmt.Errorf("can't convert %T to a path", cv.Value()), cv.key)
+	}
+
+	path = ExpandPath(path)
+	if file := cv.Metadata().File; file != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(file), path)
+	}
+
+	return path, nil
+}
+
+// AsEnum returns a string value if it is one of the allowed values, or an
+// error listing all of them otherwise.
+func (cv Value) AsEnum(allowed ...string) (string, error) {
+	if !cv.HasValue() {
+		return "", errorWithKey(errors.New("value is missing"), cv.key)
+	}
+
+	s := cv.String()
+	return s, errorWithKey(checkOneOf(s, allowed), cv.key)
+}
+
+// AsStringList returns a sequence of scalars as strings, or splits a scalar
+// on the separator, a comma if it is empty, e.g. for values of environment
+// variables, which can't be YAML sequences: "a, b ,c" is [a b c]. Items are
+// trimmed and empty ones are skipped.
+func (cv Value) AsStringList(separator string) ([]string, error) {
+	if !cv.HasValue() {
+		return nil, errorWithKey(errors.New("value is missing"), cv.key)
+	}
+
+	if separator == "" {
+		separator = ","
+	}
+
+	var items []string
+	switch v := cv.Value().(type) {
+	case []interface{}:
+		items = make([]string, 0, len(v))
+		for i, item := range v {
+			switch item.(type) {
+			case map[interface{}]interface{}, []interface{}:
+				return nil, errorWithKey(errors.New("expected a scalar"), joinKey(cv.key, strconv.Itoa(i), separatorOf(cv.provider)))
+			}
+
+			items = append(items, fmt.Sprint(item))
+		}
+	case map[interface{}]interface{}:
+		return nil, errorWithKey(errors.New("can't convert a mapping to a list"), cv.key)
+	default:
+		items = strings.Split(fmt.Sprint(v), separator)
+	}
+
+	res := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+
+	return res, nil
+}
+
+// TryAsInt64 returns an integer value as int64 if it fits without loss,
+// e.g. a large identifier. Strings of decimal integers are parsed, floats
+// are accepted only if they are integers exactly representable as float64.
+func (cv Value) TryAsInt64() (int64, bool) {
+	switch v := cv.Value().(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v), true
+		}
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) <= 1<<53 {
+			return int64(v), true
+		}
+	case string:
+		if n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+			return n, true
+		}
+	}
+
+	return 0, false
+}
+
+// TryAsUint64 returns a non-negative integer value as uint64 if it fits
+// without loss, e.g. a byte count, like TryAsInt64.
+func (cv Value) TryAsUint64() (uint64, bool) {
+	switch v := cv.Value().(type) {
+	case int:
+		if v >= 0 {
+			return uint64(v), true
+		}
+	case int64:
+		if v >= 0 {
+			return uint64(v), true
+		}
+	case uint64:
+		return v, true
+	case float64:
+		if v == math.Trunc(v) && v >= 0 && v <= 1<<53 {
+			return uint64(v), true
+		}
+	case string:
+		if n, err := strconv.ParseUint(strings.TrimSpace(v), 10, 64); err == nil {
+			return n, true
+		}
+	}
+
+	return 0, false
+}
+
+// AsTemplate parses a string value as a text/template with the functions,
+// e.g. a message format configured by operators, and names the template
+// by the key:
+//
+// 	greeting, err := p.Get("messages.greeting").AsTemplate(template.FuncMap{
+// 		"upper": strings.ToUpper,
+// 	})
+// 	...
+// 	err = greeting.Execute(w, user)
+func (cv Value) AsTemplate(funcs template.FuncMap) (*template.Template, error) {
+	if !cv.HasValue() {
+		return nil, errorWithKey(errors.New("value is missing"), cv.key)
+	}
+
+	text, ok := cv.Value().(string)
+	if !ok {
+		return nil, errorWithKey(fmt.Errorf("can't convert %T to a template", cv.Value()), cv.key)
+	}
+
+	tmpl, err := template.New(cv.key).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, errorWithKey(err, cv.key)
+	}
+
+	return tmpl, nil
+}
+
+// checkOneOf returns an error if the value is not one of the allowed values.
+func checkOneOf(value string, allowed []string) error {
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%q is not one of: %s", value, strings.Join(allowed, ", "))
+}
+
+// Keys returns keys of children of a mapping value in the order they first
+// appear in the sources, if the provider keeps it with WithKeyOrder, or
+// sorted otherwise, and indexes of a sequence value. Other values have no
+// keys. Separators in keys aren't escaped.
+func (cv Value) Keys() []string {
+	switch v := cv.Value().(type) {
+	case map[interface{}]interface{}:
+		var order *keyOrder
+		if cv.provider != nil {
+			order = keyOrderOf(cv.provider)
+			if sep := separatorOf(cv.provider); cv.key != Root {
+				order = order.find(splitKey(cv.key, sep), sep)
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for _, key := range order.orderedKeys(v) {
+			keys = append(keys, fmt.Sprint(key))
+		}
+
+		return keys
+	case []interface{}:
+		keys := make([]string, len(v))
+		for i := range v {
+			keys[i] = strconv.Itoa(i)
+		}
+
+		return keys
+	}
+
+	return nil
+}
+
+// Get returns a value scoped in the current value, e.g. of a field of an
+// array element, without joining keys with separators:
+//
+// 	for _, i := range servers.Keys() {
+// 		port := servers.Get(i).Get("tls").Get("port")
+// 	}
+//
+// Root returns the value itself.
 func (cv Value) Get(key string) Value {
 	return NewScopedProvider(cv.key, cv.provider).Get(key)
 }
@@ -124,16 +405,51 @@ func convertValue(value interface{}, targetType reflect.Type) (interface{}, erro
 
 // Populate fills in an object from configuration.
 func (cv Value) Populate(target interface{}) error {
+	return cv.populate(target, populateOptions{})
+}
+
+func (cv Value) populate(target interface{}, o populateOptions) (err error) {
+	d := decoder{Value: &cv, m: make(map[interface{}]struct{}), options: o}
+	if o.recoverPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Key: d.key, Value: r, Stack: debug.Stack()}
+			}
+		}()
+	}
+
 	if reflect.TypeOf(target).Kind() != reflect.Ptr {
 		return fmt.Errorf("can't populate non pointer type %T", target)
 	}
 
+	if cv.err != nil {
+		return cv.err
+	}
+
+	if !cv.found && cv.missing != nil {
+		return cv.missing
+	}
+
 	ptr := reflect.Indirect(reflect.ValueOf(target))
 	if !ptr.IsValid() {
 		return fmt.Errorf("can't populate nil %T", target)
 	}
 
-	d := decoder{Value: &cv, m: make(map[interface{}]struct{})}
+	if err := d.unmarshal(cv.key, ptr, ""); err != nil {
+		return err
+	}
+
+	if err := d.rangeError(); err != nil {
+		if !o.bestEffort {
+			return err
+		}
+
+		d.failed = append(d.failed, err)
+	}
+
+	if len(d.failed) > 0 {
+		return PopulateErrors(d.failed)
+	}
 
-	return d.unmarshal(cv.key, ptr, "")
+	return nil
 }
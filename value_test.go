@@ -21,7 +21,16 @@
 package config
 
 import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"text/template"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -41,3 +50,396 @@ func TestSource(t *testing.T) {
 	assert.Empty(t, Value{}.Source())
 	assert.Equal(t, "NopProvider", Value{provider: NopProvider{}}.Source())
 }
+
+func TestAsBytesBase64(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewStaticProvider(map[string]interface{}{
+		"key":    "aGVsbG8=",
+		"broken": "not base64!",
+		"int":    1,
+	})
+	require.NoError(t, err, "Can't create a static provider")
+
+	b, err := p.Get("key").AsBytesBase64()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), b)
+
+	_, err = p.Get("broken").AsBytesBase64()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `for key "broken"`)
+
+	_, err = p.Get("int").AsBytesBase64()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't decode int as base64")
+
+	_, err = p.Get("missing").AsBytesBase64()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "value is missing")
+}
+
+func TestAsLocation(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewStaticProvider(map[string]interface{}{
+		"utc":     "UTC",
+		"unknown": "Nowhere/Special",
+		"int":     1,
+	})
+	require.NoError(t, err, "Can't create a static provider")
+
+	loc, err := p.Get("utc").AsLocation()
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, loc)
+
+	_, err = p.Get("unknown").AsLocation()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `for key "unknown"`)
+
+	_, err = p.Get("int").AsLocation()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't convert int to a time zone")
+
+	_, err = p.Get("missing").AsLocation()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "value is missing")
+}
+
+func TestAsEnum(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewStaticProvider(map[string]interface{}{"level": "info", "code": 1})
+	require.NoError(t, err, "Can't create a static provider")
+
+	level, err := p.Get("level").AsEnum("debug", "info")
+	require.NoError(t, err)
+	assert.Equal(t, "info", level)
+
+	code, err := p.Get("code").AsEnum("1", "2")
+	require.NoError(t, err)
+	assert.Equal(t, "1", code)
+
+	_, err = p.Get("level").AsEnum("warn", "error")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `for key "level": "info" is not one of: warn, error`)
+
+	_, err = p.Get("missing").AsEnum("a")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "value is missing")
+}
+
+func TestValueKeys(t *testing.T) {
+	t.Parallel()
+
+	src := "zeta: 1\nalpha: {c: 1, b: 2, a: 3}\nlist: [x, y]\n"
+	p, err := NewYAMLProviderFromBytes([]byte(src))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, []string{"alpha", "list", "zeta"}, p.Get(Root).Keys())
+		assert.Equal(t, []string{"a", "b", "c"}, p.Get("alpha").Keys())
+	}
+
+	assert.Equal(t, []string{"0", "1"}, p.Get("list").Keys())
+	assert.Nil(t, p.Get("zeta").Keys())
+	assert.Nil(t, p.Get("missing").Keys())
+
+	ordered, err := NewYAML(Source(strings.NewReader(src)), WithKeyOrder(), WithSeparator("/"))
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, []string{"zeta", "alpha", "list"}, ordered.Get(Root).Keys())
+	assert.Equal(t, []string{"c", "b", "a"}, ordered.Get("alpha").Keys())
+	assert.Equal(t, []string{"c", "b", "a"}, ordered.Get(Root).Get("alpha").Keys())
+}
+
+func TestValueGetRelative(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAML(Source(strings.NewReader(`
+servers:
+  - {name: a, tls: {port: 443}}
+  - {name: b, tls: {port: 8443}}
+`)), WithSeparator("/"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	servers := p.Get("servers")
+	for i, port := range []int{443, 8443} {
+		server := servers.Get(strconv.Itoa(i))
+		assert.Equal(t, port, server.Get("tls/port").Value())
+		assert.Equal(t, port, server.Get("tls").Get("port").Value())
+	}
+
+	assert.Equal(t, "b", servers.Get("-1").Get("name").Value())
+	assert.Equal(t, 8443, p.Get("servers[name=b]").Get("tls/port").Value())
+	assert.Equal(t, servers.Value(), servers.Get(Root).Value())
+}
+
+func TestAsStringList(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+csv: "a, b ,c"
+pipes: "x | y||z"
+single: 8080
+empty: ""
+list: [a, 1, true]
+nested: [[a]]
+map: {a: 1}
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	tests := []struct {
+		key  string
+		sep  string
+		want []string
+	}{
+		{key: "csv", want: []string{"a", "b", "c"}},
+		{key: "pipes", sep: "|", want: []string{"x", "y", "z"}},
+		{key: "single", want: []string{"8080"}},
+		{key: "empty", want: []string{}},
+		{key: "list", want: []string{"a", "1", "true"}},
+	}
+
+	for _, tt := range tests {
+		list, err := p.Get(tt.key).AsStringList(tt.sep)
+		require.NoError(t, err, "Unexpected error for %q", tt.key)
+		assert.Equal(t, tt.want, list, "Wrong list for %q", tt.key)
+	}
+
+	_, err = p.Get("nested").AsStringList("")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `for key "nested.0": expected a scalar`)
+
+	_, err = p.Get("map").AsStringList("")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't convert a mapping to a list")
+
+	_, err = p.Get("missing").AsStringList("")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "value is missing")
+}
+
+func TestTryAsInt64AndUint64(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+small: 42
+negative: -7
+maxInt64: 9223372036854775807
+maxUint64: 18446744073709551615
+overflow: 18446744073709551616
+float: 1024.0
+fraction: 1.5
+string: " 9007199254740993 "
+text: many
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	tests := []struct {
+		key   string
+		i64   int64
+		i64OK bool
+		u64   uint64
+		u64OK bool
+	}{
+		{key: "small", i64: 42, i64OK: true, u64: 42, u64OK: true},
+		{key: "negative", i64: -7, i64OK: true},
+		{key: "maxInt64", i64: math.MaxInt64, i64OK: true, u64: math.MaxInt64, u64OK: true},
+		{key: "maxUint64", u64: math.MaxUint64, u64OK: true},
+		{key: "overflow"},
+		{key: "float", i64: 1024, i64OK: true, u64: 1024, u64OK: true},
+		{key: "fraction"},
+		{key: "string", i64: 9007199254740993, i64OK: true, u64: 9007199254740993, u64OK: true},
+		{key: "text"},
+		{key: "missing"},
+	}
+
+	for _, tt := range tests {
+		i64, ok := p.Get(tt.key).TryAsInt64()
+		assert.Equal(t, tt.i64OK, ok, "Unexpected TryAsInt64 result for %q", tt.key)
+		assert.Equal(t, tt.i64, i64, "Wrong int64 for %q", tt.key)
+
+		u64, ok := p.Get(tt.key).TryAsUint64()
+		assert.Equal(t, tt.u64OK, ok, "Unexpected TryAsUint64 result for %q", tt.key)
+		assert.Equal(t, tt.u64, u64, "Wrong uint64 for %q", tt.key)
+	}
+}
+
+func TestAsTemplate(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewStaticProvider(map[string]interface{}{
+		"greeting": "Hello, {{upper .}}!",
+		"broken":   "{{.",
+		"code":     1,
+	})
+	require.NoError(t, err, "Can't create a static provider")
+
+	tmpl, err := p.Get("greeting").AsTemplate(template.FuncMap{"upper": strings.ToUpper})
+	require.NoError(t, err)
+	assert.Equal(t, "greeting", tmpl.Name())
+
+	var buf bytes.Buffer
+	require.NoError(t, tmpl.Execute(&buf, "gopher"))
+	assert.Equal(t, "Hello, GOPHER!", buf.String())
+
+	_, err = p.Get("greeting").AsTemplate(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `for key "greeting"`)
+
+	_, err = p.Get("broken").AsTemplate(nil)
+	assert.Error(t, err)
+
+	_, err = p.Get("code").AsTemplate(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't convert int to a template")
+
+	_, err = p.Get("missing").AsTemplate(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "value is missing")
+}
+
+func TestAsPath(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestAsPath")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "etc"), os.ModePerm))
+	base := filepath.Join(dir, "etc", "base.yaml")
+	require.NoError(t, ioutil.WriteFile(base, []byte(`
+tls:
+  cert: certs/a.pem
+  key: /abs/a.key
+  ca: certs/ca.pem
+port: 80
+`), os.ModePerm))
+
+	local := filepath.Join(dir, "local.yaml")
+	require.NoError(t, ioutil.WriteFile(local, []byte("tls:\n  ca: local/ca.pem"), os.ModePerm))
+
+	lookup := func(string) (string, bool) { return "", false }
+	for _, expand := range []bool{false, true} {
+		options := []YAMLOption{File(base, local)}
+		if expand {
+			options = append(options, WithExpand(lookup))
+		}
+
+		p, err := NewYAML(options...)
+		require.NoError(t, err, "Can't create a YAML provider")
+
+		path, err := p.Get("tls.cert").AsPath()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "etc", "certs", "a.pem"), path)
+
+		path, err = p.Get("tls").Get("ca").AsPath()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "local", "ca.pem"), path, "Overrides come from the later file")
+
+		path, err = p.Get("tls.key").AsPath()
+		require.NoError(t, err)
+		assert.Equal(t, "/abs/a.key", path)
+	}
+
+	p, err := NewYAMLProviderFromFiles(base)
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	_, err = p.Get("port").AsPath()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't convert int to a path")
+
+	_, err = p.Get("missing").AsPath()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "value is missing")
+
+	b, err := NewYAMLProviderFromBytes([]byte("cert: certs/a.pem"))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	path, err := b.Get("cert").AsPath()
+	require.NoError(t, err)
+	assert.Equal(t, "certs/a.pem", path, "Paths without files stay relative")
+}
+
+func TestAsPathDerived(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestAsPathDerived")
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, os.RemoveAll(dir)) }()
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "etc"), os.ModePerm))
+	main := filepath.Join(dir, "main.yaml")
+	require.NoError(t, ioutil.WriteFile(main, []byte("_include: etc/tls.yaml"), os.ModePerm))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "etc", "tls.yaml"), []byte(`
+tls:
+  cert: certs/a.pem
+  certs: [certs/b.pem]
+  port: "443"
+`), os.ModePerm))
+
+	included, err := NewYAMLProviderFromFilesWithIncludes(main)
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSnapshot(&buf, included, "v1"))
+	snapshot, err := NewProviderFromSnapshot(&buf)
+	require.NoError(t, err, "Can't create a provider from the snapshot")
+
+	derive := map[string]func(Provider) (Provider, error){
+		"references": NewProviderWithReferences,
+		"extends":    NewProviderWithExtends,
+		"conditions": func(p Provider) (Provider, error) { return NewProviderWithConditions(p, nil) },
+		"schema": func(p Provider) (Provider, error) {
+			return NewProviderWithSchema(p, "tls", struct{ Port int }{})
+		},
+		"decryption": func(p Provider) (Provider, error) { return NewProviderWithDecryption(p, failingCipher{}) },
+		"snapshot":   func(Provider) (Provider, error) { return snapshot, nil },
+	}
+
+	expected := filepath.Join(dir, "etc", "certs", "a.pem")
+	for name, f := range derive {
+		p, err := f(included)
+		require.NoError(t, err, "Can't derive a provider with %s", name)
+
+		path, err := p.Get("tls.cert").AsPath()
+		require.NoError(t, err)
+		assert.Equal(t, expected, path, "Unexpected path with %s", name)
+
+		path, err = p.Get("tls.certs.0").AsPath()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "etc", "certs", "b.pem"), path, "Unexpected path with %s", name)
+	}
+}
+
+func TestIsNull(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+disabled: ~
+empty: ""
+nested:
+  value: null
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	tests := []struct {
+		key      string
+		hasValue bool
+		isNull   bool
+	}{
+		{"disabled", true, true},
+		{"nested.value", true, true},
+		{"empty", true, false},
+		{"nested", true, false},
+		{"missing", false, false},
+	}
+
+	for _, tt := range tests {
+		v := p.Get(tt.key)
+		assert.Equal(t, tt.hasValue, v.HasValue(), "Unexpected HasValue for %q", tt.key)
+		assert.Equal(t, tt.isNull, v.IsNull(), "Unexpected IsNull for %q", tt.key)
+	}
+
+	assert.True(t, p.Get("nested").Get("value").IsNull())
+	assert.False(t, Value{}.IsNull())
+}
@@ -24,11 +24,14 @@ import (
 	"bytes"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/text/transform"
@@ -36,7 +39,16 @@ import (
 )
 
 type yamlConfigProvider struct {
-	root yamlNode
+	root         yamlNode
+	keySeparator string
+	loadedAt     time.Time
+
+	// Order of keys of mappings, nil if it isn't tracked.
+	order *keyOrder
+
+	// Tree of absolute names of files values come from, with the same shape
+	// as the root.
+	origins yamlNode
 }
 
 var (
@@ -44,33 +56,440 @@ var (
 	_emptyDefault = `""`
 )
 
-func newYAMLProviderCore(files ...io.Reader) (*yamlConfigProvider, error) {
-	var root interface{}
-	for _, v := range files {
-		var curr interface{}
-		if err := unmarshalYAMLValue(v, &curr); err != nil {
-			if file, ok := v.(*os.File); ok {
-				return nil, errors.Wrapf(err, "in file: %q", file.Name())
+// yamlSettings describe how YAML sources are turned into a provider.
+type yamlSettings struct {
+	// Expand values with the mapping function, see NewYAMLProviderWithExpand.
+	expand  bool
+	mapping func(string) (string, bool, error)
+
+	// Key separator, dot is used if it is empty.
+	separator string
+
+	// Fail on duplicate keys in mappings.
+	strict bool
+
+	// Custom unmarshal function, yaml.Unmarshal is used if it is nil.
+	unmarshal func([]byte, interface{}) error
+
+	// Logger for loaded sources, messages are discarded if it is nil.
+	logger Logger
+
+	// Sink of structured events, events aren't collected if it is nil.
+	events eventSink
+
+	// Limits of sources, see WithLimits.
+	limits Limits
+
+	// Functions transforming parsed sources before they are merged.
+	transforms []func(interface{}) (interface{}, error)
+
+	// Migrations of deprecated keys applied after the transforms.
+	migrations []Migration
+
+	// Track the order of keys in mappings, see WithKeyOrder.
+	keyOrder bool
+
+	// Number of sources parsed concurrently, see WithParallelLoad.
+	parallel int
+
+	// Report of loading the sources, it isn't filled in if it is nil.
+	report *LoadReport
+
+	// Trace of merge decisions, they aren't recorded if it is nil.
+	trace *MergeTrace
+
+	// Names of sources with include directives, see Includes.
+	included map[string]bool
+}
+
+// newYAMLProvider creates a cached provider from the readers with the settings.
+func newYAMLProvider(s yamlSettings, readers ...io.Reader) (Provider, error) {
+	start := time.Now()
+	var counters []*countingReader
+	if s.report != nil {
+		*s.report = LoadReport{Sources: make([]SourceReport, len(readers))}
+		readers, counters = countReaders(readers)
+	}
+
+	readers = limitReaders(readers, s.limits.MaxFileSize, "size of the source")
+	transformers := make([]*expandTransformer, len(readers))
+	if s.expand {
+		expandFunc := replace(s.mapping)
+		ereaders := make([]io.Reader, len(readers))
+		for i, reader := range readers {
+			transformers[i] = &expandTransformer{expand: expandFunc}
+			ereaders[i] = transform.NewReader(reader, transformers[i])
+
+			// Keep names of files for errors and metadata.
+			if name, disk := readerName(reader); name != "" {
+				ereaders[i] = namedReader{Reader: ereaders[i], name: name, disk: disk}
 			}
+		}
 
-			return nil, err
+		readers = limitReaders(ereaders, s.limits.MaxExpandedSize, "expanded size of the source")
+	}
+
+	p, err := newYAMLProviderCore(s, readers...)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.report != nil {
+		for i := range s.report.Sources {
+			s.report.Sources[i].Bytes = counters[i].n
+			if transformers[i] != nil {
+				s.report.Sources[i].Expansions = transformers[i].expansions
+			}
+		}
+
+		s.report.Duration = time.Since(start)
+	}
+
+	if s.separator != "" {
+		p.keySeparator = s.separator
+	}
+
+	return newCachedProvider(p)
+}
+
+func newYAMLProviderCore(s yamlSettings, files ...io.Reader) (*yamlConfigProvider, error) {
+	unmarshal := yaml.Unmarshal
+	if s.strict {
+		unmarshal = unmarshalStrict
+	}
+
+	if s.unmarshal != nil {
+		unmarshal = withInterfaceKeys(s.unmarshal)
+	}
+
+	logger := loggerOrNop(s.logger)
+	sources, err := parseYAMLSources(s, unmarshal, logger, files)
+	if err != nil {
+		return nil, err
+	}
+
+	var root, origins interface{}
+	var order *keyOrder
+	if s.keyOrder {
+		order = newKeyOrder()
+	}
+
+	var tracer *mergeTracer
+	if s.trace != nil {
+		tracer = newMergeTracer(s.trace, separatorOrDefault(s.separator))
+	}
+
+	tracked := false
+	for i, src := range sources {
+		if order != nil {
+			order.addDocuments(src.raw)
+		}
+
+		if src.name != "" {
+			logger.Debugf("config: loaded YAML source %d from %q", i, src.name)
+		} else {
+			logger.Debugf("config: loaded YAML source %d", i)
 		}
 
-		tmp, err := mergeMaps(root, curr)
+		if s.events != nil {
+			emitLoadEvents(s, i, src.name, root, src.value)
+		}
+
+		if s.report != nil {
+			keys := make(map[string]interface{})
+			flatten(keys, Root, src.value, separatorOrDefault(s.separator))
+			s.report.Sources[i].File = src.name
+			s.report.Sources[i].Keys = len(keys)
+			s.report.Sources[i].Duration = src.duration
+		}
+
+		if tracer != nil {
+			tracer.add(root, src.value, src.name)
+		}
+
+		tmp, err := mergeMaps(root, src.value)
 		if err != nil {
 			return nil, err
 		}
 
 		root = tmp
+
+		// Track files values come from, once there are files on disk.
+		if !src.disk && !tracked {
+			continue
+		}
+
+		file := ""
+		if src.disk {
+			if file, err = filepath.Abs(src.name); err != nil {
+				return nil, err
+			}
+		}
+
+		tracked = true
+		if origins, err = mergeMaps(origins, originsOf(src.value, file)); err != nil {
+			return nil, err
+		}
+	}
+
+	p := newYAMLProviderFromValue(root)
+	p.setOrigins(origins)
+	p.order = order
+	return p, nil
+}
+
+// A parsedSource is a YAML source parsed before it is merged.
+type parsedSource struct {
+	name  string
+	disk  bool
+	value interface{}
+
+	// raw is the content of the source, if the order of keys is kept.
+	raw []byte
+
+	// Time of reading and parsing the source.
+	duration time.Duration
+}
+
+// parseYAMLSources parses the sources, concurrently with the parallel
+// option, and returns them in the original order. If several sources fail,
+// the error of the first one is returned.
+func parseYAMLSources(s yamlSettings, unmarshal func([]byte, interface{}) error, logger Logger, files []io.Reader) ([]parsedSource, error) {
+	sources := make([]parsedSource, len(files))
+	errs := make([]error, len(files))
+	if s.parallel <= 1 || len(files) <= 1 {
+		for i, v := range files {
+			var err error
+			if sources[i], err = parseYAMLSource(s, unmarshal, logger, v); err != nil {
+				return nil, err
+			}
+		}
+
+		return sources, nil
 	}
 
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.parallel)
+	for i, v := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, v io.Reader) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			sources[i], errs[i] = parseYAMLSource(s, unmarshal, logger, v)
+		}(i, v)
+	}
+
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return sources, nil
+}
+
+// parseYAMLSource reads, checks, transforms and migrates a source.
+func parseYAMLSource(s yamlSettings, unmarshal func([]byte, interface{}) error, logger Logger, v io.Reader) (parsedSource, error) {
+	start := time.Now()
+	src := parsedSource{}
+	src.name, src.disk = readerName(v)
+	var raw bytes.Buffer
+	if s.keyOrder {
+		v = io.TeeReader(v, &raw)
+	}
+
+	err := unmarshalYAMLValueWith(unmarshal, v, &src.value)
+	if err == nil && s.included[src.name] {
+		withoutIncludes(src.value)
+	}
+
+	if err == nil {
+		err = s.limits.check(src.value)
+	}
+
+	for _, transform := range s.transforms {
+		if err != nil {
+			break
+		}
+
+		src.value, err = transform(src.value)
+	}
+
+	if err == nil && len(s.migrations) > 0 {
+		err = migrate(src.value, s.migrations, separatorOrDefault(s.separator), src.name, logger)
+	}
+
+	if err != nil {
+		if src.name != "" {
+			return src, errors.Wrapf(err, "in file: %q", src.name)
+		}
+
+		return src, err
+	}
+
+	src.raw = raw.Bytes()
+	src.duration = time.Since(start)
+	return src, nil
+}
+
+// emitLoadEvents emits events for a loaded source and scalars it overrides.
+func emitLoadEvents(s yamlSettings, i int, name string, root, curr interface{}) {
+	sep := separatorOrDefault(s.separator)
+
+	prev, next := map[string]interface{}{}, map[string]interface{}{}
+	flatten(prev, Root, root, sep)
+	flatten(next, Root, curr, sep)
+	keys := make([]string, 0, len(next))
+	for key := range next {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		if old, ok := prev[key]; ok && !reflect.DeepEqual(old, next[key]) {
+			s.events.debug("config value overridden", "key", key, "source", i, "file", name)
+		}
+	}
+
+	s.events.info("config source loaded", "source", i, "file", name, "keys", len(keys))
+}
+
+// separatorOrDefault returns the separator, or a dot if it is empty.
+func separatorOrDefault(sep string) string {
+	if sep == "" {
+		return _separator
+	}
+
+	return sep
+}
+
+// namedReader is a reader that knows a name of the file it reads for error
+// messages.
+type namedReader struct {
+	io.Reader
+
+	name string
+
+	// The name is a name of a file on disk, rather than e.g. an archive member.
+	disk bool
+}
+
+// readerName returns a name of the file a reader reads, if it is known, and
+// whether it is a file on disk.
+func readerName(r io.Reader) (string, bool) {
+	switch f := r.(type) {
+	case *os.File:
+		return f.Name(), true
+	case decompressedFile:
+		return f.file.Name(), true
+	case namedReader:
+		return f.name, f.disk
+	}
+
+	return "", false
+}
+
+// originsOf returns a tree of the same shape as the value with the file name
+// in place of every scalar, so merging it in the same order as values
+// tracks files values come from.
+func originsOf(value interface{}, file string) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[interface{}]interface{}:
+		res := make(map[interface{}]interface{}, len(v))
+		for key, val := range v {
+			res[key] = originsOf(val, file)
+		}
+
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, val := range v {
+			res[i] = originsOf(val, file)
+		}
+
+		return res
+	}
+
+	return file
+}
+
+// newYAMLProviderFromValue creates a provider from an already unmarshaled
+// and merged YAML tree.
+func newYAMLProviderFromValue(root interface{}) *yamlConfigProvider {
 	return &yamlConfigProvider{
 		root: yamlNode{
 			nodeType: getNodeType(root),
 			key:      Root,
 			value:    root,
 		},
-	}, nil
+		keySeparator: _separator,
+		loadedAt:     time.Now(),
+	}
+}
+
+// newDerivedProvider creates a cached provider from a tree derived from
+// values of the provider, e.g. with resolved references. It keeps the key
+// separator of the provider and the files of scalars at the same keys.
+func newDerivedProvider(p Provider, root interface{}) (Provider, error) {
+	sep := separatorOf(p)
+	y := newYAMLProviderFromValue(root)
+	y.keySeparator = sep
+	y.setOrigins(originsFrom(p, Root, root, sep))
+	return newCachedProvider(y)
+}
+
+// setOrigins sets a tree of files values come from, see originsOf.
+func (y *yamlConfigProvider) setOrigins(origins interface{}) {
+	y.origins = yamlNode{nodeType: getNodeType(origins), key: Root, value: origins}
+}
+
+// originsFrom returns a tree of the same shape as the value with files, that
+// scalars at the same keys of the provider come from, like originsOf does.
+// Subtrees without known files are nil.
+func originsFrom(p Provider, key string, value interface{}, sep string) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[interface{}]interface{}:
+		res := make(map[interface{}]interface{})
+		for k, child := range v {
+			if o := originsFrom(p, joinKey(key, escapeSeparators(fmt.Sprint(k), sep), sep), child, sep); o != nil {
+				res[k] = o
+			}
+		}
+
+		if len(res) == 0 {
+			return nil
+		}
+
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		found := false
+		for i, child := range v {
+			res[i] = originsFrom(p, joinKey(key, strconv.Itoa(i), sep), child, sep)
+			found = found || res[i] != nil
+		}
+
+		if !found {
+			return nil
+		}
+
+		return res
+	}
+
+	if file := metadataOf(p, key).File; file != "" {
+		return file
+	}
+
+	return nil
 }
 
 // We need to have a custom merge map because yamlV2 doesn't unmarshal
@@ -134,26 +553,7 @@ func mergeMaps(dst interface{}, src interface{}) (interface{}, error) {
 // file names. All the objects are going to be merged and arrays/values
 // overridden in the order of the files.
 func NewYAMLProviderFromFiles(files ...string) (Provider, error) {
-	readClosers, err := filesToReaders(files...)
-	if err != nil {
-		return nil, err
-	}
-
-	readers := make([]io.Reader, len(readClosers))
-	for i, r := range readClosers {
-		readers[i] = r
-	}
-
-	provider, err := NewYAMLProviderFromReader(readers...)
-
-	for _, r := range readClosers {
-		nerr := r.Close()
-		if err == nil {
-			err = nerr
-		}
-	}
-
-	return provider, err
+	return NewYAML(File(files...))
 }
 
 // NewYAMLProviderWithExpand creates a configuration provider from a set of YAML
@@ -171,38 +571,13 @@ func NewYAMLProviderFromFiles(files ...string) (Provider, error) {
 // be replaced by a literal '$'.  All other sequences will be ignored
 // for expansion purposes.
 func NewYAMLProviderWithExpand(mapping func(string) (string, bool), files ...string) (Provider, error) {
-	readClosers, err := filesToReaders(files...)
-	if err != nil {
-		return nil, err
-	}
-
-	readers := make([]io.Reader, len(readClosers))
-	for i, r := range readClosers {
-		readers[i] = r
-	}
-
-	provider, err := NewYAMLProviderFromReaderWithExpand(mapping,
-		readers...)
-
-	for _, r := range readClosers {
-		nerr := r.Close()
-		if err == nil {
-			err = nerr
-		}
-	}
-
-	return provider, err
+	return NewYAML(WithExpand(mapping), File(files...))
 }
 
 // NewYAMLProviderFromReader creates a configuration provider from a list of io.Readers.
 // As above, all the objects are going to be merged and arrays/values overridden in the order of the files.
 func NewYAMLProviderFromReader(readers ...io.Reader) (Provider, error) {
-	p, err := newYAMLProviderCore(readers...)
-	if err != nil {
-		return nil, err
-	}
-
-	return newCachedProvider(p)
+	return NewYAML(Source(readers...))
 }
 
 // NewYAMLProviderFromReaderWithExpand creates a configuration provider from
@@ -212,16 +587,22 @@ func NewYAMLProviderFromReaderWithExpand(
 	mapping func(string) (string, bool),
 	readers ...io.Reader) (Provider, error) {
 
-	expandFunc := replace(mapping)
+	return NewYAML(WithExpand(mapping), Source(readers...))
+}
 
-	ereaders := make([]io.Reader, len(readers))
-	for i, reader := range readers {
-		ereaders[i] = transform.NewReader(
-			reader,
-			&expandTransformer{expand: expandFunc})
-	}
+// NewYAMLProviderFromReaderWithSeparator creates a configuration provider from
+// a list of io.Readers, that uses a custom separator instead of a dot to split
+// keys into path segments, e.g. with a "/" separator a value for
+// "hosts/my.host.com/port" can be accessed, even if "my.host.com" is a map key.
+func NewYAMLProviderFromReaderWithSeparator(separator string, readers ...io.Reader) (Provider, error) {
+	return NewYAML(WithSeparator(separator), Source(readers...))
+}
 
-	return NewYAMLProviderFromReader(ereaders...)
+// NewYAMLProviderFromFilesWithSeparator creates a configuration provider from
+// a set of YAML file names, that uses a custom key separator. See
+// NewYAMLProviderFromReaderWithSeparator for details.
+func NewYAMLProviderFromFilesWithSeparator(separator string, files ...string) (Provider, error) {
+	return NewYAML(WithSeparator(separator), File(files...))
 }
 
 // NewYAMLProviderFromBytes creates a config provider from a byte-backed YAML
@@ -241,7 +622,7 @@ func filesToReaders(files ...string) ([]io.ReadCloser, error) {
 	readers := []io.ReadCloser{}
 
 	for _, v := range files {
-		if reader, err := os.Open(v); err != nil {
+		if reader, err := openFile(v); err != nil {
 			for _, r := range readers {
 				r.Close()
 			}
@@ -255,11 +636,111 @@ func filesToReaders(files ...string) ([]io.ReadCloser, error) {
 }
 
 func (y yamlConfigProvider) getNode(key string) *yamlNode {
+	return y.findNode(&y.root, key)
+}
+
+// file returns an absolute name of the file a scalar value comes from, if it
+// is known.
+func (y yamlConfigProvider) file(key string) string {
+	if node := y.findNode(&y.origins, key); node != nil {
+		if file, ok := node.value.(string); ok {
+			return file
+		}
+	}
+
+	return ""
+}
+
+func (y yamlConfigProvider) findNode(root *yamlNode, key string) *yamlNode {
 	if key == Root {
-		return &y.root
+		return root
+	}
+
+	if node := root.Find(key, y.keySeparator); node != nil {
+		return node
+	}
+
+	// Keys may use brackets for array indexes, e.g. servers[0].host, or
+	// selectors, e.g. endpoints[name=payments].url, try them only if there
+	// is no literal match.
+	if strings.IndexByte(key, '[') >= 0 {
+		if key = y.resolveSelectors(root, key); key == "" {
+			return nil
+		}
+
+		return root.Find(normalizeIndexes(key, y.keySeparator), y.keySeparator)
+	}
+
+	return nil
+}
+
+// resolveSelectors replaces [field=value] selectors in the key with indexes of
+// the first array elements with the field values, or returns an empty key
+// if there are no such elements.
+func (y yamlConfigProvider) resolveSelectors(root *yamlNode, key string) string {
+	for {
+		start, end, field, value := nextSelector(key)
+		if start < 0 {
+			return key
+		}
+
+		prefix := strings.TrimSuffix(normalizeIndexes(key[:start], y.keySeparator), y.keySeparator)
+		node := root
+		if prefix != "" {
+			if node = root.Find(prefix, y.keySeparator); node == nil {
+				return ""
+			}
+		}
+
+		if node.nodeType != arrayNode {
+			return ""
+		}
+
+		index := -1
+		for i, child := range node.Children() {
+			if m, ok := child.value.(map[interface{}]interface{}); ok && matchesSelector(m, field, value) {
+				index = i
+				break
+			}
+		}
+
+		if index < 0 {
+			return ""
+		}
+
+		key = joinKey(prefix, strconv.Itoa(index), y.keySeparator) + key[end+1:]
+	}
+}
+
+// nextSelector returns the boundaries of the first [field=value] selector in
+// the key, or -1 if there is none.
+func nextSelector(key string) (start int, end int, field string, value string) {
+	for i := 0; i < len(key); i++ {
+		if key[i] != '[' {
+			continue
+		}
+
+		j := strings.IndexByte(key[i:], ']')
+		if j < 0 {
+			break
+		}
+
+		if eq := strings.IndexByte(key[i:i+j], '='); eq > 1 {
+			return i, i + j, key[i+1 : i+eq], key[i+eq+1 : i+j]
+		}
 	}
 
-	return y.root.Find(key)
+	return -1, -1, "", ""
+}
+
+func matchesSelector(m map[interface{}]interface{}, field string, value string) bool {
+	for k, v := range m {
+		if strings.EqualFold(fmt.Sprint(k), field) && v != nil && fmt.Sprint(v) == value {
+			return true
+		}
+	}
+
+	return false
 }
 
 // Name returns the config provider name.
@@ -267,16 +748,49 @@ func (y yamlConfigProvider) Name() string {
 	return "yaml"
 }
 
+func (y yamlConfigProvider) keyOrder() *keyOrder {
+	return y.order
+}
+
+func (y yamlConfigProvider) separator() string {
+	return y.keySeparator
+}
+
 // Get returns a configuration value by name
 func (y yamlConfigProvider) Get(key string) Value {
 	node := y.getNode(key)
 	if node == nil {
-		return NewValue(y, key, nil, false)
+		v := NewValue(y, key, nil, false)
+		v.missing = y.indexError(key)
+		return v
 	}
 
 	return NewValue(y, key, node.value, true)
 }
 
+// indexError returns an error if the key is missing because of an out of
+// range array index, or nil otherwise.
+func (y yamlConfigProvider) indexError(key string) error {
+	if key == Root {
+		return nil
+	}
+
+	path := key
+	if strings.IndexByte(path, '[') >= 0 {
+		if path = y.resolveSelectors(&y.root, path); path == "" {
+			return nil
+		}
+
+		path = normalizeIndexes(path, y.keySeparator)
+	}
+
+	if err := y.root.outOfRange(path, y.keySeparator); err != nil {
+		return errorWithKey(err, key)
+	}
+
+	return nil
+}
+
 // nodeType is a simple YAML reader.
 type nodeType int
 
@@ -301,22 +815,30 @@ func (n yamlNode) Type() reflect.Type {
 	return reflect.TypeOf(n.value)
 }
 
-// Find the first longest match in child nodes for the dottedPath.
-func (n *yamlNode) Find(dottedPath string) *yamlNode {
+// Find the first longest match in child nodes for the path,
+// split into segments by the separator. A separator preceded by a backslash
+// is a part of a segment, e.g. "hosts.my\.host\.com" has two segments:
+// "hosts" and "my.host.com".
+func (n *yamlNode) Find(dottedPath string, separator string) *yamlNode {
+	if n.nodeType == arrayNode {
+		dottedPath = n.resolveNegativeIndex(dottedPath, separator)
+	}
+
 	for curr := dottedPath; len(curr) != 0; {
+		key := unescapeSeparators(curr, separator)
 		for _, v := range n.Children() {
-			if strings.EqualFold(v.key, curr) {
+			if strings.EqualFold(v.key, key) {
 				if curr == dottedPath {
 					return v
 				}
 
-				if node := v.Find(dottedPath[len(curr)+1:]); node != nil {
+				if node := v.Find(dottedPath[len(curr)+len(separator):], separator); node != nil {
 					return node
 				}
 			}
 		}
 
-		if last := strings.LastIndex(curr, _separator); last > 0 {
+		if last := lastSeparatorIndex(curr, separator); last > 0 {
 			curr = curr[:last]
 		} else {
 			break
@@ -326,7 +848,114 @@ func (n *yamlNode) Find(dottedPath string) *yamlNode {
 	return nil
 }
 
-// Children returns a slice containing this node's child nodes.
+// outOfRange returns an error if the path isn't found because an index in it
+// is out of range of the array, or nil otherwise. It follows the same
+// matches as Find.
+func (n *yamlNode) outOfRange(path string, separator string) error {
+	if n.nodeType == arrayNode {
+		segment := path
+		if i := strings.Index(path, separator); i >= 0 {
+			segment = path[:i]
+		}
+
+		if index, err := strconv.Atoi(segment); err == nil {
+			if size := len(n.value.([]interface{})); index >= size || index < -size {
+				return fmt.Errorf("index %d out of range for array of length %d", index, size)
+			}
+		}
+
+		path = n.resolveNegativeIndex(path, separator)
+	}
+
+	for curr := path; len(curr) != 0; {
+		key := unescapeSeparators(curr, separator)
+		for _, v := range n.Children() {
+			if curr != path && strings.EqualFold(v.key, key) {
+				if err := v.outOfRange(path[len(curr)+len(separator):], separator); err != nil {
+					return err
+				}
+			}
+		}
+
+		if last := lastSeparatorIndex(curr, separator); last > 0 {
+			curr = curr[:last]
+		} else {
+			break
+		}
+	}
+
+	return nil
+}
+
+// resolveNegativeIndex replaces a negative index in the first segment of the
+// path with the corresponding index counted from the end of the array,
+// e.g. -1 is the last element.
+func (n *yamlNode) resolveNegativeIndex(path string, separator string) string {
+	segment, rest := path, ""
+	if i := strings.Index(path, separator); i >= 0 {
+		segment, rest = path[:i], path[i:]
+	}
+
+	index, err := strconv.Atoi(segment)
+	if err != nil || index >= 0 {
+		return path
+	}
+
+	if index += len(n.value.([]interface{})); index < 0 {
+		// Out of range indexes are not found, see outOfRange.
+		return path
+	}
+
+	return strconv.Itoa(index) + rest
+}
+
+// normalizeIndexes rewrites array indexes in brackets as path segments,
+// e.g. "servers[0].host" becomes "servers.0.host" and "matrix[1][-1]"
+// becomes "matrix.1.-1".
+func normalizeIndexes(path string, separator string) string {
+	buf := &bytes.Buffer{}
+	for i := 0; i < len(path); i++ {
+		if path[i] == '[' {
+			if end := strings.IndexByte(path[i:], ']'); end > 0 {
+				index := path[i+1 : i+end]
+				if _, err := strconv.Atoi(index); err == nil {
+					if buf.Len() > 0 && !strings.HasSuffix(buf.String(), separator) {
+						buf.WriteString(separator)
+					}
+
+					buf.WriteString(index)
+					i += end
+					continue
+				}
+			}
+		}
+
+		buf.WriteByte(path[i])
+	}
+
+	return buf.String()
+}
+
+// lastSeparatorIndex returns the index of the last separator in the path,
+// that is not escaped with a backslash, or -1 if there is none.
+func lastSeparatorIndex(path string, separator string) int {
+	for last := strings.LastIndex(path, separator); last > 0; last = strings.LastIndex(path[:last], separator) {
+		if path[last-1] != _escape {
+			return last
+		}
+	}
+
+	return -1
+}
+
+// unescapeSeparators removes backslashes in front of separators.
+func unescapeSeparators(path string, separator string) string {
+	return strings.Replace(path, string(_escape)+separator, separator, -1)
+}
+
+// Children returns a slice containing this node's child nodes. Children of
+// mappings are sorted by keys, so lookups matching several keys, e.g. "a"
+// and "A", are deterministic.
 func (n *yamlNode) Children() []*yamlNode {
 	if n.children == nil {
 		n.children = []*yamlNode{}
@@ -343,6 +972,8 @@ func (n *yamlNode) Children() []*yamlNode {
 
 				n.children = append(n.children, n2)
 			}
+
+			sort.Sort(nodesByKey(n.children))
 		case arrayNode:
 			for k, v := range n.value.([]interface{}) {
 				n2 := &yamlNode{
@@ -361,13 +992,136 @@ func (n *yamlNode) Children() []*yamlNode {
 	return nodes
 }
 
+// nodesByKey sorts nodes by their keys.
+type nodesByKey []*yamlNode
+
+func (n nodesByKey) Len() int           { return len(n) }
+func (n nodesByKey) Less(i, j int) bool { return n[i].key < n[j].key }
+func (n nodesByKey) Swap(i, j int)      { n[i], n[j] = n[j], n[i] }
+
 func unmarshalYAMLValue(reader io.Reader, value interface{}) error {
-	raw, err := ioutil.ReadAll(reader)
+	return unmarshalYAMLValueWith(yaml.Unmarshal, reader, value)
+}
+
+// unmarshalStrict unmarshals a YAML, that has no duplicate keys in mappings.
+func unmarshalStrict(in []byte, out interface{}) error {
+	if err := yaml.Unmarshal(in, &uniqueKeys{}); err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(in, out)
+}
+
+// uniqueKeys fails to unmarshal YAML mappings with duplicate keys.
+type uniqueKeys struct{}
+
+func (*uniqueKeys) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	// Sequences are tried first, because YAML decodes sequences of mappings
+	// into map slices too.
+	var s []uniqueKeys
+	err := unmarshal(&s)
+	if _, ok := err.(*yaml.TypeError); !ok {
+		return err
+	}
+
+	// Merged keys are not listed in map slices, so they can be overridden.
+	var m yaml.MapSlice
+	if err := unmarshal(&m); err != nil {
+		if _, ok := err.(*yaml.TypeError); ok {
+			// Scalar values have no keys.
+			return nil
+		}
+
+		return err
+	}
+
+	seen := make(map[interface{}]struct{}, len(m))
+	for _, item := range m {
+		if _, ok := seen[item.Key]; ok {
+			return fmt.Errorf("duplicate key %q", fmt.Sprint(item.Key))
+		}
+
+		seen[item.Key] = struct{}{}
+	}
+
+	var children map[interface{}]uniqueKeys
+	return unmarshal(&children)
+}
+
+// unmarshalYAMLValueWith reads a YAML from the reader and decodes it with
+// the unmarshal function, e.g. yaml.UnmarshalStrict.
+func unmarshalYAMLValueWith(unmarshal func([]byte, interface{}) error, reader io.Reader, value interface{}) error {
+	raw, err := readAll(reader)
 	if err != nil {
 		return errors.Wrap(err, "failed to read the yaml config")
 	}
 
-	return yaml.Unmarshal(raw, value)
+	docs := splitDocuments(raw)
+	out, ok := value.(*interface{})
+	if !ok || len(docs) < 2 {
+		return unmarshal(raw, value)
+	}
+
+	// Documents of a stream are merged in order, like separate files.
+	var root interface{}
+	for i, doc := range docs {
+		var curr interface{}
+		if err := unmarshal(doc, &curr); err != nil {
+			return errors.Wrapf(err, "in document %d", i)
+		}
+
+		if root, err = mergeMaps(root, curr); err != nil {
+			return errors.Wrapf(err, "in document %d", i)
+		}
+	}
+
+	*out = root
+	return nil
+}
+
+// splitDocuments splits a YAML stream into documents by the "---" and "..."
+// markers at the beginning of lines. Directives and comments preceding
+// a marker stay with the document that follows it.
+func splitDocuments(raw []byte) [][]byte {
+	var docs [][]byte
+	start, content := 0, false
+	for offset := 0; offset < len(raw); {
+		end := bytes.IndexByte(raw[offset:], '\n') + 1
+		if end == 0 {
+			end = len(raw) - offset
+		}
+
+		line := bytes.TrimRight(raw[offset:offset+end], "\r\n")
+		isStart := bytes.HasPrefix(line, []byte("---")) &&
+			(len(line) == 3 || line[3] == ' ' || line[3] == '\t')
+		isEnd := bytes.Equal(bytes.TrimRight(line, " \t"), []byte("..."))
+
+		switch {
+		case isEnd:
+			docs = append(docs, raw[start:offset])
+			start, content = offset+end, false
+		case isStart:
+			if content {
+				docs = append(docs, raw[start:offset])
+				start = offset
+			}
+
+			content = len(bytes.TrimSpace(line[3:])) > 0
+		default:
+			trimmed := bytes.TrimSpace(line)
+			if len(trimmed) > 0 && trimmed[0] != '#' && trimmed[0] != '%' {
+				content = true
+			}
+		}
+
+		offset += end
+	}
+
+	if start < len(raw) {
+		docs = append(docs, raw[start:])
+	}
+
+	return docs
 }
 
 // Function to expand environment variables in returned values that have form: ${ENV_VAR:DEFAULT_VALUE}.
@@ -380,12 +1134,24 @@ func unmarshalYAMLValue(reader io.Reader, value interface{}) error {
 //
 // In the case that HTTP_PORT is not provided, default value (in this case 8080)
 // will be used.
-func replace(lookUp func(string) (string, bool)) func(in string) (string, error) {
+func replace(lookUp func(string) (string, bool, error)) func(in string) (string, error) {
 	return func(in string) (string, error) {
 		sep := strings.Index(in, _envSeparator)
 		var key string
 		var def string
 
+		// References are resolved by NewProviderWithReferences later.
+		if strings.HasPrefix(in, _refPrefix) {
+			return "${" + in + "}", nil
+		}
+
+		// Keys of runtime facts contain the separator, e.g. ${runtime:pid:1}.
+		if strings.HasPrefix(in, _factPrefix) {
+			if sep = strings.Index(in[len(_factPrefix):], _envSeparator); sep != -1 {
+				sep += len(_factPrefix)
+			}
+		}
+
 		if sep == -1 {
 			// separator missing - everything is the key ${KEY}
 			key = in
@@ -395,7 +1161,12 @@ func replace(lookUp func(string) (string, bool)) func(in string) (string, error)
 			def = in[sep+1:]
 		}
 
-		if envVal, ok := lookUp(key); ok {
+		envVal, ok, err := lookUp(key)
+		if err != nil {
+			return "", errors.Wrapf(err, "can't look up %q", key)
+		}
+
+		if ok {
 			return envVal, nil
 		}
 
@@ -0,0 +1,385 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+)
+
+// A YAMLOption configures a provider created by NewYAML.
+type YAMLOption func(*yamlOptions)
+
+type yamlOptions struct {
+	settings yamlSettings
+	sources  []yamlSource
+	lazy     bool
+	err      error
+}
+
+// A yamlSource is either a file name, an archive name, a reader or an
+// opening function.
+type yamlSource struct {
+	file    string
+	archive string
+	reader  io.Reader
+
+	// Missing files are skipped, rather than failing to create a provider,
+	// quietly for conventional files, that are often missing.
+	optional bool
+	quiet    bool
+
+	// Include directives of the file are resolved.
+	includes bool
+
+	// Opens the source reading up to the limit of bytes up front, if it
+	// needs to. The file is its name, that is on disk if disk is set.
+	open func(limit int64) (io.ReadCloser, error)
+	disk bool
+}
+
+// File adds YAML files to read configuration from.
+func File(files ...string) YAMLOption {
+	return func(o *yamlOptions) {
+		for _, file := range files {
+			o.sources = append(o.sources, yamlSource{file: file})
+		}
+	}
+}
+
+// OptionalFile adds YAML files, that are skipped with a warning to the
+// logger if they don't exist, e.g. local overrides of a developer:
+//
+// 	p, err := config.NewYAML(
+// 		config.File("base.yaml"),
+// 		config.OptionalFile("local.yaml"),
+// 	)
+//
+// Other errors, e.g. of reading or parsing existing files, still fail to
+// create the provider.
+func OptionalFile(files ...string) YAMLOption {
+	return func(o *yamlOptions) {
+		for _, file := range files {
+			o.sources = append(o.sources, yamlSource{file: file, optional: true})
+		}
+	}
+}
+
+// Source adds readers with YAML to read configuration from.
+func Source(readers ...io.Reader) YAMLOption {
+	return func(o *yamlOptions) {
+		for _, r := range readers {
+			o.sources = append(o.sources, yamlSource{reader: r})
+		}
+	}
+}
+
+// WithExpand replaces ${var} and $var sequences in all the sources based on
+// the mapping function, see NewYAMLProviderWithExpand for details.
+func WithExpand(mapping func(string) (string, bool)) YAMLOption {
+	return func(o *yamlOptions) {
+		o.settings.expand = true
+		o.settings.mapping = withoutErrors(mapping)
+	}
+}
+
+// WithExpandContext is like WithExpand, but the mapping function receives
+// the context and can fail, e.g. to look up values in secret stores with
+// timeouts. Errors of the function and of the context fail to create the
+// provider instead of falling back to defaults.
+func WithExpandContext(
+	ctx context.Context,
+	mapping func(context.Context, string) (string, bool, error),
+) YAMLOption {
+	return func(o *yamlOptions) {
+		o.settings.expand = true
+		o.settings.mapping = func(key string) (string, bool, error) {
+			if err := ctx.Err(); err != nil {
+				return "", false, err
+			}
+
+			return mapping(ctx, key)
+		}
+	}
+}
+
+// WithSeparator uses a custom separator instead of a dot to split keys into
+// path segments, see NewYAMLProviderFromReaderWithSeparator for details.
+func WithSeparator(separator string) YAMLOption {
+	return func(o *yamlOptions) {
+		if separator == "" && o.err == nil {
+			o.err = errors.New("empty key separator")
+		}
+
+		o.settings.separator = separator
+	}
+}
+
+// WithStrict fails to create a provider if sources have duplicate keys in
+// mappings.
+func WithStrict() YAMLOption {
+	return func(o *yamlOptions) {
+		o.settings.strict = true
+	}
+}
+
+// WithUnmarshaler decodes sources with a custom unmarshal function instead
+// of the vendored yaml.v2 one, e.g. with yaml.v3 for better error messages:
+//
+// 	config.NewYAML(config.File("base.yaml"), config.WithUnmarshaler(yaml.Unmarshal))
+//
+// Maps with string keys produced by the function are converted to maps with
+// interface{} keys, which the providers use. The option can't be combined
+// with WithStrict, configure strict decoding of the function instead.
+func WithUnmarshaler(unmarshal func([]byte, interface{}) error) YAMLOption {
+	return func(o *yamlOptions) {
+		if unmarshal == nil && o.err == nil {
+			o.err = errors.New("received a nil unmarshal function")
+		}
+
+		o.settings.unmarshal = unmarshal
+	}
+}
+
+// WithTransform transforms every parsed source before it is merged, e.g. to
+// lowercase all the keys or to move values of a legacy layout to new keys.
+// Sources are trees of map[interface{}]interface{}, []interface{} and
+// scalars, that the function can modify in place. Transforms are applied
+// in the order of the options.
+func WithTransform(transform func(interface{}) (interface{}, error)) YAMLOption {
+	return func(o *yamlOptions) {
+		if transform == nil && o.err == nil {
+			o.err = errors.New("received a nil transform function")
+		}
+
+		o.settings.transforms = append(o.settings.transforms, transform)
+	}
+}
+
+// WithLogger logs sources the provider is loaded from to the logger.
+func WithLogger(logger Logger) YAMLOption {
+	return func(o *yamlOptions) {
+		o.settings.logger = logger
+	}
+}
+
+// WithParallelLoad reads and parses up to n sources concurrently, e.g. to
+// cut the startup time of services loading dozens of files. Sources are
+// still merged in their order and the error of the first failed source is
+// returned. Mapping functions, transforms and loggers of the provider must
+// be safe for concurrent use then.
+func WithParallelLoad(n int) YAMLOption {
+	return func(o *yamlOptions) {
+		if n <= 0 && o.err == nil {
+			o.err = errors.New("number of sources to load in parallel must be positive")
+		}
+
+		o.settings.parallel = n
+	}
+}
+
+// NewYAML creates a configuration provider from YAML sources, e.g.
+//
+// 	p, err := config.NewYAML(
+// 		config.File("base.yaml", "production.yaml"),
+// 		config.WithExpand(os.LookupEnv),
+// 	)
+//
+// All the objects are going to be merged and arrays/values overridden in
+// the order of the sources. Options apply to all the sources regardless of
+// their order.
+func NewYAML(options ...YAMLOption) (Provider, error) {
+	var o yamlOptions
+	for _, option := range options {
+		option(&o)
+	}
+
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+
+	return o.provider()
+}
+
+// check returns an error of invalid options or their combinations.
+func (o yamlOptions) check() error {
+	if o.err != nil {
+		return o.err
+	}
+
+	if o.settings.strict && o.settings.unmarshal != nil {
+		return errors.New("WithStrict can't be combined with WithUnmarshaler")
+	}
+
+	if o.settings.keyOrder && o.settings.unmarshal != nil {
+		return errors.New("WithKeyOrder can't be combined with WithUnmarshaler")
+	}
+
+	return nil
+}
+
+// provider creates a provider from the sources with the options.
+func (o yamlOptions) provider() (Provider, error) {
+	if o.lazy {
+		return &lazyProvider{settings: o.settings, sources: o.sources}, nil
+	}
+
+	return loadYAMLSources(o.settings, o.sources)
+}
+
+// loadYAMLSources opens all the files first and closes them after
+// the provider is created.
+func loadYAMLSources(s yamlSettings, sources []yamlSource) (Provider, error) {
+	readers := make([]io.Reader, 0, len(sources))
+	var closers []io.Closer
+	closeAll := func(err error) error {
+		for _, c := range closers {
+			if cerr := c.Close(); err == nil {
+				err = cerr
+			}
+		}
+
+		return err
+	}
+
+	for _, source := range sources {
+		if source.reader != nil {
+			readers = append(readers, source.reader)
+			continue
+		}
+
+		if source.open != nil {
+			rc, err := source.open(s.limits.MaxFileSize)
+			if err != nil {
+				closeAll(nil)
+				return nil, err
+			}
+
+			readers = append(readers, namedReader{Reader: rc, name: source.file, disk: source.disk})
+			closers = append(closers, rc)
+			continue
+		}
+
+		if source.includes {
+			files, err := resolveIncludes(source.file, nil, s.limits.MaxFileSize)
+			if err != nil {
+				closeAll(nil)
+				return nil, err
+			}
+
+			if s.included == nil {
+				s.included = make(map[string]bool)
+			}
+
+			for _, f := range files {
+				readers = append(readers, namedReader{Reader: newByteSource(f.content), name: f.name, disk: true})
+				s.included[f.name] = true
+			}
+
+			continue
+		}
+
+		if source.archive != "" {
+			members, err := readArchive(source.archive, s.limits.MaxFileSize)
+			if err != nil {
+				closeAll(nil)
+				return nil, err
+			}
+
+			readers = append(readers, members...)
+			continue
+		}
+
+		rcs, err := filesToReaders(source.file)
+		if err != nil && source.optional && os.IsNotExist(err) {
+			if !source.quiet {
+				loggerOrNop(s.logger).Warnf("config: optional file %q is skipped: %v", source.file, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			closeAll(nil)
+			return nil, err
+		}
+
+		readers = append(readers, rcs[0])
+		closers = append(closers, rcs[0])
+	}
+
+	p, err := newYAMLProvider(s, readers...)
+	if err = closeAll(err); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// withoutErrors adapts a mapping function that can't fail.
+func withoutErrors(mapping func(string) (string, bool)) func(string) (string, bool, error) {
+	return func(key string) (string, bool, error) {
+		v, ok := mapping(key)
+		return v, ok, nil
+	}
+}
+
+// withInterfaceKeys wraps an unmarshal function to convert maps with string
+// keys it produces to maps with interface{} keys.
+func withInterfaceKeys(unmarshal func([]byte, interface{}) error) func([]byte, interface{}) error {
+	return func(in []byte, out interface{}) error {
+		if err := unmarshal(in, out); err != nil {
+			return err
+		}
+
+		if v, ok := out.(*interface{}); ok {
+			*v = interfaceKeys(*v)
+		}
+
+		return nil
+	}
+}
+
+// interfaceKeys recursively converts maps with string keys to maps with
+// interface{} keys, it is the opposite of stringifyKeys.
+func interfaceKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		res := make(map[interface{}]interface{}, len(v))
+		for key, val := range v {
+			res[key] = interfaceKeys(val)
+		}
+
+		return res
+	case map[interface{}]interface{}:
+		for key, val := range v {
+			v[key] = interfaceKeys(val)
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = interfaceKeys(val)
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewYAML(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(key string) (string, bool) {
+		return "expanded", key == "VAR"
+	}
+
+	p, err := NewYAML(
+		File("./testdata/base.yaml"),
+		Source(bytes.NewBufferString("value: ${VAR}\nhosts:\n  my.host: 80")),
+		WithExpand(lookup),
+		WithSeparator("/"),
+	)
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	assert.Equal(t, "expanded", p.Get("value").Value())
+	assert.Equal(t, 80, p.Get("hosts/my.host").Value())
+	assert.Equal(t, "/", separatorOf(p))
+}
+
+func TestNewYAMLWithStrict(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewYAML(Source(bytes.NewBufferString("a: 1\na: 2")), WithStrict())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `duplicate key "a"`)
+}
+
+func TestNewYAMLWithUnmarshaler(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAML(
+		Source(bytes.NewBufferString(`{"a": {"b": [{"c": 1}]}, "d": "json"}`)),
+		WithUnmarshaler(json.Unmarshal),
+	)
+	require.NoError(t, err, "Can't create a provider")
+
+	assert.Equal(t, float64(1), p.Get("a.b.0.c").Value())
+
+	var s struct{ D string }
+	require.NoError(t, p.Get(Root).Populate(&s))
+	assert.Equal(t, "json", s.D)
+
+	_, err = NewYAML(
+		Source(bytes.NewBufferString("a: b")),
+		WithUnmarshaler(func([]byte, interface{}) error { return errors.New("can't unmarshal") }),
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't unmarshal")
+
+	_, err = NewYAML(WithUnmarshaler(nil))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nil unmarshal function")
+
+	_, err = NewYAML(WithUnmarshaler(json.Unmarshal), WithStrict())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't be combined")
+}
+
+func TestNewYAMLWithExpandContext(t *testing.T) {
+	t.Parallel()
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "secret")
+	lookup := func(ctx context.Context, key string) (string, bool, error) {
+		switch key {
+		case "SECRET":
+			return ctx.Value(ctxKey{}).(string), true, nil
+		case "BROKEN":
+			return "", false, errors.New("store is unavailable")
+		}
+
+		return "", false, nil
+	}
+
+	p, err := NewYAML(
+		Source(bytes.NewBufferString("a: ${SECRET}\nb: ${MISSING:default}")),
+		WithExpandContext(ctx, lookup),
+	)
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "secret", p.Get("a").Value())
+	assert.Equal(t, "default", p.Get("b").Value())
+
+	_, err = NewYAML(
+		Source(bytes.NewBufferString("a: ${BROKEN:default}")),
+		WithExpandContext(ctx, lookup),
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `can't look up "BROKEN": store is unavailable`)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = NewYAML(
+		Source(bytes.NewBufferString("a: ${SECRET}")),
+		WithExpandContext(canceled, lookup),
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), context.Canceled.Error())
+}
+
+func TestNewYAMLWithTransform(t *testing.T) {
+	t.Parallel()
+
+	var lowercase func(interface{}) interface{}
+	lowercase = func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case map[interface{}]interface{}:
+			res := make(map[interface{}]interface{}, len(v))
+			for key, val := range v {
+				res[strings.ToLower(fmt.Sprint(key))] = lowercase(val)
+			}
+
+			return res
+		case []interface{}:
+			for i, val := range v {
+				v[i] = lowercase(val)
+			}
+		}
+
+		return value
+	}
+
+	stripPrefix := func(value interface{}) (interface{}, error) {
+		if m, ok := value.(map[interface{}]interface{}); ok && m["vendor"] != nil {
+			return m["vendor"], nil
+		}
+
+		return value, nil
+	}
+
+	p, err := NewYAML(
+		Source(bytes.NewBufferString("Vendor: {Server: {Port: 80}}"), bytes.NewBufferString("server: {host: a}")),
+		WithTransform(func(v interface{}) (interface{}, error) { return lowercase(v), nil }),
+		WithTransform(stripPrefix),
+	)
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, map[interface{}]interface{}{"port": 80, "host": "a"}, p.Get("server").Value())
+
+	_, err = NewYAML(
+		Source(bytes.NewBufferString("a: 1")),
+		WithTransform(func(interface{}) (interface{}, error) { return nil, errors.New("unsupported layout") }),
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported layout")
+
+	_, err = NewYAML(WithTransform(nil))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "received a nil transform function")
+}
+
+func TestNewYAMLErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewYAML(WithSeparator(""))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty key separator")
+
+	_, err = NewYAML(File("./testdata/base.yaml", "./testdata/missing.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing.yaml")
+
+	_, err = NewYAML(File("./testdata/base.yaml"), Source(bytes.NewBufferString("a: [")))
+	require.Error(t, err)
+}
+
+func TestNewYAMLWithOptionalFile(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	p, err := NewYAML(
+		File("./testdata/base.yaml"),
+		OptionalFile("./testdata/missing.yaml"),
+		Source(strings.NewReader("a: 1")),
+		WithLogger(StdLogger(log.New(&buf, "", 0))),
+	)
+	require.NoError(t, err, "Missing optional files should be skipped")
+	assert.Equal(t, "base_only", p.Get("value").Value())
+	assert.Equal(t, 1, p.Get("a").Value())
+	assert.Contains(t, buf.String(), `WARN config: optional file "./testdata/missing.yaml" is skipped`)
+
+	p, err = NewYAML(OptionalFile("./testdata/base.yaml"))
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "base_only", p.Get("value").Value())
+
+	_, err = NewYAML(OptionalFile("./testdata"))
+	require.Error(t, err, "Optional files, that can't be read, should fail")
+}
+
+func TestNewYAMLWithoutSources(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAML()
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.False(t, p.Get("a").HasValue())
+}
+
+func TestNewYAMLWithParallelLoad(t *testing.T) {
+	t.Parallel()
+
+	var sources []YAMLOption
+	for i := 0; i < 20; i++ {
+		sources = append(sources, Source(strings.NewReader(fmt.Sprintf("last: %d\nsource%d: true", i, i))))
+	}
+
+	p, err := NewYAML(append(sources, File("./testdata/base.yaml"), WithParallelLoad(4))...)
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	assert.Equal(t, 19, p.Get("last").Value(), "Sources should be merged in order")
+	assert.Equal(t, true, p.Get("source0").Value())
+	assert.Equal(t, true, p.Get("source19").Value())
+	assert.True(t, strings.HasSuffix(p.Get("value").Metadata().File, "base.yaml"))
+
+	_, first := NewYAML(Source(strings.NewReader("a: [")))
+	require.Error(t, first)
+
+	_, err = NewYAML(
+		Source(strings.NewReader("a: 1")),
+		Source(strings.NewReader("a: [")),
+		Source(strings.NewReader("b: 1")),
+		Source(strings.NewReader("b: 1\n  c: 2")),
+		WithParallelLoad(3),
+	)
+	require.Error(t, err)
+	assert.Equal(t, first.Error(), err.Error(), "Error of the first failed source is expected")
+
+	_, err = NewYAML(WithParallelLoad(0))
+	assert.EqualError(t, err, "number of sources to load in parallel must be positive")
+
+	_, err = NewYAML(WithSeparator(""), WithParallelLoad(0))
+	assert.EqualError(t, err, "empty key separator", "The first error should be kept")
+}
@@ -1884,3 +1884,267 @@ func TestYAMLProviderWithGarbledPath(t *testing.T) {
 		assert.Contains(t, err.Error(), "no such file or directory")
 	})
 }
+
+func TestYAMLProviderWithSeparator(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromReaderWithSeparator("/", bytes.NewBufferString(`
+hosts:
+  my.host.com:
+    port: 8080
+  other.host.com:
+    port: 9090
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	assert.Equal(t, 8080, p.Get("hosts/my.host.com/port").Value())
+	assert.False(t, p.Get("hosts.my.host.com.port").HasValue())
+	assert.Equal(t, 9090, p.Get("hosts").Get("other.host.com/port").Value())
+	assert.Equal(t, 9090, NewScopedProvider("hosts/other.host.com", p).Get("port").Value())
+
+	var hosts struct {
+		Hosts map[string]struct {
+			Port int
+		}
+	}
+
+	require.NoError(t, p.Get(Root).Populate(&hosts))
+	assert.Equal(t, 8080, hosts.Hosts["my.host.com"].Port)
+	assert.Equal(t, 9090, hosts.Hosts["other.host.com"].Port)
+
+	v, err := p.Get("hosts/missing.host.com/port").WithDefault(80)
+	require.NoError(t, err)
+	assert.Equal(t, 80, v.Value())
+}
+
+func TestYAMLProviderWithMultiCharacterSeparator(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromReaderWithSeparator("::", bytes.NewBufferString(`
+metrics:
+  http.requests:
+    - count
+    - time
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	assert.Equal(t, "time", p.Get("metrics::http.requests::1").Value())
+
+	var metrics map[string][]string
+	require.NoError(t, p.Get("metrics").Populate(&metrics))
+	assert.Equal(t, map[string][]string{"http.requests": {"count", "time"}}, metrics)
+}
+
+func TestYAMLProviderWithEmptySeparator(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewYAMLProviderFromReaderWithSeparator("", bytes.NewBufferString(`a: b`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty key separator")
+}
+
+func TestYAMLProviderFromFilesWithSeparator(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromFilesWithSeparator("/", "./testdata/benchmark1.yaml", "./testdata/benchmark2.yaml")
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "v2", p.Get("api/version").String())
+	assert.Equal(t, "guess", p.Get("api/credentials/username").String())
+
+	_, err = NewYAMLProviderFromFilesWithSeparator("/", "./testdata/missing.yaml")
+	require.Error(t, err)
+}
+
+func TestYAMLEscapedSeparators(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+hosts:
+  my.host.com:
+    port: 8080
+  other:
+    host:
+      com:
+        port: 9090
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	assert.Equal(t, 8080, p.Get(`hosts.my\.host\.com.port`).Value())
+	assert.Equal(t, 8080, p.Get(`hosts`).Get(`my\.host\.com.port`).Value())
+	assert.Equal(t, 8080, NewScopedProvider(`hosts.my\.host\.com`, p).Get("port").Value())
+
+	// Escaped separators never split keys into segments.
+	assert.Equal(t, 9090, p.Get(`hosts.other.host.com.port`).Value())
+	assert.False(t, p.Get(`hosts.other\.host\.com.port`).HasValue())
+}
+
+func TestYAMLEscapedCustomSeparators(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromReaderWithSeparator("/", bytes.NewBufferString(`
+paths:
+  /usr/bin: binaries
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	assert.Equal(t, "binaries", p.Get(`paths/\/usr\/bin`).Value())
+}
+
+func TestYAMLArrayIndexes(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+servers:
+  - host: first
+  - host: second
+  - host: last
+matrix:
+  - [1, 2]
+  - [3, 4]
+brackets:
+  a[0]: literal
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	tests := map[string]interface{}{
+		"servers.0.host":    "first",
+		"servers[0].host":   "first",
+		"servers[1].host":   "second",
+		"servers[-1].host":  "last",
+		"servers.-1.host":   "last",
+		"servers[-3].host":  "first",
+		"matrix[1][0]":      3,
+		"matrix[-1][-1]":    4,
+		"brackets.a[0]":     "literal",
+		"servers.[2].host":  "last",
+		"servers[2][-1]":    nil,
+		"servers[3].host":   nil,
+		"servers[-4].host":  nil,
+		"servers[one].host": nil,
+	}
+
+	for key, expected := range tests {
+		v := p.Get(key)
+		if expected == nil {
+			assert.False(t, v.HasValue(), "Expected %q to be missing", key)
+			continue
+		}
+
+		assert.Equal(t, expected, v.Value(), "Unexpected value for %q", key)
+	}
+
+	assert.Equal(t, "second", p.Get("servers").Get("[1].host").Value())
+
+	var host string
+	err = p.Get("servers[3].host").Populate(&host)
+	assert.EqualError(t, err, `for key "servers[3].host": index 3 out of range for array of length 3`)
+	err = p.Get("matrix").Get("[0][-3]").Populate(&host)
+	assert.EqualError(t, err, `for key "matrix.[0][-3]": index -3 out of range for array of length 2`)
+	assert.NoError(t, p.Get("servers[2].port").Populate(&host), "Missing keys of elements should be fine")
+	assert.NoError(t, p.Get("servers[one].host").Populate(&host))
+
+	group, err := NewProviderGroup("group", p)
+	require.NoError(t, err)
+	assert.Error(t, group.Get("servers[-4]").Populate(&host))
+}
+
+func TestYAMLMultipleDocuments(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`%YAML 1.1
+---
+a: first
+b: first
+list: [1, 2]
+--- # comment
+a: second
+literal: |
+  --- not a marker
+...
+# third document
+---
+list: [3]
+---
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	assert.Equal(t, "second", p.Get("a").Value())
+	assert.Equal(t, "first", p.Get("b").Value())
+	assert.Equal(t, []interface{}{3}, p.Get("list").Value())
+	assert.Equal(t, "--- not a marker\n", p.Get("literal").Value())
+
+	_, err = NewYAMLProviderFromBytes([]byte("a: b\n---\na: [\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "in document 1")
+}
+
+func TestSplitDocuments(t *testing.T) {
+	t.Parallel()
+
+	docs := func(s string) []string {
+		var res []string
+		for _, doc := range splitDocuments([]byte(s)) {
+			res = append(res, string(doc))
+		}
+
+		return res
+	}
+
+	assert.Nil(t, docs(""))
+	assert.Equal(t, []string{"a: b"}, docs("a: b"))
+	assert.Equal(t, []string{"---\na: b\n"}, docs("---\na: b\n"))
+	assert.Equal(t, []string{"a: b\r\n", "--- c\r\n"}, docs("a: b\r\n--- c\r\n"))
+	assert.Equal(t, []string{"a: b\n", "c: d\n"}, docs("a: b\n...\nc: d\n"))
+	assert.Equal(t, []string{"a: ---\n----\n"}, docs("a: ---\n----\n"))
+}
+
+func TestYAMLArraySelectors(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewYAMLProviderFromBytes([]byte(`
+endpoints:
+  - name: users
+    url: http://users
+  - name: payments
+    url: http://payments
+    ports: [80, 443]
+    replicas:
+      - zone: a
+        count: 1
+      - zone: b
+        count: 2
+  - name: 3
+    url: http://three
+`))
+	require.NoError(t, err, "Can't create a YAML provider")
+
+	tests := map[string]interface{}{
+		"endpoints[name=payments].url":                    "http://payments",
+		"endpoints[NAME=users].url":                       "http://users",
+		"endpoints[name=3].url":                           "http://three",
+		"endpoints[name=payments].ports[-1]":              443,
+		"endpoints[name=payments].replicas[zone=b].count": 2,
+		"endpoints[1].replicas[zone=a].count":             1,
+		"endpoints[name=missing].url":                     nil,
+		"endpoints[name=payments].replicas[zone=c]":       nil,
+		"endpoints[0].name[a=b]":                          nil,
+		"missing[name=a]":                                 nil,
+		"endpoints[name=users.url":                        nil,
+	}
+
+	for key, expected := range tests {
+		v := p.Get(key)
+		if expected == nil {
+			assert.False(t, v.HasValue(), "Expected %q to be missing", key)
+			continue
+		}
+
+		assert.Equal(t, expected, v.Value(), "Unexpected value for %q", key)
+	}
+
+	assert.Equal(t, "http://payments", p.Get("endpoints").Get("[name=payments].url").Value())
+
+	s, err := NewYAMLProviderFromReaderWithSeparator("/", bytes.NewBufferString("a:\n  - b.c: d\n    e: f"))
+	require.NoError(t, err, "Can't create a YAML provider")
+	assert.Equal(t, "f", s.Get("a[b.c=d]/e").Value())
+}